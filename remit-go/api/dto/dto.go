@@ -0,0 +1,232 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// Envelope wraps every v2 response body
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta Meta        `json:"meta"`
+}
+
+// Meta carries response metadata alongside the data
+type Meta struct {
+	APIVersion string `json:"api_version"`
+	NextKey    string `json:"next_key,omitempty"`
+}
+
+// Money is an amount paired with its currency and its display form
+type Money struct {
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	Formatted string  `json:"formatted"`
+}
+
+// NewMoney builds a Money value with the currency-aware display string. The
+// amount is rounded to the currency's minor units so it matches the display
+// string and serializes identically everywhere, including signed payloads.
+func NewMoney(amount float64, currency string) Money {
+	return Money{
+		Amount:    domain.RoundAmount(amount, currency),
+		Currency:  currency,
+		Formatted: domain.FormatAmount(amount, currency),
+	}
+}
+
+// Transaction is the stable v2 representation of a transaction
+type Transaction struct {
+	TransactionID     string            `json:"transaction_id"`
+	Status            string            `json:"status"`
+	Source            Money             `json:"source"`
+	Target            Money             `json:"target"`
+	ExchangeRate      float64           `json:"exchange_rate"`
+	MidMarketRate     float64           `json:"mid_market_rate,omitempty"`
+	FallbackRate      bool              `json:"fallback_rate,omitempty"`
+	RateSource        string            `json:"rate_source,omitempty"`
+	RateFetchedAt     *time.Time        `json:"rate_fetched_at,omitempty"`
+	Fees              *Fees             `json:"fees,omitempty"`
+	PaymentMethod     string            `json:"payment_method,omitempty"`
+	Payment           *Payment          `json:"payment,omitempty"`
+	Recipient         *Recipient        `json:"recipient,omitempty"`
+	TransferID        string            `json:"transfer_id,omitempty"`
+	FailureReason     string            `json:"failure_reason,omitempty"`
+	EstimatedDelivery *DeliveryEstimate `json:"estimated_delivery,omitempty"`
+	Replayed          bool              `json:"idempotent_replayed,omitempty"`
+	Warnings          []Warning         `json:"warnings,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	CompletedAt       *time.Time        `json:"completed_at,omitempty"`
+}
+
+// Warning is a notice about the request that did not stop it
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Fees is the v2 fee breakdown, expressed in the source currency
+type Fees struct {
+	Base      float64 `json:"base"`
+	Variable  float64 `json:"variable"`
+	Provider  float64 `json:"provider"`
+	Total     float64 `json:"total"`
+	Currency  string  `json:"currency"`
+	PromoCode string  `json:"promo_code,omitempty"`
+	Discount  float64 `json:"discount,omitempty"`
+
+	// FXSpread is the cost of the exchange rate margin, in the target currency
+	FXSpread Money `json:"fx_spread"`
+}
+
+// Payment is the v2 representation of a payment
+type Payment struct {
+	PaymentID   string     `json:"payment_id"`
+	Method      string     `json:"method,omitempty"`
+	Status      string     `json:"status"`
+	PaymentLink string     `json:"payment_link"`
+	PayeeVPA    string     `json:"payee_vpa,omitempty"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+
+	// PaidAmount is the amount collected, once the payment succeeded
+	PaidAmount *Money `json:"paid_amount,omitempty"`
+}
+
+// Recipient is the v2 representation of the recipient
+type Recipient struct {
+	Name               string `json:"name"`
+	NameTransliterated string `json:"name_transliterated,omitempty"`
+	BankCode           string `json:"bank_code"`
+	BankName           string `json:"bank_name,omitempty"`
+	AccountNumber      string `json:"account_number"`
+}
+
+// DeliveryEstimate is the v2 representation of a delivery estimate
+type DeliveryEstimate struct {
+	Status      string     `json:"status"`
+	EarliestAt  *time.Time `json:"earliest_at,omitempty"`
+	LatestAt    *time.Time `json:"latest_at,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// From maps a domain value to its v2 DTO. Values without a v2 mapping are
+// returned unchanged.
+func From(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *domain.Transaction:
+		return NewTransaction(v)
+	case []*domain.Transaction:
+		return NewTransactions(v)
+	case *domain.PaymentDetails:
+		return NewPayment(v)
+	case *domain.DeliveryEstimate:
+		return NewDeliveryEstimate(v)
+	default:
+		return v
+	}
+}
+
+// NewTransaction maps a domain transaction to its v2 DTO
+func NewTransaction(tx *domain.Transaction) *Transaction {
+	if tx == nil {
+		return nil
+	}
+
+	out := &Transaction{
+		TransactionID:     tx.ID,
+		Status:            string(tx.Status),
+		Source:            NewMoney(tx.SourceAmount, tx.SourceCurrency),
+		Target:            NewMoney(tx.TargetAmount, tx.TargetCurrency),
+		ExchangeRate:      tx.ExchangeRate,
+		MidMarketRate:     tx.MidMarketRate,
+		FallbackRate:      tx.FallbackRate,
+		RateSource:        tx.RateSource,
+		RateFetchedAt:     tx.RateFetchedAt,
+		PaymentMethod:     string(tx.PaymentMethod),
+		Payment:           NewPayment(tx.PaymentDetails),
+		TransferID:        tx.TransferID,
+		FailureReason:     tx.FailureReason,
+		EstimatedDelivery: NewDeliveryEstimate(tx.EstimatedDelivery),
+		Replayed:          tx.Replayed,
+		CreatedAt:         tx.CreatedAt,
+		UpdatedAt:         tx.UpdatedAt,
+		CompletedAt:       tx.CompletedAt,
+	}
+
+	for _, w := range tx.Warnings {
+		out.Warnings = append(out.Warnings, Warning{Code: w.Code, Message: w.Message})
+	}
+
+	if tx.Fees != nil {
+		out.Fees = &Fees{
+			Base:      tx.Fees.BaseFee,
+			Variable:  tx.Fees.VariableFee,
+			Provider:  tx.Fees.WiseFee,
+			Total:     tx.Fees.TotalFee,
+			Currency:  tx.SourceCurrency,
+			PromoCode: tx.Fees.PromoCode,
+			Discount:  tx.Fees.Discount,
+			FXSpread:  NewMoney(tx.Fees.FXSpread, tx.TargetCurrency),
+		}
+	}
+
+	if tx.RecipientDetails != nil {
+		out.Recipient = &Recipient{
+			Name:               tx.RecipientDetails.Name,
+			NameTransliterated: tx.RecipientDetails.NameTransliterated,
+			BankCode:           tx.RecipientDetails.BankCode,
+			BankName:           tx.RecipientDetails.BankName,
+			AccountNumber:      tx.RecipientDetails.BankAccount,
+		}
+	}
+
+	return out
+}
+
+// NewTransactions maps a list of domain transactions to v2 DTOs
+func NewTransactions(txns []*domain.Transaction) []*Transaction {
+	out := make([]*Transaction, 0, len(txns))
+	for _, tx := range txns {
+		out = append(out, NewTransaction(tx))
+	}
+	return out
+}
+
+// NewPayment maps domain payment details to the v2 DTO
+func NewPayment(p *domain.PaymentDetails) *Payment {
+	if p == nil {
+		return nil
+	}
+
+	payment := &Payment{
+		PaymentID:   p.PaymentID,
+		Method:      string(p.Method),
+		Status:      p.Status,
+		PaymentLink: p.PaymentLink,
+		PayeeVPA:    p.UPIID,
+		PaidAt:      p.PaidAt,
+		ExpiresAt:   p.ExpiresAt,
+	}
+	if p.Currency != "" {
+		paid := NewMoney(p.PaidAmount, p.Currency)
+		payment.PaidAmount = &paid
+	}
+	return payment
+}
+
+// NewDeliveryEstimate maps a domain delivery estimate to the v2 DTO
+func NewDeliveryEstimate(e *domain.DeliveryEstimate) *DeliveryEstimate {
+	if e == nil {
+		return nil
+	}
+
+	return &DeliveryEstimate{
+		Status:      string(e.Status),
+		EarliestAt:  e.EarliestAt,
+		LatestAt:    e.LatestAt,
+		DeliveredAt: e.DeliveredAt,
+	}
+}
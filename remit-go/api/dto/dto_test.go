@@ -0,0 +1,24 @@
+package dto
+
+import "testing"
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     Money
+	}{
+		{160, "CAD", Money{Amount: 160, Currency: "CAD", Formatted: "CA$160.00"}},
+		{160.004, "CAD", Money{Amount: 160, Currency: "CAD", Formatted: "CA$160.00"}},
+		{160.005, "CAD", Money{Amount: 160.01, Currency: "CAD", Formatted: "CA$160.01"}},
+		{150000, "INR", Money{Amount: 150000, Currency: "INR", Formatted: "₹1,50,000.00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want.Formatted, func(t *testing.T) {
+			if got := NewMoney(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("NewMoney(%v, %s) = %+v, want %+v", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
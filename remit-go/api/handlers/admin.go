@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/api/middleware"
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+	"github.com/remit-demo/remit-go/internal/service"
+)
+
+// adminTransaction is a transaction together with the audit fields that are
+// hidden from users
+type adminTransaction struct {
+	*domain.Transaction
+	CreatedBy   string `json:"created_by,omitempty"`
+	CreatedByIP string `json:"created_by_ip,omitempty"`
+	RedactedBy  string `json:"redacted_by,omitempty"`
+}
+
+func newAdminTransaction(tx *domain.Transaction) *adminTransaction {
+	return &adminTransaction{
+		Transaction: tx,
+		CreatedBy:   tx.CreatedBy,
+		CreatedByIP: tx.CreatedByIP,
+		RedactedBy:  tx.RedactedBy,
+	}
+}
+
+// AdminGetTransaction returns a transaction including its audit fields
+func (h *Handler) AdminGetTransaction(c *gin.Context) {
+	txID := c.Param("id")
+	if txID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID required"})
+		return
+	}
+
+	tx, err := h.svc.GetTransaction(c.Request.Context(), txID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminTransaction(tx))
+}
+
+// SearchTransactions pages through transactions of any user by their
+// reference
+func (h *Handler) SearchTransactions(c *gin.Context) {
+	reference := c.Query("reference")
+	if reference == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reference required"})
+		return
+	}
+
+	page := middleware.GetPageRequest(c)
+	txns, nextKey, err := h.svc.SearchTransactions(c.Request.Context(), reference, page.Limit, page.LastKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last_key"})
+			return
+		}
+		if errors.Is(err, repository.ErrIndexMisconfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "transaction search is unavailable: storage index misconfigured"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search transactions"})
+		return
+	}
+
+	out := make([]*adminTransaction, 0, len(txns))
+	for _, tx := range txns {
+		out = append(out, newAdminTransaction(tx))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": out,
+		"next_key":     nextKey,
+	})
+}
+
+// ApproveTransaction releases a transaction held for review
+func (h *Handler) ApproveTransaction(c *gin.Context) {
+	h.resolveReview(c, h.svc.ApproveTransaction)
+}
+
+// RejectTransaction fails a transaction held for review
+func (h *Handler) RejectTransaction(c *gin.Context) {
+	h.resolveReview(c, h.svc.RejectTransaction)
+}
+
+func (h *Handler) resolveReview(c *gin.Context, resolve func(ctx context.Context, txID string) (*domain.Transaction, error)) {
+	txID := c.Param("id")
+	if txID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID required"})
+		return
+	}
+
+	tx, err := resolve(c.Request.Context(), txID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		case errors.Is(err, service.ErrInvalidStatus):
+			c.JSON(http.StatusConflict, gin.H{"error": "transaction is not pending review"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve review"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminTransaction(tx))
+}
+
+// GetReconciliationReport handles reconciliation report requests for a
+// date range. from and to accept RFC 3339 timestamps or YYYY-MM-DD dates; a
+// bare to date covers that whole day. to defaults to now.
+func (h *Handler) GetReconciliationReport(c *gin.Context) {
+	from, err := parseReportTime(c.Query("from"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp or YYYY-MM-DD date"})
+		return
+	}
+
+	to := time.Now()
+	if c.Query("to") != "" {
+		if to, err = parseReportTime(c.Query("to"), true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp or YYYY-MM-DD date"})
+			return
+		}
+	}
+
+	report, err := h.svc.ReconciliationReport(c.Request.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build reconciliation report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func parseReportTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return day, nil
+}
+
+// ListDeadLetters pages through background operations that failed for good
+func (h *Handler) ListDeadLetters(c *gin.Context) {
+	page := middleware.GetPageRequest(c)
+
+	deadLetters, nextKey, err := h.svc.ListDeadLetters(c.Request.Context(), page.Limit, page.LastKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last_key"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list dead letters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": deadLetters,
+		"next_key":     nextKey,
+	})
+}
+
+// ReplayDeadLetter runs a dead-lettered operation again and removes it when
+// it succeeds
+func (h *Handler) ReplayDeadLetter(c *gin.Context) {
+	err := h.svc.ReplayDeadLetter(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+		case errors.Is(err, service.ErrUnknownOperation):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "dead letter operation cannot be replayed"})
+		case errors.Is(err, service.ErrInvalidStatus):
+			c.JSON(http.StatusConflict, gin.H{"error": "transaction is no longer in a replayable status"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "replay failed; the dead letter was kept"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "replayed"})
+}
+
+// SetCurrencyPairEnabled pauses or resumes a corridor for new transactions
+func (h *Handler) SetCurrencyPairEnabled(c *gin.Context) {
+	var req struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	pair, err := h.svc.SetCurrencyPairEnabled(c.Param("source"), c.Param("target"), *req.Enabled)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCurrency) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "currency pair not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update currency pair"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// RecalculateTransaction re-quotes an unpaid transaction at the current rate
+func (h *Handler) RecalculateTransaction(c *gin.Context) {
+	tx, err := h.svc.RecalculateTransaction(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		case errors.Is(err, service.ErrInvalidStatus):
+			c.JSON(http.StatusConflict, gin.H{"error": "transaction can only be recalculated before payment is received"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to recalculate transaction"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminTransaction(tx))
+}
+
+// ApplyBulkAction fails or retries every transaction in a status created
+// within a date range. confirm_count must match the number of transactions
+// affected; a mismatch changes nothing and reports the actual count.
+func (h *Handler) ApplyBulkAction(c *gin.Context) {
+	var req struct {
+		Status       string `json:"status" binding:"required"`
+		From         string `json:"from" binding:"required"`
+		To           string `json:"to"`
+		Action       string `json:"action" binding:"required"`
+		Reason       string `json:"reason" binding:"max=64"`
+		ConfirmCount *int   `json:"confirm_count" binding:"required,gte=0"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	from, err := parseReportTime(req.From, false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp or YYYY-MM-DD date"})
+		return
+	}
+	to := time.Now()
+	if req.To != "" {
+		if to, err = parseReportTime(req.To, true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp or YYYY-MM-DD date"})
+			return
+		}
+	}
+
+	results, err := h.svc.ApplyBulkAction(c.Request.Context(), &service.BulkActionRequest{
+		Status:       domain.TransactionStatus(req.Status),
+		From:         from,
+		To:           to,
+		Action:       service.BulkAction(req.Action),
+		Reason:       req.Reason,
+		ConfirmCount: *req.ConfirmCount,
+	})
+	if err != nil {
+		var verr *service.ValidationError
+		var cerr *service.BulkConfirmationError
+		switch {
+		case errors.As(err, &verr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": verr.Message})
+		case errors.As(err, &cerr):
+			c.JSON(http.StatusConflict, gin.H{"error": "confirm_count does not match the affected transactions", "affected": cerr.Affected})
+		case errors.Is(err, service.ErrInvalidDateRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply bulk action"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// RedactTransaction erases the recipient's personal data from a finished
+// transaction. Irreversible.
+func (h *Handler) RedactTransaction(c *gin.Context) {
+	tx, err := h.svc.RedactTransaction(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		case errors.Is(err, service.ErrInvalidStatus):
+			c.JSON(http.StatusConflict, gin.H{"error": "only completed or failed transactions can be redacted"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to redact transaction"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminTransaction(tx))
+}
+
+// RedactUserData erases the recipient personal data from all of a user's
+// finished transactions. Irreversible.
+func (h *Handler) RedactUserData(c *gin.Context) {
+	result, err := h.svc.RedactUserData(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrIndexMisconfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "transaction history is unavailable: storage index misconfigured"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to redact user data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetProviderDebug returns the raw provider records behind a transaction,
+// redacted, for support
+func (h *Handler) GetProviderDebug(c *gin.Context) {
+	debug, err := h.svc.GetProviderDebug(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get provider records"})
+		return
+	}
+
+	c.JSON(http.StatusOK, debug)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// parseAmount converts a request amount, decoded as a json.Number so no
+// precision is lost on the way, to a float64 in the given currency. Amounts
+// with more decimals than the currency's minor units, or too large for a
+// float64 to hold to the minor unit, are rejected rather than rounded.
+func parseAmount(n json.Number, currency string) (float64, *FieldError) {
+	invalid := func(rule, msg string) *FieldError {
+		return &FieldError{Field: "amount", Message: msg, Rule: rule}
+	}
+
+	exact, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return 0, invalid("number", "amount must be a number")
+	}
+	if exact.Sign() <= 0 {
+		return 0, invalid("gt", "amount must be greater than 0")
+	}
+
+	decimals := domain.CurrencyPrecision(currency)
+	scaled := new(big.Rat).Mul(exact, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	if !scaled.IsInt() {
+		return 0, invalid("precision", fmt.Sprintf("amount must have at most %d decimal places", decimals))
+	}
+
+	amount, _ := exact.Float64()
+	if strconv.FormatFloat(amount, 'f', decimals, 64) != exact.FloatString(decimals) {
+		return 0, invalid("precision", "amount is too large to be represented exactly")
+	}
+	return amount, nil
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/service"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		want     float64
+		wantRule string
+	}{
+		{"10000", 10000, ""},
+		{"10000.5", 10000.5, ""},
+		{"10000.50", 10000.5, ""},
+		{"1e4", 10000, ""},
+		{"100000000000.01", 100000000000.01, ""},
+		{"9999999999999.99", 9999999999999.99, ""},
+		{"100.001", 0, "precision"},
+		{"0.005", 0, "precision"},
+		{"123456789012345678.01", 0, "precision"},
+		{"0", 0, "gt"},
+		{"-100", 0, "gt"},
+		{"ten", 0, "number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			got, ferr := parseAmount(json.Number(tt.amount), "INR")
+			if tt.wantRule != "" {
+				if ferr == nil || ferr.Rule != tt.wantRule || ferr.Field != "amount" {
+					t.Fatalf("parseAmount() = %v, %+v; want the %s rule", got, ferr, tt.wantRule)
+				}
+				return
+			}
+			if ferr != nil || got != tt.want {
+				t.Errorf("parseAmount() = %v, %+v; want %v", got, ferr, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitiateAmountPrecision(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     string
+		wantStatus int
+		wantAmount float64
+	}{
+		{"large with paise", "100000000000.01", http.StatusCreated, 100000000000.01},
+		{"too many decimals", "100.001", http.StatusBadRequest, 0},
+		{"too large to hold exactly", "123456789012345678.01", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got float64
+			h := NewHandler(&stubService{
+				initiate: func(req *service.InitiateRequest) (*domain.Transaction, error) {
+					got = req.Amount
+					return testTransaction(), nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.POST("/transactions", h.InitiateTransaction)
+
+			body := `{"amount":` + tt.amount + `,"recipient":{"name":"Jane Doe","bank_account":"12345678","bank_code":"TD001"}}`
+			rec := serve(router, http.MethodPost, "/transactions", body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got != tt.wantAmount {
+				t.Errorf("amount passed on = %v, want %v", got, tt.wantAmount)
+			}
+			if tt.wantStatus == http.StatusBadRequest {
+				errs, _ := decode(t, rec)["errors"].([]interface{})
+				if len(errs) != 1 || errs[0].(map[string]interface{})["field"] != "amount" {
+					t.Errorf("errors = %v, want one for amount", errs)
+				}
+			}
+		})
+	}
+}
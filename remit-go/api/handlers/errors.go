@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// codeInvalidRequest is the stable error code of malformed request bodies
+const codeInvalidRequest = "invalid_request"
+
+// FieldError describes one field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+}
+
+// fieldMessages are the messages of fields whose rules deserve more than
+// the generic wording, by field path and rule
+var fieldMessages = map[string]string{
+	"amount/required":                    "amount is required and must be greater than 0",
+	"amount/gt":                          "amount must be greater than 0",
+	"recipient/required":                 "recipient is required",
+	"recipient.account_currency/iso4217": "recipient account currency must be an ISO 4217 code",
+	"recipient.bank_account/required":    "recipient bank account number is required",
+	"recipient.bank_code/required":       "recipient bank code (IFSC) is required",
+	"recipient.name/required":            "recipient name is required",
+	"reference/max":                      "reference must be at most 64 characters",
+}
+
+// fieldMessage returns a message for a failed rule that can be shown to
+// the caller
+func fieldMessage(field, rule, param string) string {
+	if msg, ok := fieldMessages[field+"/"+rule]; ok {
+		return msg
+	}
+
+	switch rule {
+	case "required":
+		return field + " is required"
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "gte":
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "min":
+		return fmt.Sprintf("%s must have at least %s items", field, param)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s characters", field, param)
+	default:
+		return field + " is invalid"
+	}
+}
+
+func init() {
+	// Report fields by their JSON names rather than Go struct field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// bindError answers a request whose body failed to bind. Every field that
+// failed validation is listed under "errors", so the caller can fix them all
+// at once. Other binding errors, such as malformed JSON, only get a generic
+// message and stable code outside verbose mode, so binding library
+// internals never reach clients.
+func (h *Handler) bindError(c *gin.Context, err error) {
+	resp := gin.H{"error": "invalid request", "code": codeInvalidRequest}
+
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		fields := make([]FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			// Request bodies are anonymous structs, so the namespace is
+			// the JSON path of the field, e.g. "recipient.name"
+			fields = append(fields, FieldError{
+				Field:   fe.Namespace(),
+				Message: fieldMessage(fe.Namespace(), fe.Tag(), fe.Param()),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+			})
+		}
+		resp["errors"] = fields
+	} else if h.config.VerboseErrors {
+		resp["detail"] = err.Error()
+	}
+
+	c.JSON(http.StatusBadRequest, resp)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBindErrorDetail(t *testing.T) {
+	tests := []struct {
+		name       string
+		verbose    bool
+		body       string
+		wantDetail bool
+	}{
+		{"malformed JSON hidden", false, `{"payment_id":`, false},
+		{"malformed JSON verbose", true, `{"payment_id":`, true},
+		{"wrong type hidden", false, `{"payment_id":1,"status":"SUCCESS"}`, false},
+		{"wrong type verbose", true, `{"payment_id":1,"status":"SUCCESS"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{}, &Config{VerboseErrors: tt.verbose})
+			router := newRouter("", APIVersionV1)
+			router.POST("/callbacks/payment", h.HandlePaymentCallback)
+
+			rec := serve(router, http.MethodPost, "/callbacks/payment", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			body := decode(t, rec)
+			if body["error"] != "invalid request" || body["code"] != codeInvalidRequest {
+				t.Errorf("body = %v, want the generic message and code", body)
+			}
+			if _, ok := body["detail"]; ok != tt.wantDetail {
+				t.Errorf("detail = %v, want shown %v", body["detail"], tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestBindErrorFields(t *testing.T) {
+	long := strings.Repeat("x", 65)
+
+	tests := []struct {
+		name string
+		body string
+		want []FieldError
+	}{
+		{"every field reported", `{"recipient":{"name":"","bank_account":"","bank_code":""},"reference":"` + long + `"}`, []FieldError{
+			{Field: "amount", Message: "amount is required and must be greater than 0", Rule: "required"},
+			{Field: "recipient.bank_account", Message: "recipient bank account number is required", Rule: "required"},
+			{Field: "recipient.bank_code", Message: "recipient bank code (IFSC) is required", Rule: "required"},
+			{Field: "recipient.name", Message: "recipient name is required", Rule: "required"},
+			{Field: "reference", Message: "reference must be at most 64 characters", Rule: "max", Param: "64"},
+		}},
+		{"missing recipient", `{"amount":10000}`, []FieldError{
+			{Field: "recipient", Message: "recipient is required", Rule: "required"},
+		}},
+		{"generic message", `{"amount":10000,"recipient":{"name":"Jane Doe","bank_account":"123456789012345678901","bank_code":"TD001","account_currency":"XX"}}`, []FieldError{
+			{Field: "recipient.bank_account", Message: "recipient.bank_account must be at most 20 characters", Rule: "max", Param: "20"},
+			{Field: "recipient.account_currency", Message: "recipient account currency must be an ISO 4217 code", Rule: "iso4217"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.POST("/transactions", h.InitiateTransaction)
+
+			rec := serve(router, http.MethodPost, "/transactions", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			var body struct {
+				Errors []FieldError `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Errors) != len(tt.want) {
+				t.Fatalf("errors = %+v, want %+v", body.Errors, tt.want)
+			}
+			for i, fe := range body.Errors {
+				if fe != tt.want[i] {
+					t.Errorf("error %d = %+v, want %+v", i, fe, tt.want[i])
+				}
+			}
+		})
+	}
+}
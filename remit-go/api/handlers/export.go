@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/internal/service"
+)
+
+// StartExport starts a background export of the user's transactions and
+// answers 202 with the job to poll
+func (h *Handler) StartExport(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	job, err := h.svc.StartExport(c.Request.Context(), userID)
+	if errors.Is(err, service.ErrTooManyExports) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many exports in progress; wait for one to finish or cancel it"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start export"})
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/%s/exports/%s", apiVersion(c), job.ID))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetExport handles export job status requests
+func (h *Handler) GetExport(c *gin.Context) {
+	job, err := h.svc.GetExport(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		exportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// DownloadExport returns the file of a finished export job
+func (h *Handler) DownloadExport(c *gin.Context) {
+	data, job, err := h.svc.ExportFile(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		exportError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="transactions-%s.csv"`, job.ID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// CancelExport stops an export job that has not finished
+func (h *Handler) CancelExport(c *gin.Context) {
+	job, err := h.svc.CancelExport(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		exportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func exportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrExportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+	case errors.Is(err, service.ErrExportNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": "export is not ready"})
+	case errors.Is(err, service.ErrInvalidStatus):
+		c.JSON(http.StatusConflict, gin.H{"error": "export has already finished"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get export"})
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/service"
+)
+
+func TestStartExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		err        error
+		wantStatus int
+	}{
+		{"v1", APIVersionV1, nil, http.StatusAccepted},
+		{"v2", APIVersionV2, nil, http.StatusAccepted},
+		{"too many in progress", APIVersionV1, service.ErrTooManyExports, http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				startExport: func(userID string) (*domain.ExportJob, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &domain.ExportJob{ID: "EXP-1", UserID: userID, Status: domain.ExportPending, Format: "csv"}, nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", tt.version)
+			router.POST("/transactions/export", h.StartExport)
+
+			rec := serve(router, http.MethodPost, "/transactions/export", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusAccepted {
+				return
+			}
+			if got, want := rec.Header().Get("Location"), "/api/"+tt.version+"/exports/EXP-1"; got != want {
+				t.Errorf("Location = %q, want %q", got, want)
+			}
+			if body := decode(t, rec); body["id"] != "EXP-1" || body["status"] != "PENDING" {
+				t.Errorf("body = %v, want the pending job EXP-1", body)
+			}
+		})
+	}
+}
+
+func TestDownloadExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"ready", nil, http.StatusOK},
+		{"still running", service.ErrExportNotReady, http.StatusConflict},
+		{"someone else's export", fmt.Errorf("export EXP-1: %w", service.ErrExportNotFound), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				exportFile: func(userID, id string) ([]byte, *domain.ExportJob, error) {
+					if tt.err != nil {
+						return nil, nil, tt.err
+					}
+					return []byte("id,status\nTXN-1,COMPLETED\n"), &domain.ExportJob{ID: id, Status: domain.ExportReady}, nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/exports/:id/download", h.DownloadExport)
+
+			rec := serve(router, http.MethodGet, "/exports/EXP-1/download", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="transactions-EXP-1.csv"` {
+				t.Errorf("Content-Disposition = %q", got)
+			}
+			if rec.Body.String() != "id,status\nTXN-1,COMPLETED\n" {
+				t.Errorf("body = %q, want the export file", rec.Body)
+			}
+		})
+	}
+}
+
+func TestCancelExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"running", nil, http.StatusOK},
+		{"already finished", service.ErrInvalidStatus, http.StatusConflict},
+		{"missing", service.ErrExportNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				cancelExport: func(userID, id string) (*domain.ExportJob, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &domain.ExportJob{ID: id, Status: domain.ExportCancelled}, nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.POST("/exports/:id/cancel", h.CancelExport)
+
+			rec := serve(router, http.MethodPost, "/exports/EXP-1/cancel", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if body := decode(t, rec); rec.Code == http.StatusOK && body["status"] != "CANCELLED" {
+				t.Errorf("body = %v, want the cancelled job", body)
+			}
+		})
+	}
+}
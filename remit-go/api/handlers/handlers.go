@@ -1,32 +1,71 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/api/dto"
+	"github.com/remit-demo/remit-go/api/middleware"
 	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
 	"github.com/remit-demo/remit-go/internal/service"
 )
 
+// Idempotency headers on transaction initiation
+const (
+	headerIdempotencyKey      = "Idempotency-Key"
+	headerIdempotencyReplayed = "X-Idempotency-Replayed"
+)
+
 // Handler handles HTTP requests
 type Handler struct {
-	svc service.Service
+	svc    service.Service
+	config *Config
+}
+
+// Config holds handler configuration
+type Config struct {
+	// VerboseErrors includes the parser's detail for malformed request
+	// bodies in 400 responses. Meant for development only.
+	VerboseErrors bool
+
+	// MaxStreamItems caps the transactions one streamed listing returns.
+	// Zero means no limit.
+	MaxStreamItems int
+
+	// RateDecimals is how many decimal places exchange rates are shown
+	// with. Zero shows them at full precision.
+	RateDecimals int
 }
 
 // NewHandler creates a new handler instance
-func NewHandler(svc service.Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc service.Service, config *Config) *Handler {
+	return &Handler{svc: svc, config: config}
 }
 
 // InitiateTransaction handles transaction initiation requests
 func (h *Handler) InitiateTransaction(c *gin.Context) {
 	var req struct {
-		Amount    float64                  `json:"amount" binding:"required,gt=0"`
+		Amount    json.Number              `json:"amount" binding:"required"`
 		Recipient *domain.RecipientDetails `json:"recipient" binding:"required"`
+		PromoCode string                   `json:"promo_code"`
+
+		PaymentMethod domain.PaymentMethod `json:"payment_method"`
+		Reference     string               `json:"reference" binding:"max=64"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.bindError(c, err)
+		return
+	}
+	amount, ferr := parseAmount(req.Amount, "INR")
+	if ferr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "code": codeInvalidRequest, "errors": []FieldError{*ferr}})
 		return
 	}
 
@@ -37,22 +76,104 @@ func (h *Handler) InitiateTransaction(c *gin.Context) {
 		return
 	}
 
-	tx, err := h.svc.InitiateTransaction(c.Request.Context(), userID, req.Amount, req.Recipient)
+	tx, err := h.svc.InitiateTransaction(c.Request.Context(), &service.InitiateRequest{
+		UserID:         userID,
+		UserTier:       c.GetString(middleware.UserTierKey),
+		Amount:         amount,
+		Recipient:      req.Recipient,
+		PromoCode:      req.PromoCode,
+		ClientIP:       c.ClientIP(),
+		PaymentMethod:  req.PaymentMethod,
+		Reference:      req.Reference,
+		IdempotencyKey: c.GetHeader(headerIdempotencyKey),
+	})
 	if err != nil {
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": verr.Message})
+			return
+		}
+		if errors.Is(err, repository.ErrItemTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "transaction is too large to store"})
+			return
+		}
+
 		switch err {
 		case service.ErrInvalidAmount:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid amount"})
 		case service.ErrInvalidRecipient:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipient details"})
+		case service.ErrRecipientBlocked:
+			c.JSON(http.StatusForbidden, gin.H{"error": "recipient cannot receive transfers"})
+		case service.ErrRecipientCurrencyMismatch:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "recipient account is not held in the target currency"})
 		case service.ErrDailyLimitExceeded:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "daily limit exceeded"})
+		case service.ErrTooManyOpenTransactions:
+			c.JSON(http.StatusConflict, gin.H{"error": "too many open transactions"})
+		case service.ErrInvalidCurrency:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency pair"})
+		case service.ErrCorridorNotAllowed:
+			c.JSON(http.StatusForbidden, gin.H{"error": "corridor not allowed for this user"})
+		case service.ErrCorridorDisabled:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "corridor is temporarily unavailable"})
+		case service.ErrBelowCorridorMinimum:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "amount after fees is below the corridor minimum"})
+		case service.ErrAboveCorridorMaximum:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "amount after fees is above the corridor maximum"})
+		case service.ErrInvalidPromoCode:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promo code"})
+		case service.ErrPromoCodeExpired:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "promo code expired"})
+		case service.ErrPromoCodeUsageExceeded:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "promo code usage limit reached"})
+		case service.ErrUnsupportedPaymentMethod:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported payment method"})
+		case service.ErrIdempotencyInProgress:
+			c.JSON(http.StatusConflict, gin.H{"error": "a request with this idempotency key is still in progress"})
+		case service.ErrIDCollision:
+			c.Header("Retry-After", "1")
+			c.JSON(http.StatusConflict, gin.H{"error": "transaction ID conflict, retry the request", "code": string(service.ErrIDCollision)})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, tx)
+	if tx.Replayed {
+		c.Header(headerIdempotencyReplayed, "true")
+	}
+
+	// Async clients poll the transaction rather than read it from here
+	if c.Query("async") == "true" {
+		c.Header("Location", "/api/"+apiVersion(c)+"/transactions/"+tx.ID)
+		h.render(c, http.StatusAccepted, gin.H{"id": tx.ID, "status": tx.Status})
+		return
+	}
+
+	if tx.Replayed {
+		h.render(c, http.StatusOK, tx)
+		return
+	}
+
+	h.render(c, http.StatusCreated, tx)
+}
+
+// GetLimits handles requests for the user's limits and fee schedule
+func (h *Handler) GetLimits(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limits, err := h.svc.GetLimits(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get limits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
 }
 
 // GetTransaction handles transaction retrieval requests
@@ -63,21 +184,146 @@ func (h *Handler) GetTransaction(c *gin.Context) {
 		return
 	}
 
-	tx, err := h.svc.GetTransaction(c.Request.Context(), txID)
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tx, err := h.svc.GetUserTransaction(c.Request.Context(), userID, txID)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get transaction"})
 		return
 	}
 
-	if tx == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+	h.render(c, http.StatusOK, tx)
+}
+
+// GetTransactionStatuses handles batch status queries for the user's
+// transactions
+func (h *Handler) GetTransactionStatuses(c *gin.Context) {
+	var req struct {
+		IDs []string `json:"ids" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	statuses, notFound, err := h.svc.GetTransactionStatuses(c.Request.Context(), userID, req.IDs)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d ids per request", service.MaxStatusBatchSize)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get transaction statuses"})
+		return
+	}
+
+	h.render(c, http.StatusOK, gin.H{
+		"statuses":  statuses,
+		"not_found": notFound,
+	})
+}
+
+// GetTransactionETA handles delivery estimate requests for a transaction
+func (h *Handler) GetTransactionETA(c *gin.Context) {
+	txID := c.Param("id")
+	if txID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	estimate, err := h.svc.EstimateDelivery(c.Request.Context(), userID, txID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		case errors.Is(err, service.ErrInvalidCurrency):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "no delivery estimate for this corridor"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to estimate delivery"})
+		}
+		return
+	}
+
+	h.render(c, http.StatusOK, estimate)
+}
+
+// GetTransactionTimeline handles transaction timeline requests
+func (h *Handler) GetTransactionTimeline(c *gin.Context) {
+	txID := c.Param("id")
+	if txID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	timeline, err := h.svc.GetTransactionTimeline(c.Request.Context(), userID, txID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get transaction timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timeline": timeline})
+}
+
+// GetRateBreakdown handles requests for the rates locked on a transaction
+func (h *Handler) GetRateBreakdown(c *gin.Context) {
+	txID := c.Param("id")
+	if txID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID required"})
 		return
 	}
 
-	c.JSON(http.StatusOK, tx)
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	breakdown, err := h.svc.GetRateBreakdown(c.Request.Context(), userID, txID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get rate breakdown"})
+		return
+	}
+
+	h.render(c, http.StatusOK, breakdown)
 }
 
-// ListTransactions handles transaction listing requests
+// ListTransactions handles transaction listing requests. With
+// Accept: application/x-ndjson the whole history from the cursor on is
+// streamed instead of a single page.
 func (h *Handler) ListTransactions(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
@@ -85,19 +331,53 @@ func (h *Handler) ListTransactions(c *gin.Context) {
 		return
 	}
 
-	limit := 10 // Default limit
-	if limitStr := c.Query("limit"); limitStr != "" {
-		// Parse limit from query parameter
+	page := middleware.GetPageRequest(c)
+
+	order := repository.SortOrder(c.DefaultQuery("sort", string(repository.SortDescending)))
+	if !order.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be asc or desc"})
+		return
 	}
 
-	lastKey := c.Query("last_key")
+	// Optionally only the transactions to one recipient account
+	list := func(ctx context.Context, cursor string) ([]*domain.Transaction, string, error) {
+		return h.svc.ListUserTransactions(ctx, userID, page.Limit, cursor, order)
+	}
+	if account := strings.TrimSpace(c.Query("recipient_account")); account != "" {
+		list = func(ctx context.Context, cursor string) ([]*domain.Transaction, string, error) {
+			return h.svc.ListRecipientTransactions(ctx, userID, account, page.Limit, cursor, order)
+		}
+	}
 
-	txns, nextKey, err := h.svc.ListUserTransactions(c.Request.Context(), userID, limit, lastKey)
+	txns, nextKey, err := list(c.Request.Context(), page.LastKey)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last_key"})
+			return
+		}
+		if errors.Is(err, repository.ErrIndexMisconfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "transaction history is unavailable: storage index misconfigured"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list transactions"})
 		return
 	}
 
+	if wantsNDJSON(c) {
+		h.streamTransactions(c, txns, nextKey, list)
+		return
+	}
+	h.roundRates(txns)
+
+	if apiVersion(c) == APIVersionV2 {
+		c.JSON(http.StatusOK, dto.Envelope{
+			Data: dto.NewTransactions(txns),
+			Meta: dto.Meta{APIVersion: APIVersionV2, NextKey: nextKey},
+		})
+		return
+	}
+
+	formatAmounts(c, txns)
 	c.JSON(http.StatusOK, gin.H{
 		"transactions": txns,
 		"next_key":     nextKey,
@@ -112,29 +392,91 @@ func (h *Handler) GeneratePaymentLink(c *gin.Context) {
 		return
 	}
 
-	payment, err := h.svc.GeneratePaymentLink(c.Request.Context(), txID)
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	payment, err := h.svc.GeneratePaymentLink(c.Request.Context(), userID, txID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate payment link"})
+		var verr *service.ValidationError
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		case errors.Is(err, service.ErrPendingReview):
+			c.JSON(http.StatusConflict, gin.H{"error": "transaction is pending review"})
+		case errors.Is(err, service.ErrInvalidStatus):
+			c.JSON(http.StatusConflict, gin.H{"error": "payment link cannot be generated in the current transaction status"})
+		case errors.As(err, &verr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": verr.Message})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate payment link"})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	h.render(c, http.StatusOK, payment)
+}
+
+// CancelPayment voids the transaction's payment link before it is paid
+func (h *Handler) CancelPayment(c *gin.Context) {
+	txID := c.Param("id")
+	if txID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tx, err := h.svc.CancelPayment(c.Request.Context(), userID, txID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		case errors.Is(err, service.ErrPaymentAlreadyReceived):
+			c.JSON(http.StatusConflict, gin.H{"error": "payment already received"})
+		case errors.Is(err, service.ErrInvalidStatus):
+			c.JSON(http.StatusConflict, gin.H{"error": "transaction has no pending payment to cancel"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel payment"})
+		}
+		return
+	}
+
+	h.render(c, http.StatusOK, tx)
 }
 
 // HandlePaymentCallback processes payment status callbacks
 func (h *Handler) HandlePaymentCallback(c *gin.Context) {
 	var req struct {
-		PaymentID string `json:"payment_id" binding:"required"`
-		Status    string `json:"status" binding:"required"`
+		PaymentID  string   `json:"payment_id" binding:"required"`
+		Status     string   `json:"status" binding:"required"`
+		PaidAmount *float64 `json:"paid_amount"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.bindError(c, err)
 		return
 	}
 
-	if err := h.svc.HandlePaymentCallback(c.Request.Context(), req.PaymentID, req.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process payment callback"})
+	if err := h.svc.HandlePaymentCallback(c.Request.Context(), &service.PaymentCallback{
+		PaymentID:  req.PaymentID,
+		Status:     service.PaymentCallbackStatus(req.Status),
+		PaidAmount: req.PaidAmount,
+	}); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		case errors.Is(err, service.ErrUnknownPaymentStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown payment status"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process payment callback"})
+		}
 		return
 	}
 
@@ -144,30 +486,146 @@ func (h *Handler) HandlePaymentCallback(c *gin.Context) {
 // HandleTransferCallback processes transfer status callbacks
 func (h *Handler) HandleTransferCallback(c *gin.Context) {
 	var req struct {
-		TransactionID string `json:"transaction_id" binding:"required"`
-		Status        string `json:"status" binding:"required"`
+		TransactionID   string   `json:"transaction_id" binding:"required"`
+		Status          string   `json:"status" binding:"required"`
+		DeliveredAmount *float64 `json:"delivered_amount" binding:"omitempty,gte=0"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.bindError(c, err)
 		return
 	}
 
-	if err := h.svc.HandleTransferCallback(c.Request.Context(), req.TransactionID, req.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process transfer callback"})
+	err := h.svc.HandleTransferCallback(c.Request.Context(), &service.TransferCallback{
+		TransactionID:   req.TransactionID,
+		Status:          service.TransferCallbackStatus(req.Status),
+		DeliveredAmount: req.DeliveredAmount,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		case errors.Is(err, service.ErrUnknownTransferStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown transfer status"})
+		case errors.Is(err, service.ErrInvalidStatus):
+			c.JSON(http.StatusConflict, gin.H{"error": "transaction has no transfer in progress"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process transfer callback"})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
-// GetExchangeRate handles exchange rate requests
+// GetQuote handles fee and rate preview requests, including the FX spread
+// disclosure
+func (h *Handler) GetQuote(c *gin.Context) {
+	var req struct {
+		Amount    float64 `form:"amount" binding:"required,gt=0"`
+		Source    string  `form:"source"`
+		Target    string  `form:"target"`
+		PromoCode string  `form:"promo_code"`
+	}
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	quote, err := h.svc.Quote(c.Request.Context(), &service.QuoteRequest{
+		UserID:         c.GetString("user_id"),
+		Amount:         req.Amount,
+		SourceCurrency: req.Source,
+		TargetCurrency: req.Target,
+		PromoCode:      req.PromoCode,
+	})
+	if err != nil {
+		quoteError(c, err)
+		return
+	}
+
+	h.render(c, http.StatusOK, quote)
+}
+
+// ReverseQuote previews the transaction that delivers target_amount in the
+// target currency
+func (h *Handler) ReverseQuote(c *gin.Context) {
+	var req struct {
+		TargetAmount float64 `form:"target_amount" binding:"required,gt=0"`
+		Source       string  `form:"source"`
+		Target       string  `form:"target"`
+		PromoCode    string  `form:"promo_code"`
+	}
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	quote, err := h.svc.ReverseQuote(c.Request.Context(), &service.QuoteRequest{
+		UserID:         c.GetString("user_id"),
+		TargetAmount:   req.TargetAmount,
+		SourceCurrency: req.Source,
+		TargetCurrency: req.Target,
+		PromoCode:      req.PromoCode,
+	})
+	if err != nil {
+		quoteError(c, err)
+		return
+	}
+
+	h.render(c, http.StatusOK, quote)
+}
+
+// quoteError answers a failed quote
+func quoteError(c *gin.Context, err error) {
+	var verr *service.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": verr.Message})
+	case errors.Is(err, service.ErrInvalidAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid amount"})
+	case errors.Is(err, service.ErrInvalidCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency pair"})
+	case errors.Is(err, service.ErrInvalidPromoCode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promo code"})
+	case errors.Is(err, service.ErrPromoCodeExpired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "promo code expired"})
+	case errors.Is(err, service.ErrPromoCodeUsageExceeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "promo code usage limit reached"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get quote"})
+	}
+}
+
+// ListCurrencyPairs lists the corridors and whether they are open
+func (h *Handler) ListCurrencyPairs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"currency_pairs": h.svc.ListCurrencyPairs()})
+}
+
+// GetExchangeRate handles exchange rate requests. The pair is taken from the
+// source and target query parameters, or the default pair when both are
+// omitted.
 func (h *Handler) GetExchangeRate(c *gin.Context) {
-	rate, err := h.svc.GetExchangeRate(c.Request.Context())
+	rate, err := h.svc.GetExchangeRate(c.Request.Context(), c.Query("source"), c.Query("target"))
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency pair"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get exchange rate"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"rate": rate})
+	resp := gin.H{
+		"source_currency": rate.SourceCurrency,
+		"target_currency": rate.TargetCurrency,
+		"rate":            domain.RoundRate(rate.Rate, h.config.RateDecimals),
+	}
+	if estimate, err := h.svc.EstimateQuoteDelivery(c.Request.Context(), rate.SourceCurrency, rate.TargetCurrency); err == nil {
+		resp["estimated_delivery"] = estimate
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,1065 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/api/middleware"
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+	"github.com/remit-demo/remit-go/internal/service"
+)
+
+// stubService is a service.Service answering the calls a test sets up.
+// Calling any other method panics on the nil embedded interface.
+type stubService struct {
+	service.Service
+
+	initiate           func(req *service.InitiateRequest) (*domain.Transaction, error)
+	getUserTransaction func(userID, id string) (*domain.Transaction, error)
+	getTransaction     func(id string) (*domain.Transaction, error)
+	getExchangeRate    func(source, target string) (*domain.ExchangeRate, error)
+	quote              func(req *service.QuoteRequest) (*domain.Quote, error)
+	listUser           func(limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error)
+	listRecipient      func(account string, cursor string) ([]*domain.Transaction, string, error)
+	search             func(reference string, limit int, cursor string) ([]*domain.Transaction, string, error)
+	setPairEnabled     func(source, target string, enabled bool) (*domain.CurrencyPair, error)
+	providerDebug      func(id string) (*service.ProviderDebug, error)
+	rateBreakdown      func(userID, id string) (*domain.RateBreakdown, error)
+	paymentLink        func(userID, id string) (*domain.PaymentDetails, error)
+	startExport        func(userID string) (*domain.ExportJob, error)
+	exportFile         func(userID, id string) ([]byte, *domain.ExportJob, error)
+	cancelExport       func(userID, id string) (*domain.ExportJob, error)
+	paymentCallback    func(cb *service.PaymentCallback) error
+	transferCallback   func(cb *service.TransferCallback) error
+	dependencies       map[string]error
+}
+
+func (s *stubService) InitiateTransaction(ctx context.Context, req *service.InitiateRequest) (*domain.Transaction, error) {
+	return s.initiate(req)
+}
+
+func (s *stubService) GetUserTransaction(ctx context.Context, userID, id string) (*domain.Transaction, error) {
+	return s.getUserTransaction(userID, id)
+}
+
+func (s *stubService) GetTransaction(ctx context.Context, id string) (*domain.Transaction, error) {
+	return s.getTransaction(id)
+}
+
+func (s *stubService) GetRateBreakdown(ctx context.Context, userID, id string) (*domain.RateBreakdown, error) {
+	return s.rateBreakdown(userID, id)
+}
+
+func (s *stubService) GeneratePaymentLink(ctx context.Context, userID, id string) (*domain.PaymentDetails, error) {
+	return s.paymentLink(userID, id)
+}
+
+func (s *stubService) StartExport(ctx context.Context, userID string) (*domain.ExportJob, error) {
+	return s.startExport(userID)
+}
+
+func (s *stubService) ExportFile(ctx context.Context, userID, id string) ([]byte, *domain.ExportJob, error) {
+	return s.exportFile(userID, id)
+}
+
+func (s *stubService) CancelExport(ctx context.Context, userID, id string) (*domain.ExportJob, error) {
+	return s.cancelExport(userID, id)
+}
+
+func (s *stubService) ListUserTransactions(ctx context.Context, userID string, limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+	return s.listUser(limit, lastKey, order)
+}
+
+func (s *stubService) ListRecipientTransactions(ctx context.Context, userID, account string, limit int, cursor string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+	return s.listRecipient(account, cursor)
+}
+
+func (s *stubService) SearchTransactions(ctx context.Context, reference string, limit int, cursor string) ([]*domain.Transaction, string, error) {
+	return s.search(reference, limit, cursor)
+}
+
+func (s *stubService) SetCurrencyPairEnabled(source, target string, enabled bool) (*domain.CurrencyPair, error) {
+	return s.setPairEnabled(source, target, enabled)
+}
+
+func (s *stubService) GetProviderDebug(ctx context.Context, id string) (*service.ProviderDebug, error) {
+	return s.providerDebug(id)
+}
+
+func (s *stubService) HandlePaymentCallback(ctx context.Context, cb *service.PaymentCallback) error {
+	return s.paymentCallback(cb)
+}
+
+func (s *stubService) HandleTransferCallback(ctx context.Context, cb *service.TransferCallback) error {
+	return s.transferCallback(cb)
+}
+
+func (s *stubService) CheckDependencies(ctx context.Context) map[string]error {
+	return s.dependencies
+}
+
+func (s *stubService) GetExchangeRate(ctx context.Context, source, target string) (*domain.ExchangeRate, error) {
+	return s.getExchangeRate(source, target)
+}
+
+func (s *stubService) Quote(ctx context.Context, req *service.QuoteRequest) (*domain.Quote, error) {
+	return s.quote(req)
+}
+
+func (s *stubService) EstimateQuoteDelivery(ctx context.Context, source, target string) (*domain.DeliveryEstimate, error) {
+	return nil, errors.New("no delivery window")
+}
+
+// newRouter returns a router whose requests are authenticated as userID
+// and rendered in the API version
+func newRouter(userID, version string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(APIVersion(version), func(c *gin.Context) {
+		if userID != "" {
+			c.Set("user_id", userID)
+		}
+		c.Next()
+	})
+	return router
+}
+
+// serve sends a request with an optional JSON body through router
+func serve(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode unmarshals a response body into a generic JSON value
+func decode(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %q: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+// testTransaction returns a 10000 INR to CAD transaction awaiting payment
+func testTransaction() *domain.Transaction {
+	tx := domain.NewTransaction("user-1", 10000, "INR", "CAD", &domain.RecipientDetails{
+		Name: "Jane Doe", BankAccount: "12345678", BankCode: "TD001",
+	})
+	tx.ID = "TXN-1"
+	tx.SetFees(&domain.Fees{BaseFee: 50, VariableFee: 100, TotalFee: 150})
+	tx.SetRates(0.0165, 0.016, 0.03, domain.FeeModelExclusive)
+	tx.Status = domain.StatusPaymentPending
+	tx.CreatedAt = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	return tx
+}
+
+func TestGetTransactionVersions(t *testing.T) {
+	tests := []struct {
+		version string
+		check   func(t *testing.T, body map[string]interface{})
+	}{
+		{APIVersionV1, func(t *testing.T, body map[string]interface{}) {
+			if body["id"] != "TXN-1" || body["source_amount"] != 10000.0 || body["status"] != "PAYMENT_PENDING" {
+				t.Errorf("v1 body = %v, want the domain transaction", body)
+			}
+			if _, ok := body["data"]; ok {
+				t.Errorf("v1 body has an envelope: %v", body)
+			}
+		}},
+		{APIVersionV2, func(t *testing.T, body map[string]interface{}) {
+			meta, _ := body["meta"].(map[string]interface{})
+			if meta["api_version"] != APIVersionV2 {
+				t.Errorf("meta = %v, want api_version v2", body["meta"])
+			}
+			data, _ := body["data"].(map[string]interface{})
+			if data["transaction_id"] != "TXN-1" || data["status"] != "PAYMENT_PENDING" {
+				t.Errorf("data = %v, want the v2 transaction", data)
+			}
+			source, _ := data["source"].(map[string]interface{})
+			if source["amount"] != 10000.0 || source["currency"] != "INR" || source["formatted"] == "" {
+				t.Errorf("source = %v, want 10000 INR with its display form", source)
+			}
+			recipient, _ := data["recipient"].(map[string]interface{})
+			if recipient["account_number"] != "12345678" {
+				t.Errorf("recipient = %v", recipient)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				getUserTransaction: func(userID, id string) (*domain.Transaction, error) {
+					return testTransaction(), nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", tt.version)
+			router.GET("/transactions/:id", h.GetTransaction)
+
+			rec := serve(router, http.MethodGet, "/transactions/TXN-1", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("API-Version"); got != tt.version {
+				t.Errorf("API-Version = %q, want %q", got, tt.version)
+			}
+			tt.check(t, decode(t, rec))
+		})
+	}
+}
+
+func TestGetTransactionPaidAmount(t *testing.T) {
+	paid := func(body map[string]interface{}, version string) (interface{}, interface{}) {
+		if version == APIVersionV2 {
+			data, _ := body["data"].(map[string]interface{})
+			payment, _ := data["payment"].(map[string]interface{})
+			amount, _ := payment["paid_amount"].(map[string]interface{})
+			return amount["amount"], amount["currency"]
+		}
+		payment, _ := body["payment_details"].(map[string]interface{})
+		return payment["paid_amount"], payment["currency"]
+	}
+
+	tests := []struct {
+		name         string
+		version      string
+		paid         bool
+		wantAmount   interface{}
+		wantCurrency interface{}
+	}{
+		{"v1 paid", APIVersionV1, true, 10150.0, "INR"},
+		{"v1 unpaid", APIVersionV1, false, nil, nil},
+		{"v2 paid", APIVersionV2, true, 10150.0, "INR"},
+		{"v2 unpaid", APIVersionV2, false, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := testTransaction()
+			tx.PaymentDetails = &domain.PaymentDetails{PaymentID: "PAY-TXN-1", Method: domain.PaymentMethodUPI, Status: "PENDING"}
+			if tt.paid {
+				tx.PaymentDetails.Status = "SUCCESS"
+				tx.PaymentDetails.PaidAmount = 10150
+				tx.PaymentDetails.Currency = "INR"
+			}
+			h := NewHandler(&stubService{
+				getUserTransaction: func(userID, id string) (*domain.Transaction, error) { return tx, nil },
+			}, &Config{})
+			router := newRouter("user-1", tt.version)
+			router.GET("/transactions/:id", h.GetTransaction)
+
+			rec := serve(router, http.MethodGet, "/transactions/TXN-1", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			amount, currency := paid(decode(t, rec), tt.version)
+			if amount != tt.wantAmount || currency != tt.wantCurrency {
+				t.Errorf("paid = %v %v, want %v %v", amount, currency, tt.wantAmount, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestFormatAmounts(t *testing.T) {
+	svc := &stubService{
+		getUserTransaction: func(userID, id string) (*domain.Transaction, error) {
+			return testTransaction(), nil
+		},
+		listUser: func(limit int, cursor string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+			return []*domain.Transaction{testTransaction()}, "", nil
+		},
+		quote: func(req *service.QuoteRequest) (*domain.Quote, error) {
+			return &domain.Quote{SourceAmount: 100000, SourceCurrency: "INR", TargetAmount: 1600, TargetCurrency: "CAD"}, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantSource string
+		wantTarget string
+	}{
+		{"transaction", "/transactions/TXN-1?format=true", "₹10,000.00", "CA$160.00"},
+		{"transaction without format", "/transactions/TXN-1", "", ""},
+		{"transaction with format off", "/transactions/TXN-1?format=false", "", ""},
+		{"listing", "/transactions?format=true", "₹10,000.00", "CA$160.00"},
+		{"quote", "/quote?amount=100000&format=true", "₹1,00,000.00", "CA$1,600.00"},
+		{"quote without format", "/quote?amount=100000", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(svc, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/transactions/:id", h.GetTransaction)
+			router.GET("/transactions", h.ListTransactions)
+			router.GET("/quote", h.GetQuote)
+
+			rec := serve(router, http.MethodGet, tt.target, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			body := decode(t, rec)
+			if txns, ok := body["transactions"].([]interface{}); ok {
+				body = txns[0].(map[string]interface{})
+			}
+			source, _ := body["source_display"].(string)
+			target, _ := body["target_display"].(string)
+			if source != tt.wantSource || target != tt.wantTarget {
+				t.Errorf("display = %q, %q; want %q, %q", source, target, tt.wantSource, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestRateDecimals(t *testing.T) {
+	tests := []struct {
+		version  string
+		decimals int
+		wantRate float64
+	}{
+		{APIVersionV1, 0, 0.01604312},
+		{APIVersionV1, 4, 0.016},
+		{APIVersionV2, 0, 0.01604312},
+		{APIVersionV2, 4, 0.016},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.version, "/", tt.decimals), func(t *testing.T) {
+			tx := testTransaction()
+			tx.SetRates(0.01612374, 0.01604312, 0.005, domain.FeeModelExclusive)
+			wantTarget := tx.TargetAmount
+			h := NewHandler(&stubService{
+				getUserTransaction: func(userID, id string) (*domain.Transaction, error) { return tx, nil },
+			}, &Config{RateDecimals: tt.decimals})
+			router := newRouter("user-1", tt.version)
+			router.GET("/transactions/:id", h.GetTransaction)
+
+			rec := serve(router, http.MethodGet, "/transactions/TXN-1", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			body := decode(t, rec)
+			target := body["target_amount"]
+			if tt.version == APIVersionV2 {
+				body, _ = body["data"].(map[string]interface{})
+				money, _ := body["target"].(map[string]interface{})
+				target = money["amount"]
+				wantTarget = domain.RoundAmount(wantTarget, "CAD")
+			}
+			if body["exchange_rate"] != tt.wantRate {
+				t.Errorf("exchange_rate = %v, want %v", body["exchange_rate"], tt.wantRate)
+			}
+			// The target amount keeps the precision of the unrounded rate
+			if target != wantTarget {
+				t.Errorf("target amount = %v, want %v", target, wantTarget)
+			}
+		})
+	}
+}
+
+func TestGetExchangeRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantPair   [2]string
+		wantStatus int
+	}{
+		{"default pair", "", [2]string{"", ""}, http.StatusOK},
+		{"requested pair", "?source=INR&target=USD", [2]string{"INR", "USD"}, http.StatusOK},
+		{"unsupported pair", "?source=INR&target=XYZ", [2]string{"INR", "XYZ"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [2]string
+			h := NewHandler(&stubService{
+				getExchangeRate: func(source, target string) (*domain.ExchangeRate, error) {
+					got = [2]string{source, target}
+					if target == "XYZ" {
+						return nil, service.ErrInvalidCurrency
+					}
+					if source == "" {
+						source, target = "INR", "CAD"
+					}
+					return domain.NewExchangeRate("ad_bank", source, target, 0.016), nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/rates", h.GetExchangeRate)
+
+			rec := serve(router, http.MethodGet, "/rates"+tt.query, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got != tt.wantPair {
+				t.Errorf("pair requested = %v, want %v", got, tt.wantPair)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			body := decode(t, rec)
+			if body["source_currency"] != "INR" || body["target_currency"] == "" || body["rate"] != 0.016 {
+				t.Errorf("body = %v, want the quoted pair and rate", body)
+			}
+		})
+	}
+}
+
+func TestInitiateRecordsClientIP(t *testing.T) {
+	var got *service.InitiateRequest
+	h := NewHandler(&stubService{
+		initiate: func(req *service.InitiateRequest) (*domain.Transaction, error) {
+			got = req
+			return testTransaction(), nil
+		},
+	}, &Config{})
+	router := newRouter("user-1", APIVersionV1)
+	router.POST("/transactions", h.InitiateTransaction)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(initiateBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "203.0.113.7:4000"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got == nil {
+		t.Fatalf("status = %d, service not called: %s", rec.Code, rec.Body)
+	}
+	if got.UserID != "user-1" || got.ClientIP != "203.0.113.7" {
+		t.Errorf("initiated by %q from %q, want user-1 from 203.0.113.7", got.UserID, got.ClientIP)
+	}
+}
+
+func TestAuditFieldsAdminOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		admin     bool
+		wantAudit bool
+	}{
+		{"user", false, false},
+		{"admin", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := testTransaction()
+			tx.CreatedBy = "user-1"
+			tx.CreatedByIP = "203.0.113.7"
+			h := NewHandler(&stubService{
+				getUserTransaction: func(userID, id string) (*domain.Transaction, error) { return tx, nil },
+				getTransaction:     func(id string) (*domain.Transaction, error) { return tx, nil },
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			if tt.admin {
+				router.GET("/transactions/:id", h.AdminGetTransaction)
+			} else {
+				router.GET("/transactions/:id", h.GetTransaction)
+			}
+
+			rec := serve(router, http.MethodGet, "/transactions/TXN-1", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			body := decode(t, rec)
+			if body["id"] != "TXN-1" {
+				t.Errorf("body = %v, want the transaction", body)
+			}
+			_, hasUser := body["created_by"]
+			_, hasIP := body["created_by_ip"]
+			if hasUser != tt.wantAudit || hasIP != tt.wantAudit {
+				t.Errorf("created_by shown %v, created_by_ip shown %v; want %v", hasUser, hasIP, tt.wantAudit)
+			}
+			if tt.wantAudit && (body["created_by"] != "user-1" || body["created_by_ip"] != "203.0.113.7") {
+				t.Errorf("audit fields = %v, %v", body["created_by"], body["created_by_ip"])
+			}
+		})
+	}
+}
+
+func TestListTransactionsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"invalid last key", repository.ErrInvalidInput, http.StatusBadRequest},
+		{"index misconfigured", fmt.Errorf("%w: no status-index", repository.ErrIndexMisconfigured), http.StatusServiceUnavailable},
+		{"other failure", errors.New("connection reset"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				listUser: func(limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+					return nil, "", tt.err
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/transactions", h.ListTransactions)
+
+			if rec := serve(router, http.MethodGet, "/transactions", ""); rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	notFound := fmt.Errorf("failed to get transaction: %w", repository.ErrNotFound)
+	svc := &stubService{
+		getUserTransaction: func(userID, id string) (*domain.Transaction, error) { return nil, notFound },
+		paymentCallback:    func(cb *service.PaymentCallback) error { return notFound },
+		transferCallback:   func(cb *service.TransferCallback) error { return notFound },
+	}
+	h := NewHandler(svc, &Config{})
+	router := newRouter("user-1", APIVersionV1)
+	router.GET("/transactions/:id", h.GetTransaction)
+	router.POST("/callbacks/payment", h.HandlePaymentCallback)
+	router.POST("/callbacks/transfer", h.HandleTransferCallback)
+
+	tests := []struct {
+		name      string
+		method    string
+		target    string
+		body      string
+		wantError string
+	}{
+		{"transaction", http.MethodGet, "/transactions/TXN-404", "", "transaction not found"},
+		{"payment callback", http.MethodPost, "/callbacks/payment", `{"payment_id":"PAY-TXN-404","status":"SUCCESS"}`, "payment not found"},
+		{"transfer callback", http.MethodPost, "/callbacks/transfer", `{"transaction_id":"TXN-404","status":"COMPLETED"}`, "transaction not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, tt.method, tt.target, tt.body)
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404: %s", rec.Code, rec.Body)
+			}
+			if body := decode(t, rec); body["error"] != tt.wantError {
+				t.Errorf("error = %v, want %q", body["error"], tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCallbackUnknownStatus(t *testing.T) {
+	svc := &stubService{
+		paymentCallback: func(cb *service.PaymentCallback) error {
+			if !cb.Status.Valid() {
+				return service.ErrUnknownPaymentStatus
+			}
+			return nil
+		},
+		transferCallback: func(cb *service.TransferCallback) error {
+			if !cb.Status.Valid() {
+				return service.ErrUnknownTransferStatus
+			}
+			return nil
+		},
+	}
+	h := NewHandler(svc, &Config{})
+	router := newRouter("", APIVersionV1)
+	router.POST("/callbacks/payment", h.HandlePaymentCallback)
+	router.POST("/callbacks/transfer", h.HandleTransferCallback)
+
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{"known payment status", "/callbacks/payment", `{"payment_id":"PAY-TXN-1","status":"SUCCESS"}`, http.StatusOK, ""},
+		{"unknown payment status", "/callbacks/payment", `{"payment_id":"PAY-TXN-1","status":"REFUNDED"}`, http.StatusBadRequest, "unknown payment status"},
+		{"known transfer status", "/callbacks/transfer", `{"transaction_id":"TXN-1","status":"COMPLETED"}`, http.StatusOK, ""},
+		{"unknown transfer status", "/callbacks/transfer", `{"transaction_id":"TXN-1","status":"CANCELLED"}`, http.StatusBadRequest, "unknown transfer status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, http.MethodPost, tt.target, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if body := decode(t, rec); tt.wantError != "" && body["error"] != tt.wantError {
+				t.Errorf("error = %v, want %q", body["error"], tt.wantError)
+			}
+		})
+	}
+}
+
+// initiateBody is a valid transaction initiation request
+const initiateBody = `{"amount":10000,"recipient":{"name":"Jane Doe","bank_account":"12345678","bank_code":"TD001"}}`
+
+func TestInitiateIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		replayed     bool
+		err          error
+		wantStatus   int
+		wantReplayed string
+	}{
+		{"first request", "k1", false, nil, http.StatusCreated, ""},
+		{"replayed request", "k1", true, nil, http.StatusOK, "true"},
+		{"without a key", "", false, nil, http.StatusCreated, ""},
+		{"key in flight", "k1", false, service.ErrIdempotencyInProgress, http.StatusConflict, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotKey string
+			h := NewHandler(&stubService{
+				initiate: func(req *service.InitiateRequest) (*domain.Transaction, error) {
+					gotKey = req.IdempotencyKey
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					tx := testTransaction()
+					tx.Replayed = tt.replayed
+					return tx, nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.POST("/transactions", h.InitiateTransaction)
+
+			req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(initiateBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if gotKey != tt.key {
+				t.Errorf("idempotency key = %q, want %q", gotKey, tt.key)
+			}
+			if got := rec.Header().Get("X-Idempotency-Replayed"); got != tt.wantReplayed {
+				t.Errorf("X-Idempotency-Replayed = %q, want %q", got, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestInitiateAsync(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      string
+		query        string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"synchronous", APIVersionV1, "", http.StatusCreated, ""},
+		{"async off", APIVersionV1, "?async=false", http.StatusCreated, ""},
+		{"async", APIVersionV1, "?async=true", http.StatusAccepted, "/api/v1/transactions/TXN-1"},
+		{"async v2", APIVersionV2, "?async=true", http.StatusAccepted, "/api/v2/transactions/TXN-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				initiate: func(req *service.InitiateRequest) (*domain.Transaction, error) {
+					return testTransaction(), nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", tt.version)
+			router.POST("/transactions", h.InitiateTransaction)
+
+			rec := serve(router, http.MethodPost, "/transactions"+tt.query, initiateBody)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+
+			body := decode(t, rec)
+			if tt.version == APIVersionV2 {
+				body, _ = body["data"].(map[string]interface{})
+			}
+			if body["id"] != "TXN-1" || body["status"] != "PAYMENT_PENDING" {
+				t.Errorf("body = %v, want the transaction's id and status", body)
+			}
+			if _, full := body["source_amount"]; full == (tt.wantStatus == http.StatusAccepted) {
+				t.Errorf("body = %v, want the full transaction only when synchronous", body)
+			}
+		})
+	}
+}
+
+func TestSearchTransactions(t *testing.T) {
+	tests := []struct {
+		name          string
+		target        string
+		err           error
+		wantStatus    int
+		wantReference string
+	}{
+		{"found", "/admin/transactions/search?reference=invoice-42", nil, http.StatusOK, "invoice-42"},
+		{"reference required", "/admin/transactions/search", nil, http.StatusBadRequest, ""},
+		{"index misconfigured", "/admin/transactions/search?reference=invoice-42", fmt.Errorf("%w: no reference-index", repository.ErrIndexMisconfigured), http.StatusServiceUnavailable, "invoice-42"},
+		{"storage failure", "/admin/transactions/search?reference=invoice-42", errors.New("throttled"), http.StatusInternalServerError, "invoice-42"},
+		{"foreign cursor", "/admin/transactions/search?reference=invoice-42", repository.ErrInvalidInput, http.StatusBadRequest, "invoice-42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var searched string
+			h := NewHandler(&stubService{
+				search: func(reference string, limit int, cursor string) ([]*domain.Transaction, string, error) {
+					searched = reference
+					if tt.err != nil {
+						return nil, "", tt.err
+					}
+					tx := testTransaction()
+					tx.Reference = reference
+					return []*domain.Transaction{tx}, "", nil
+				},
+			}, &Config{})
+			router := newRouter("admin-1", APIVersionV1)
+			router.GET("/admin/transactions/search", h.SearchTransactions)
+
+			rec := serve(router, http.MethodGet, tt.target, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if searched != tt.wantReference {
+				t.Errorf("searched %q, want %q", searched, tt.wantReference)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			txns, _ := decode(t, rec)["transactions"].([]interface{})
+			if len(txns) != 1 {
+				t.Fatalf("transactions = %v, want TXN-1", txns)
+			}
+			if tx := txns[0].(map[string]interface{}); tx["id"] != "TXN-1" || tx["reference"] != "invoice-42" {
+				t.Errorf("transaction = %v, want TXN-1 with its reference", tx)
+			}
+		})
+	}
+}
+
+func TestSearchTransactionsPageSizes(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantLimit  int
+		wantCursor string
+	}{
+		{"configured default page", "", http.StatusOK, 20, ""},
+		{"configured maximum", "&limit=80", http.StatusOK, 50, ""},
+		{"next page", "&limit=5&last_key=abc", http.StatusOK, 5, "abc"},
+		{"invalid limit", "&limit=0", http.StatusBadRequest, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var limit int
+			var cursor string
+			h := NewHandler(&stubService{
+				search: func(reference string, l int, c string) ([]*domain.Transaction, string, error) {
+					limit, cursor = l, c
+					return nil, "next", nil
+				},
+			}, &Config{})
+			router := newRouter("admin-1", APIVersionV1)
+			router.GET("/admin/transactions/search", middleware.Pagination(20, 50), h.SearchTransactions)
+
+			rec := serve(router, http.MethodGet, "/admin/transactions/search?reference=invoice-42"+tt.query, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if limit != tt.wantLimit || cursor != tt.wantCursor {
+				t.Errorf("searched with limit %d, cursor %q; want %d, %q", limit, cursor, tt.wantLimit, tt.wantCursor)
+			}
+			if rec.Code == http.StatusOK && decode(t, rec)["next_key"] != "next" {
+				t.Errorf("next_key = %v, want next", decode(t, rec)["next_key"])
+			}
+		})
+	}
+}
+
+func TestSetCurrencyPairEnabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		body        string
+		wantStatus  int
+		wantCalled  bool
+		wantEnabled bool
+	}{
+		{"pause", "/admin/currency-pairs/INR/CAD", `{"enabled":false}`, http.StatusOK, true, false},
+		{"resume", "/admin/currency-pairs/INR/CAD", `{"enabled":true}`, http.StatusOK, true, true},
+		{"enabled required", "/admin/currency-pairs/INR/CAD", `{}`, http.StatusBadRequest, false, false},
+		{"unknown pair", "/admin/currency-pairs/INR/USD", `{"enabled":false}`, http.StatusNotFound, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *bool
+			h := NewHandler(&stubService{
+				setPairEnabled: func(source, target string, enabled bool) (*domain.CurrencyPair, error) {
+					got = &enabled
+					if source != "INR" || target != "CAD" {
+						return nil, service.ErrInvalidCurrency
+					}
+					return &domain.CurrencyPair{Source: source, Target: target, Enabled: enabled}, nil
+				},
+			}, &Config{})
+			router := newRouter("admin-1", APIVersionV1)
+			router.PUT("/admin/currency-pairs/:source/:target", h.SetCurrencyPairEnabled)
+
+			rec := serve(router, http.MethodPut, tt.target, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if (got != nil) != tt.wantCalled || (got != nil && *got != tt.wantEnabled) {
+				t.Errorf("service called %v with enabled %v, want called %v with %v", got != nil, got, tt.wantCalled, tt.wantEnabled)
+			}
+			if rec.Code == http.StatusOK {
+				if body := decode(t, rec); body["enabled"] != tt.wantEnabled || body["source"] != "INR" {
+					t.Errorf("body = %v", body)
+				}
+			}
+		})
+	}
+}
+
+func TestGetProviderDebug(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"found", nil, http.StatusOK},
+		{"missing transaction", fmt.Errorf("failed to get transaction: %w", repository.ErrNotFound), http.StatusNotFound},
+		{"storage failure", errors.New("throttled"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				providerDebug: func(id string) (*service.ProviderDebug, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &service.ProviderDebug{
+						TransactionID: id,
+						Transfer:      map[string]interface{}{"status": "bounced_back"},
+						PaymentError:  "gateway timeout",
+					}, nil
+				},
+			}, &Config{})
+			router := newRouter("admin-1", APIVersionV1)
+			router.GET("/admin/transactions/:id/provider-debug", h.GetProviderDebug)
+
+			rec := serve(router, http.MethodGet, "/admin/transactions/TXN-1/provider-debug", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			body := decode(t, rec)
+			transfer, _ := body["transfer"].(map[string]interface{})
+			if body["transaction_id"] != "TXN-1" || transfer["status"] != "bounced_back" || body["payment_error"] != "gateway timeout" {
+				t.Errorf("body = %v, want the provider records of TXN-1", body)
+			}
+		})
+	}
+}
+
+func TestGetRateBreakdown(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"found", nil, http.StatusOK},
+		{"missing transaction", fmt.Errorf("failed to get transaction: %w", repository.ErrNotFound), http.StatusNotFound},
+		{"storage failure", errors.New("throttled"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				rateBreakdown: func(userID, id string) (*domain.RateBreakdown, error) {
+					if userID != "user-1" {
+						t.Errorf("breakdown asked for user %q, want user-1", userID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					tx := testTransaction()
+					tx.ID = id
+					return tx.RateBreakdown(), nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/transactions/:id/rate-breakdown", h.GetRateBreakdown)
+
+			rec := serve(router, http.MethodGet, "/transactions/TXN-1/rate-breakdown", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			body := decode(t, rec)
+			if body["transaction_id"] != "TXN-1" || body["mid_market_rate"] != 0.0165 || body["customer_rate"] != 0.016 || body["margin_percent"] != 3.0 {
+				t.Errorf("body = %v, want 0.0165 mid-market, 3%% margin and 0.016 customer rate for TXN-1", body)
+			}
+		})
+	}
+}
+
+func TestListTransactionsSort(t *testing.T) {
+	tests := []struct {
+		target     string
+		wantStatus int
+		wantOrder  repository.SortOrder
+	}{
+		{"/transactions", http.StatusOK, repository.SortDescending},
+		{"/transactions?sort=desc", http.StatusOK, repository.SortDescending},
+		{"/transactions?sort=asc", http.StatusOK, repository.SortAscending},
+		{"/transactions?sort=ASC", http.StatusBadRequest, ""},
+		{"/transactions?sort=newest", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			var got repository.SortOrder
+			h := NewHandler(&stubService{
+				listUser: func(limit int, cursor string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+					got = order
+					return nil, "", nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/transactions", h.ListTransactions)
+
+			rec := serve(router, http.MethodGet, tt.target, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got != tt.wantOrder {
+				t.Errorf("listed in order %q, want %q", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestInitiateIDCollision(t *testing.T) {
+	h := NewHandler(&stubService{
+		initiate: func(req *service.InitiateRequest) (*domain.Transaction, error) {
+			return nil, service.ErrIDCollision
+		},
+	}, &Config{})
+	router := newRouter("user-1", APIVersionV1)
+	router.POST("/transactions", h.InitiateTransaction)
+
+	rec := serve(router, http.MethodPost, "/transactions", initiateBody)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("no Retry-After header on a retryable conflict")
+	}
+	if body := decode(t, rec); body["code"] != "id_collision" {
+		t.Errorf("body = %v, want code id_collision", body)
+	}
+}
+
+func TestInitiateRecipientSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		recipient  string
+		err        error // returned by the service
+		wantStatus int
+	}{
+		{"within the caps", `{"name":"` + strings.Repeat("x", 100) + `","bank_account":"` + strings.Repeat("1", 20) + `","bank_code":"TD001000000"}`, nil, http.StatusCreated},
+		{"name too long", `{"name":"` + strings.Repeat("x", 101) + `","bank_account":"12345678","bank_code":"TD001"}`, nil, http.StatusBadRequest},
+		{"account too long", `{"name":"Jane Doe","bank_account":"` + strings.Repeat("1", 21) + `","bank_code":"TD001"}`, nil, http.StatusBadRequest},
+		{"bank code too long", `{"name":"Jane Doe","bank_account":"12345678","bank_code":"TD0010000000"}`, nil, http.StatusBadRequest},
+		{"item too large to store", `{"name":"Jane Doe","bank_account":"12345678","bank_code":"TD001"}`,
+			fmt.Errorf("failed to create transaction: %w", repository.ErrItemTooLarge), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				initiate: func(req *service.InitiateRequest) (*domain.Transaction, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return testTransaction(), nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.POST("/transactions", h.InitiateTransaction)
+
+			rec := serve(router, http.MethodPost, "/transactions", `{"amount":10000,"recipient":`+tt.recipient+`}`)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestGeneratePaymentLink(t *testing.T) {
+	mismatch := &service.ValidationError{Err: service.ErrMethodCurrencyMismatch, Message: "UPI payments cannot be made in USD"}
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{"generated", nil, http.StatusOK, ""},
+		{"method cannot collect the currency", mismatch, http.StatusBadRequest, "UPI payments cannot be made in USD"},
+		{"missing transaction", fmt.Errorf("failed to get transaction: %w", repository.ErrNotFound), http.StatusNotFound, "transaction not found"},
+		{"provider failure", errors.New("gateway timeout"), http.StatusInternalServerError, "failed to generate payment link"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{
+				paymentLink: func(userID, id string) (*domain.PaymentDetails, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &domain.PaymentDetails{PaymentID: "PAY-" + id, Method: domain.PaymentMethodUPI, PaymentLink: "upi://pay?tr=" + id}, nil
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.POST("/transactions/:id/payment", h.GeneratePaymentLink)
+
+			rec := serve(router, http.MethodPost, "/transactions/TXN-1/payment", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if body := decode(t, rec); tt.wantError != "" && body["error"] != tt.wantError {
+				t.Errorf("error = %v, want %q", body["error"], tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz reports that the process is up
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz reports whether every integration answers its health probe. Any
+// failing dependency makes the whole service unready.
+func (h *Handler) Readyz(c *gin.Context) {
+	checks := gin.H{}
+	status := http.StatusOK
+	for name, err := range h.svc.CheckDependencies(c.Request.Context()) {
+		if err != nil {
+			checks[name] = err.Error()
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		checks[name] = "ok"
+	}
+
+	result := "ready"
+	if status != http.StatusOK {
+		result = "unready"
+	}
+	c.JSON(status, gin.H{"status": result, "checks": checks})
+}
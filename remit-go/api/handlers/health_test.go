@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name       string
+		deps       map[string]error
+		wantStatus int
+		wantResult string
+	}{
+		{"all up", map[string]error{"upi": nil, "ad_bank": nil, "wise": nil}, http.StatusOK, "ready"},
+		{"one down", map[string]error{"upi": nil, "ad_bank": errors.New("connection refused"), "wise": nil}, http.StatusServiceUnavailable, "unready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{dependencies: tt.deps}, &Config{})
+			router := newRouter("", APIVersionV1)
+			router.GET("/readyz", h.Readyz)
+
+			rec := serve(router, http.MethodGet, "/readyz", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			body := decode(t, rec)
+			if body["status"] != tt.wantResult {
+				t.Errorf("status = %v, want %s", body["status"], tt.wantResult)
+			}
+			checks, _ := body["checks"].(map[string]interface{})
+			for name, err := range tt.deps {
+				want := "ok"
+				if err != nil {
+					want = err.Error()
+				}
+				if checks[name] != want {
+					t.Errorf("%s = %v, want %q", name, checks[name], want)
+				}
+			}
+		})
+	}
+}
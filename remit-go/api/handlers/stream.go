@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/api/dto"
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+const contentTypeNDJSON = "application/x-ndjson"
+
+// wantsNDJSON reports whether the client asked for newline-delimited JSON
+func wantsNDJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), contentTypeNDJSON)
+}
+
+// streamTransactions writes the already fetched first page and every page
+// after it as one JSON object per line, fetching page by page and flushing
+// after each so neither side holds the full history. An error after the
+// first line can no longer change the status, so it is reported as a final
+// {"error": ...} line, as is reaching the configured MaxStreamItems. list
+// fetches the page after a cursor.
+func (h *Handler) streamTransactions(
+	c *gin.Context,
+	txns []*domain.Transaction,
+	nextKey string,
+	list func(ctx context.Context, cursor string) ([]*domain.Transaction, string, error),
+) {
+	c.Header("Content-Type", contentTypeNDJSON)
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	written := 0
+	for {
+		for _, tx := range txns {
+			if max := h.config.MaxStreamItems; max > 0 && written >= max {
+				_ = enc.Encode(gin.H{
+					"error": fmt.Sprintf("stream limit of %d transactions reached; narrow the request with a date filter", max),
+				})
+				return
+			}
+			h.roundRates(tx)
+			var line interface{} = tx
+			if apiVersion(c) == APIVersionV2 {
+				line = dto.NewTransaction(tx)
+			} else {
+				formatAmounts(c, tx)
+			}
+			if err := enc.Encode(line); err != nil {
+				return // client went away
+			}
+			written++
+		}
+		c.Writer.Flush()
+
+		if nextKey == "" {
+			return
+		}
+
+		var err error
+		txns, nextKey, err = list(c.Request.Context(), nextKey)
+		if err != nil {
+			_ = enc.Encode(gin.H{"error": "failed to list transactions"})
+			return
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// pagedHistory returns a listing of pages of two transactions each, failing
+// with failAt when the page at that index is requested
+func pagedHistory(pages int, failAt int) func(limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+	return func(limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+		page := 0
+		if lastKey != "" {
+			page, _ = strconv.Atoi(lastKey)
+		}
+		if page == failAt {
+			return nil, "", errors.New("connection reset")
+		}
+		var txns []*domain.Transaction
+		for i := range 2 {
+			tx := testTransaction()
+			tx.ID = fmt.Sprintf("TXN-%d", page*2+i)
+			txns = append(txns, tx)
+		}
+		next := ""
+		if page+1 < pages {
+			next = strconv.Itoa(page + 1)
+		}
+		return txns, next, nil
+	}
+}
+
+// ndjsonLines decodes each line of a streamed response
+func ndjsonLines(t *testing.T, rec *httptest.ResponseRecorder) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestListTransactionsNDJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		pages     int
+		failAt    int
+		wantIDs   int
+		wantError bool
+	}{
+		{"single page", 1, -1, 2, false},
+		{"every page", 3, -1, 6, false},
+		{"failure after the first page", 3, 1, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{listUser: pagedHistory(tt.pages, tt.failAt)}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/transactions", h.ListTransactions)
+
+			req := httptest.NewRequest(http.MethodGet, "/transactions", nil)
+			req.Header.Set("Accept", "application/x-ndjson")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/x-ndjson" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			lines := ndjsonLines(t, rec)
+			var ids int
+			for i, line := range lines {
+				if _, ok := line["error"]; ok {
+					if !tt.wantError || i != len(lines)-1 {
+						t.Errorf("line %d is an error: %v", i, line)
+					}
+					continue
+				}
+				if line["id"] != fmt.Sprintf("TXN-%d", ids) {
+					t.Errorf("line %d = %v, want TXN-%d", i, line["id"], ids)
+				}
+				ids++
+			}
+			if ids != tt.wantIDs {
+				t.Errorf("transactions streamed = %d, want %d", ids, tt.wantIDs)
+			}
+			if _, ok := lines[len(lines)-1]["error"]; ok != tt.wantError {
+				t.Errorf("last line = %v, want error %v", lines[len(lines)-1], tt.wantError)
+			}
+		})
+	}
+}
+
+func TestListTransactionsWithoutNDJSON(t *testing.T) {
+	h := NewHandler(&stubService{listUser: pagedHistory(3, -1)}, &Config{})
+	router := newRouter("user-1", APIVersionV1)
+	router.GET("/transactions", h.ListTransactions)
+
+	rec := serve(router, http.MethodGet, "/transactions", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := decode(t, rec)
+	if txns, _ := body["transactions"].([]interface{}); len(txns) != 2 || body["next_key"] != "1" {
+		t.Errorf("body = %v, want the first page and its key", body)
+	}
+}
+
+func TestListTransactionsNDJSONStreamLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		wantIDs   int
+		wantLimit bool
+	}{
+		{"no limit", 0, 6, false},
+		{"limit within a page", 3, 3, true},
+		{"limit at a page boundary", 4, 4, true},
+		{"limit above the history", 10, 6, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&stubService{listUser: pagedHistory(3, -1)}, &Config{MaxStreamItems: tt.max})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/transactions", h.ListTransactions)
+
+			req := httptest.NewRequest(http.MethodGet, "/transactions", nil)
+			req.Header.Set("Accept", "application/x-ndjson")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			lines := ndjsonLines(t, rec)
+			ids := len(lines)
+			last, limited := lines[len(lines)-1]["error"].(string)
+			if limited {
+				ids--
+			}
+			if ids != tt.wantIDs {
+				t.Errorf("transactions streamed = %d, want %d", ids, tt.wantIDs)
+			}
+			if limited != tt.wantLimit {
+				t.Fatalf("last line = %v, want limit error %v", lines[len(lines)-1], tt.wantLimit)
+			}
+			if limited && !strings.Contains(last, fmt.Sprintf("stream limit of %d", tt.max)) {
+				t.Errorf("error = %q, want the stream limit", last)
+			}
+		})
+	}
+}
+
+func TestListTransactionsByRecipientAccount(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		ndjson      bool
+		wantAccount string // empty when the full history is listed
+		wantIDs     int
+	}{
+		{"full history", "", false, "", 2},
+		{"recipient", "?recipient_account=12345678", false, "12345678", 2},
+		{"recipient trimmed", "?recipient_account=%2012345678%20", false, "12345678", 2},
+		{"recipient streamed", "?recipient_account=12345678", true, "12345678", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var account string
+			var pages int
+			history := pagedHistory(3, -1)
+			h := NewHandler(&stubService{
+				listUser: history,
+				listRecipient: func(a string, cursor string) ([]*domain.Transaction, string, error) {
+					account = a
+					pages++
+					return history(0, cursor, repository.SortDescending)
+				},
+			}, &Config{})
+			router := newRouter("user-1", APIVersionV1)
+			router.GET("/transactions", h.ListTransactions)
+
+			req := httptest.NewRequest(http.MethodGet, "/transactions"+tt.query, nil)
+			if tt.ndjson {
+				req.Header.Set("Accept", "application/x-ndjson")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			if account != tt.wantAccount {
+				t.Errorf("recipient account = %q, want %q", account, tt.wantAccount)
+			}
+			ids := 0
+			if tt.ndjson {
+				ids = len(ndjsonLines(t, rec))
+			} else {
+				txns, _ := decode(t, rec)["transactions"].([]interface{})
+				ids = len(txns)
+			}
+			if ids != tt.wantIDs {
+				t.Errorf("transactions = %d, want %d", ids, tt.wantIDs)
+			}
+			// Every page, streamed ones included, comes from the recipient listing
+			if tt.wantAccount != "" && pages != tt.wantIDs/2 {
+				t.Errorf("recipient pages = %d, want %d", pages, tt.wantIDs/2)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/api/dto"
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// API versions served by the handlers
+const (
+	APIVersionV1 = "v1"
+	APIVersionV2 = "v2"
+)
+
+const apiVersionKey = "api_version"
+
+// APIVersion tags requests on a route group with the API version whose
+// response shape should be rendered
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Header("API-Version", version)
+		c.Next()
+	}
+}
+
+// apiVersion returns the negotiated API version, defaulting to v1
+func apiVersion(c *gin.Context) string {
+	if version := c.GetString(apiVersionKey); version != "" {
+		return version
+	}
+	return APIVersionV1
+}
+
+// formatAmounts adds the currency-formatted display amounts to v1
+// transactions and quotes when the request has ?format=true. v2 always
+// carries them in its money values.
+func formatAmounts(c *gin.Context, data interface{}) {
+	if c.Query("format") != "true" {
+		return
+	}
+
+	switch v := data.(type) {
+	case *domain.Transaction:
+		v.FormatAmounts()
+	case []*domain.Transaction:
+		for _, tx := range v {
+			tx.FormatAmounts()
+		}
+	case *domain.Quote:
+		v.FormatAmounts()
+	}
+}
+
+// roundRates rounds the exchange rates of transactions, quotes and rate
+// breakdowns to the configured RateDecimals before they are rendered
+func (h *Handler) roundRates(data interface{}) {
+	decimals := h.config.RateDecimals
+	if decimals <= 0 {
+		return
+	}
+
+	switch v := data.(type) {
+	case *domain.Transaction:
+		v.RoundRates(decimals)
+	case []*domain.Transaction:
+		for _, tx := range v {
+			tx.RoundRates(decimals)
+		}
+	case *domain.Quote:
+		v.RoundRates(decimals)
+	case *domain.RateBreakdown:
+		v.RoundRates(decimals)
+	}
+}
+
+// render writes the response in the shape of the negotiated API version.
+// v1 returns the domain value as-is; v2 returns its DTO wrapped in an envelope.
+func (h *Handler) render(c *gin.Context, status int, data interface{}) {
+	h.roundRates(data)
+	if apiVersion(c) == APIVersionV2 {
+		c.JSON(status, dto.Envelope{
+			Data: dto.From(data),
+			Meta: dto.Meta{APIVersion: APIVersionV2},
+		})
+		return
+	}
+
+	formatAmounts(c, data)
+	c.JSON(status, data)
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParseIPAllowlist parses addresses and CIDR ranges, e.g. "203.0.113.7" or
+// "198.51.100.0/24", into prefixes
+func ParseIPAllowlist(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address or CIDR %q", entry)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// SourceIPAllowlist rejects with a 403 requests whose client IP is in none
+// of the allowed prefixes. The client IP is resolved by gin, so
+// X-Forwarded-For is only believed from trusted proxies. An empty allowlist
+// allows every address.
+func SourceIPAllowlist(allowed []netip.Prefix) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(allowed) == 0 {
+			c.Next()
+			return
+		}
+
+		addr, err := netip.ParseAddr(c.ClientIP())
+		if err == nil {
+			addr = addr.Unmap()
+			for _, prefix := range allowed {
+				if prefix.Contains(addr) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "source address not allowed"})
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseIPAllowlist(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+		wantErr bool
+	}{
+		{"address", []string{"203.0.113.7"}, []string{"203.0.113.7/32"}, false},
+		{"range", []string{"198.51.100.0/24"}, []string{"198.51.100.0/24"}, false},
+		{"range with host bits", []string{"198.51.100.9/24"}, []string{"198.51.100.0/24"}, false},
+		{"IPv6 address", []string{"2001:db8::1"}, []string{"2001:db8::1/128"}, false},
+		{"IPv4-mapped address", []string{"::ffff:203.0.113.7"}, []string{"203.0.113.7/32"}, false},
+		{"hostname", []string{"example.com"}, nil, true},
+		{"bad range", []string{"198.51.100.0/33"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefixes, err := ParseIPAllowlist(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseIPAllowlist() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(prefixes) != len(tt.want) {
+				t.Fatalf("ParseIPAllowlist() = %v, want %v", prefixes, tt.want)
+			}
+			for i, prefix := range prefixes {
+				if prefix.String() != tt.want[i] {
+					t.Errorf("prefix %d = %s, want %s", i, prefix, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSourceIPAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		allowlist    []string
+		remoteAddr   string
+		forwardedFor string
+		trustedProxy string
+		wantStatus   int
+	}{
+		{"allowed address", []string{"203.0.113.7"}, "203.0.113.7:4000", "", "", http.StatusOK},
+		{"allowed range", []string{"198.51.100.0/24"}, "198.51.100.20:4000", "", "", http.StatusOK},
+		{"denied address", []string{"203.0.113.7"}, "203.0.113.8:4000", "", "", http.StatusForbidden},
+		{"denied outside range", []string{"198.51.100.0/24"}, "198.51.101.20:4000", "", "", http.StatusForbidden},
+		{"empty allowlist allows all", nil, "192.0.2.1:4000", "", "", http.StatusOK},
+		{"forwarded address from untrusted peer ignored", []string{"203.0.113.7"}, "192.0.2.1:4000", "203.0.113.7", "", http.StatusForbidden},
+		{"forwarded address from trusted proxy", []string{"203.0.113.7"}, "10.0.0.1:4000", "203.0.113.7", "10.0.0.1", http.StatusOK},
+		{"denied forwarded address from trusted proxy", []string{"203.0.113.7"}, "10.0.0.1:4000", "192.0.2.1", "10.0.0.1", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := ParseIPAllowlist(tt.allowlist)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router := gin.New()
+			var proxies []string
+			if tt.trustedProxy != "" {
+				proxies = []string{tt.trustedProxy}
+			}
+			if err := router.SetTrustedProxies(proxies); err != nil {
+				t.Fatal(err)
+			}
+			router.POST("/callback", SourceIPAllowlist(allowed), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/callback", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// signToken returns an HS256 JWT of claims signed with key
+func signToken(t *testing.T, key string, claims Claims) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseToken(t *testing.T) {
+	now := time.Now()
+	valid := Claims{Subject: "user-1", Tier: "basic", ExpiresAt: now.Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name    string
+		token   string
+		key     string
+		wantErr error
+	}{
+		{"valid", signToken(t, testSecret, valid), testSecret, nil},
+		{"wrong key", signToken(t, "another-secret-another-secret-xx", valid), testSecret, ErrInvalidToken},
+		{"expired", signToken(t, testSecret, Claims{Subject: "user-1", ExpiresAt: now.Add(-time.Minute).Unix()}), testSecret, ErrTokenExpired},
+		{"no subject", signToken(t, testSecret, Claims{ExpiresAt: now.Add(time.Hour).Unix()}), testSecret, ErrInvalidToken},
+		{"malformed", "not-a-token", testSecret, ErrInvalidToken},
+		{"empty key", signToken(t, "", valid), "", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseToken(tt.token, []byte(tt.key), now)
+			if err != tt.wantErr {
+				t.Fatalf("ParseToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && claims.Subject != valid.Subject {
+				t.Errorf("Subject = %q, want %q", claims.Subject, valid.Subject)
+			}
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := Claims{Subject: "attacker", Roles: []string{RoleAdmin}}
+
+	tests := []struct {
+		name       string
+		secret     string
+		token      string
+		wantStatus int
+	}{
+		{"valid token", testSecret, signToken(t, testSecret, admin), http.StatusOK},
+		{"missing token", testSecret, "", http.StatusUnauthorized},
+		{"forged token", testSecret, signToken(t, "", admin), http.StatusUnauthorized},
+		{"empty secret refuses tokens signed with it", "", signToken(t, "", admin), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", Auth(tt.secret), func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString(UserIDKey))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthSetsClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", Auth(testSecret), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(UserIDKey)+" "+c.GetString(UserTierKey))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, testSecret, Claims{Subject: "user-1", Tier: "gold"}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "user-1 gold" {
+		t.Fatalf("status = %d, body = %q; want 200 with the user and tier", rec.Code, rec.Body)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		roles      []string
+		wantStatus int
+	}{
+		{"admin", []string{RoleAdmin}, http.StatusOK},
+		{"no roles", nil, http.StatusForbidden},
+		{"other role", []string{"support"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", Auth(testSecret), RequireRole(RoleAdmin), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signToken(t, testSecret, Claims{Subject: "user-1", Roles: tt.roles}))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+// RequireFeature hides the routes it guards behind a feature flag. Requests
+// to a disabled feature get a 404, as if the route did not exist.
+func RequireFeature(features config.FeaturesConfig, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !features.Enabled(name) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+func TestRequireFeature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		features   config.FeaturesConfig
+		wantStatus int
+	}{
+		{"enabled", config.FeaturesConfig{config.FeatureV2API: true}, http.StatusOK},
+		{"disabled", config.FeaturesConfig{config.FeatureV2API: false}, http.StatusNotFound},
+		{"not listed", config.FeaturesConfig{"other": true}, http.StatusNotFound},
+		{"no flags", nil, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", RequireFeature(tt.features, config.FeatureV2API), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/internal/metrics"
+)
+
+// LoadShed rejects requests with a 503 once more than max of them are in
+// flight at the same time, so new work is shed while the routes it does not
+// guard (callbacks, reads) keep being served. A max of zero disables shedding.
+// Use a single instance per pool of routes that should share the ceiling.
+func LoadShed(max int) gin.HandlerFunc {
+	var inFlight int64
+	return func(c *gin.Context) {
+		n := atomic.AddInt64(&inFlight, 1)
+		metrics.InFlightInitiations.Inc()
+		defer func() {
+			atomic.AddInt64(&inFlight, -1)
+			metrics.InFlightInitiations.Dec()
+		}()
+
+		if max > 0 && n > int64(max) {
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "service busy, retry shortly"})
+			return
+		}
+
+		c.Next()
+	}
+}
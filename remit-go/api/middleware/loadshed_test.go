@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoadShed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		max        int
+		held       int // requests in flight when the probe arrives
+		wantStatus int
+	}{
+		{"below the ceiling", 2, 1, http.StatusOK},
+		{"at the ceiling", 2, 2, http.StatusServiceUnavailable},
+		{"no ceiling", 0, 5, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			entered := make(chan struct{})
+			router := gin.New()
+			shed := LoadShed(tt.max)
+			router.POST("/held", shed, func(c *gin.Context) {
+				entered <- struct{}{}
+				<-release
+				c.Status(http.StatusOK)
+			})
+			router.POST("/probe", shed, func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.GET("/unguarded", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var wg sync.WaitGroup
+			for range tt.held {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/held", nil))
+				}()
+				<-entered
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/probe", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") == "" {
+				t.Error("shed response has no Retry-After")
+			}
+
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unguarded", nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("unguarded route status = %d, want 200", rec.Code)
+			}
+
+			close(release)
+			wg.Wait()
+
+			// Capacity is given back once the held requests finish
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/probe", nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("status after release = %d, want 200", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	mathrand "math/rand/v2"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/internal/service"
+)
+
+// RequestIDHeader carries a request's ID, from the caller and back to it
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID matches the caller-supplied IDs that are kept
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID tags each request with an ID: the caller's X-Request-ID when it
+// is a short token, a fresh one otherwise. The ID is returned in the same
+// header and carried in the request context, where the request log and any
+// background work the request starts pick it up.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(service.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger logs one line per request with its method, route, status and
+// latency. Requests answered with an error status are always logged; the
+// rest only with probability sampleRate, so busy success paths stay quiet.
+// A sampleRate of zero or one and above logs every request.
+func RequestLogger(sampleRate float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && sampleRate > 0 && sampleRate < 1 && mathrand.Float64() >= sampleRate {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		log.Printf("request: request_id=%s method=%s route=%s path=%s status=%d latency=%s client_ip=%s",
+			service.RequestID(c.Request.Context()), c.Request.Method, route, c.Request.URL.Path, status, time.Since(start), c.ClientIP())
+	}
+}
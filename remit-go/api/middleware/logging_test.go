@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/internal/service"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"caller's ID", "req-42.a_b", true},
+		{"no ID", "", false},
+		{"too long", strings.Repeat("a", 65), false},
+		{"unsafe characters", "req 1\nforged=1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inContext string
+			router := gin.New()
+			router.GET("/", RequestID(), func(c *gin.Context) {
+				inContext = service.RequestID(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(RequestIDHeader)
+			if got == "" || got != inContext {
+				t.Fatalf("response ID = %q, context ID = %q; want the same non-empty ID", got, inContext)
+			}
+			if tt.keep != (got == tt.header) {
+				t.Errorf("ID = %q, caller sent %q, keep = %v", got, tt.header, tt.keep)
+			}
+		})
+	}
+}
+
+func TestRequestLoggerSampling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const requests = 4000
+
+	tests := []struct {
+		name       string
+		sampleRate float64
+		status     int
+		wantMin    int
+		wantMax    int
+	}{
+		{"errors always logged", 0.1, http.StatusInternalServerError, requests, requests},
+		{"client errors always logged", 0.1, http.StatusBadRequest, requests, requests},
+		{"successes sampled", 0.25, http.StatusOK, requests/4 - 200, requests/4 + 200},
+		{"sampling off", 0, http.StatusOK, requests, requests},
+		{"full rate", 1, http.StatusOK, requests, requests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged bytes.Buffer
+			log.SetOutput(&logged)
+			defer log.SetOutput(os.Stderr)
+
+			router := gin.New()
+			router.GET("/", RequestLogger(tt.sampleRate), func(c *gin.Context) {
+				c.Status(tt.status)
+			})
+			for range requests {
+				router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+
+			lines := strings.Count(logged.String(), "request: ")
+			if lines < tt.wantMin || lines > tt.wantMax {
+				t.Errorf("logged %d of %d requests, want %d to %d", lines, requests, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
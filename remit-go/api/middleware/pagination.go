@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page size bounds applied to every paginated endpoint unless configured
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// maxLastKeyLength bounds the size of a pagination cursor
+const maxLastKeyLength = 1024
+
+const pageRequestKey = "page_request"
+
+// PageRequest holds validated pagination inputs
+type PageRequest struct {
+	Limit   int
+	LastKey string
+}
+
+// Pagination validation errors
+var (
+	ErrInvalidLimit   = errors.New("limit must be a positive integer")
+	ErrInvalidLastKey = errors.New("last_key is not a valid pagination cursor")
+)
+
+// Pagination parses and validates the limit and last_key query parameters
+// and stores the resulting PageRequest in the context. Invalid input aborts
+// the request with a 400. Zero sizes select DefaultPageSize and MaxPageSize.
+func Pagination(defaultSize, maxSize int) gin.HandlerFunc {
+	if defaultSize <= 0 {
+		defaultSize = DefaultPageSize
+	}
+	if maxSize <= 0 {
+		maxSize = MaxPageSize
+	}
+
+	return func(c *gin.Context) {
+		page, err := ParsePageRequest(c.Query("limit"), c.Query("last_key"), defaultSize, maxSize)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(pageRequestKey, page)
+		c.Next()
+	}
+}
+
+// ParsePageRequest validates raw pagination inputs. An empty limit selects
+// defaultSize and one above maxSize is lowered to it.
+func ParsePageRequest(limit, lastKey string, defaultSize, maxSize int) (PageRequest, error) {
+	page := PageRequest{Limit: min(defaultSize, maxSize)}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return PageRequest{}, ErrInvalidLimit
+		}
+		page.Limit = min(n, maxSize)
+	}
+
+	if lastKey != "" {
+		if len(lastKey) > maxLastKeyLength {
+			return PageRequest{}, ErrInvalidLastKey
+		}
+		if _, err := base64.RawURLEncoding.DecodeString(lastKey); err != nil {
+			return PageRequest{}, ErrInvalidLastKey
+		}
+		page.LastKey = lastKey
+	}
+
+	return page, nil
+}
+
+// GetPageRequest returns the PageRequest stored by the Pagination middleware,
+// or the default page when the middleware did not run
+func GetPageRequest(c *gin.Context) PageRequest {
+	if page, ok := c.Get(pageRequestKey); ok {
+		return page.(PageRequest)
+	}
+	return PageRequest{Limit: DefaultPageSize}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParsePageRequest(t *testing.T) {
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(`{"id":"TXN-1"}`))
+
+	tests := []struct {
+		name    string
+		limit   string
+		lastKey string
+		want    PageRequest
+		wantErr error
+	}{
+		{"defaults", "", "", PageRequest{Limit: 10}, nil},
+		{"limit", "25", "", PageRequest{Limit: 25}, nil},
+		{"limit above the maximum", "500", "", PageRequest{Limit: 100}, nil},
+		{"zero limit", "0", "", PageRequest{}, ErrInvalidLimit},
+		{"negative limit", "-1", "", PageRequest{}, ErrInvalidLimit},
+		{"non-numeric limit", "ten", "", PageRequest{}, ErrInvalidLimit},
+		{"cursor", "", cursor, PageRequest{Limit: 10, LastKey: cursor}, nil},
+		{"cursor not base64", "", "not a cursor!", PageRequest{}, ErrInvalidLastKey},
+		{"cursor too long", "", strings.Repeat("A", maxLastKeyLength+1), PageRequest{}, ErrInvalidLastKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePageRequest(tt.limit, tt.lastKey, DefaultPageSize, MaxPageSize)
+			if err != tt.wantErr {
+				t.Fatalf("ParsePageRequest() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePageRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		defaultSize int
+		maxSize     int
+		query       string
+		wantStatus  int
+		wantLimit   int
+	}{
+		{"default page", 0, 0, "", http.StatusOK, DefaultPageSize},
+		{"requested limit", 0, 0, "?limit=5", http.StatusOK, 5},
+		{"default maximum", 0, 0, "?limit=500", http.StatusOK, MaxPageSize},
+		{"configured default page", 20, 50, "", http.StatusOK, 20},
+		{"configured maximum", 20, 50, "?limit=80", http.StatusOK, 50},
+		{"limit within configured maximum", 20, 50, "?limit=30", http.StatusOK, 30},
+		{"configured maximum below default size", 0, 5, "", http.StatusOK, 5},
+		{"invalid limit", 0, 0, "?limit=abc", http.StatusBadRequest, 0},
+		{"invalid cursor", 0, 0, "?last_key=%25%25", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var limit int
+			router := gin.New()
+			router.GET("/", Pagination(tt.defaultSize, tt.maxSize), func(c *gin.Context) {
+				limit = GetPageRequest(c).Limit
+				c.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if limit != tt.wantLimit {
+				t.Errorf("limit = %d, want %d", limit, tt.wantLimit)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	enabled := config.SecurityConfig{
+		Enabled:               true,
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		ContentSecurityPolicy: "default-src 'none'",
+	}
+
+	tests := []struct {
+		name         string
+		cfg          config.SecurityConfig
+		proto        string
+		wantStatus   int
+		wantHeaders  map[string]string
+		wantLocation string
+	}{
+		{
+			name:       "headers set",
+			cfg:        enabled,
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Content-Security-Policy":   "default-src 'none'",
+			},
+		},
+		{
+			name:       "custom frame options without HSTS or CSP",
+			cfg:        config.SecurityConfig{Enabled: true, FrameOptions: "SAMEORIGIN"},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Strict-Transport-Security": "",
+				"X-Frame-Options":           "SAMEORIGIN",
+				"Content-Security-Policy":   "",
+			},
+		},
+		{
+			name:        "disabled",
+			cfg:         config.SecurityConfig{HSTSMaxAge: time.Hour, RedirectHTTPS: true},
+			proto:       "http",
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Strict-Transport-Security": "", "X-Content-Type-Options": ""},
+		},
+		{
+			name:         "plain HTTP redirected",
+			cfg:          config.SecurityConfig{Enabled: true, RedirectHTTPS: true},
+			proto:        "http",
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://api.example.com/transactions?limit=5",
+		},
+		{
+			name:       "HTTPS not redirected",
+			cfg:        config.SecurityConfig{Enabled: true, RedirectHTTPS: true},
+			proto:      "https",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "plain HTTP allowed without redirect",
+			cfg:        enabled,
+			proto:      "http",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(SecureHeaders(tt.cfg))
+			router.GET("/transactions", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://api.example.com/transactions?limit=5", nil)
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+			for name, want := range tt.wantHeaders {
+				if got := rec.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
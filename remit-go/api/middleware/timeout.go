@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeout gives each request a context deadline. Handlers that are
+// still running when it passes see a cancelled context, whatever they write
+// afterwards is dropped, and the client gets a 504. streamedRoutes names the
+// routes that stream their response, as "METHOD /registered/path"; a request
+// to one of them asking for a stream (NDJSON or server-sent events) is
+// exempt. On any other route the headers change nothing. A zero timeout
+// disables it.
+func RequestTimeout(timeout time.Duration, streamedRoutes ...string) gin.HandlerFunc {
+	streamed := make(map[string]bool, len(streamedRoutes))
+	for _, route := range streamedRoutes {
+		streamed[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if timeout <= 0 || streamed[c.Request.Method+" "+c.FullPath()] && isStreaming(c) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		w := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = w
+
+		c.Next()
+
+		c.Writer = w.ResponseWriter
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !w.ResponseWriter.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+		}
+	}
+}
+
+// isStreaming reports whether the client asked for a streamed response
+func isStreaming(c *gin.Context) bool {
+	accept := c.GetHeader("Accept")
+	return strings.Contains(accept, "application/x-ndjson") || strings.Contains(accept, "text/event-stream")
+}
+
+// timeoutWriter discards the response once the request deadline has passed,
+// leaving it to RequestTimeout to answer 504
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+func (w *timeoutWriter) expired() bool {
+	return errors.Is(w.ctx.Err(), context.DeadlineExceeded)
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.expired() {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if w.expired() {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.expired() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeoutStreamingExemption(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		accept     string
+		wantStatus int
+	}{
+		{"streamed route asking for a stream", http.MethodGet, "/transactions", "application/x-ndjson", http.StatusOK},
+		{"streamed route asking for events", http.MethodGet, "/transactions", "text/event-stream", http.StatusOK},
+		{"streamed route asking for a page", http.MethodGet, "/transactions", "application/json", http.StatusGatewayTimeout},
+		{"other method on a streamed path", http.MethodPost, "/transactions", "application/x-ndjson", http.StatusGatewayTimeout},
+		{"other route asking for a stream", http.MethodGet, "/transactions/tx-1", "application/x-ndjson", http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestTimeout(20*time.Millisecond, "GET /transactions"))
+			slow := func(c *gin.Context) {
+				time.Sleep(50 * time.Millisecond)
+				c.String(http.StatusOK, "done")
+			}
+			router.GET("/transactions", slow)
+			router.POST("/transactions", slow)
+			router.GET("/transactions/:id", slow)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -1,31 +1,117 @@
 package routes
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	"github.com/remit-demo/remit-go/api/handlers"
+	"github.com/remit-demo/remit-go/api/middleware"
+	"github.com/remit-demo/remit-go/internal/config"
 )
 
-// SetupRoutes configures the API routes
-func SetupRoutes(router *gin.Engine, h *handlers.Handler) {
+// streamedRoutes are the routes that stream their response when the client
+// asks for it, exempt from the request timeout
+var streamedRoutes = []string{
+	"GET /api/v1/transactions",
+	"GET /api/v2/transactions",
+}
+
+// SetupRoutes configures the API routes. The user-facing endpoints require
+// authentication; callbacks are limited to the allowed provider addresses
+// and the exchange rate remains public.
+func SetupRoutes(router *gin.Engine, h *handlers.Handler, cfg *config.Config) error {
+	callbackIPs, err := middleware.ParseIPAllowlist(cfg.Server.CallbackAllowlist)
+	if err != nil {
+		return fmt.Errorf("callback allowlist: %w", err)
+	}
+
+	router.Use(
+		middleware.SecureHeaders(cfg.Server.Security),
+		middleware.RequestTimeout(cfg.Server.RequestTimeout, streamedRoutes...),
+	)
+
+	// Probes
+	router.GET("/healthz", h.Healthz)
+	router.GET("/readyz", h.Readyz)
+
+	auth := middleware.Auth(cfg.Auth.JWTSecret)
+	shed := middleware.LoadShed(cfg.Server.MaxInFlightInitiations)
+	paginate := middleware.Pagination(cfg.Server.DefaultPageSize, cfg.Server.MaxPageSize)
+
 	// API v1 group
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1", handlers.APIVersion(handlers.APIVersionV1))
 	{
-		// Transaction endpoints
-		v1.POST("/transactions", h.InitiateTransaction)
-		v1.GET("/transactions/:id", h.GetTransaction)
-		v1.GET("/transactions", h.ListTransactions)
+		user := v1.Group("", auth)
+		{
+			// Transaction endpoints
+			user.POST("/transactions", shed, h.InitiateTransaction)
+			user.GET("/transactions/:id", h.GetTransaction)
+			user.GET("/transactions", paginate, h.ListTransactions)
+			user.POST("/transactions/status", h.GetTransactionStatuses)
+			user.GET("/transactions/:id/eta", h.GetTransactionETA)
+			user.GET("/transactions/:id/timeline", h.GetTransactionTimeline)
+			user.GET("/transactions/:id/rate-breakdown", h.GetRateBreakdown)
+
+			// Export endpoints
+			user.POST("/transactions/export", h.StartExport)
+			user.GET("/exports/:id", h.GetExport)
+			user.GET("/exports/:id/download", h.DownloadExport)
+			user.POST("/exports/:id/cancel", h.CancelExport)
 
-		// Payment endpoints
-		v1.POST("/transactions/:id/payment", h.GeneratePaymentLink)
+			// Payment endpoints
+			user.POST("/transactions/:id/payment", h.GeneratePaymentLink)
+			user.POST("/transactions/:id/cancel-payment", h.CancelPayment)
+
+			// Limits and quote endpoints
+			user.GET("/limits", h.GetLimits)
+			user.GET("/quote", h.GetQuote)
+			user.GET("/quote/reverse", h.ReverseQuote)
+		}
 
 		// Exchange rate endpoint
 		v1.GET("/exchange-rate", h.GetExchangeRate)
+		v1.GET("/currency-pairs", h.ListCurrencyPairs)
+
+		// Admin endpoints
+		admin := v1.Group("/admin", auth, middleware.RequireRole(middleware.RoleAdmin))
+		{
+			admin.GET("/transactions/search", paginate, h.SearchTransactions)
+			admin.POST("/transactions/bulk-action", h.ApplyBulkAction)
+			admin.GET("/transactions/:id", h.AdminGetTransaction)
+			admin.POST("/transactions/:id/approve", h.ApproveTransaction)
+			admin.POST("/transactions/:id/reject", h.RejectTransaction)
+			admin.POST("/transactions/:id/recalculate", h.RecalculateTransaction)
+			admin.GET("/transactions/:id/provider-debug", h.GetProviderDebug)
+			admin.POST("/transactions/:id/redact", h.RedactTransaction)
+			admin.POST("/users/:id/redact", h.RedactUserData)
+			admin.GET("/reports/reconciliation", h.GetReconciliationReport)
+			admin.PUT("/currency-pairs/:source/:target", h.SetCurrencyPairEnabled)
+			admin.GET("/dead-letters", paginate, h.ListDeadLetters)
+			admin.POST("/dead-letters/:id/replay", h.ReplayDeadLetter)
+		}
 
 		// Callback endpoints
-		callbacks := v1.Group("/callbacks")
+		callbacks := v1.Group("/callbacks", middleware.SourceIPAllowlist(callbackIPs))
 		{
 			callbacks.POST("/payment", h.HandlePaymentCallback)
 			callbacks.POST("/transfer", h.HandleTransferCallback)
 		}
 	}
+
+	// API v2 group: same operations, stable DTOs wrapped in a { data, meta } envelope
+	v2 := router.Group("/api/v2",
+		middleware.RequireFeature(cfg.Features, config.FeatureV2API),
+		handlers.APIVersion(handlers.APIVersionV2),
+		auth,
+	)
+	{
+		v2.POST("/transactions", shed, h.InitiateTransaction)
+		v2.GET("/transactions/:id", h.GetTransaction)
+		v2.GET("/transactions", paginate, h.ListTransactions)
+		v2.POST("/transactions/status", h.GetTransactionStatuses)
+		v2.GET("/transactions/:id/eta", h.GetTransactionETA)
+		v2.POST("/transactions/:id/payment", h.GeneratePaymentLink)
+	}
+
+	return nil
 }
@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+func TestV2BehindFeatureFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantStatus int
+	}{
+		{"enabled", true, http.StatusUnauthorized}, // reaches authentication
+		{"disabled", false, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			cfg := &config.Config{Features: map[string]bool{config.FeatureV2API: tt.enabled}}
+			cfg.Auth.JWTSecret = "0123456789abcdef0123456789abcdef"
+			if err := SetupRoutes(router, nil, cfg); err != nil {
+				t.Fatalf("SetupRoutes() = %v", err)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/transactions/TXN-1", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestStreamedRoutesAreRegistered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	cfg := &config.Config{Features: map[string]bool{config.FeatureV2API: true}}
+	if err := SetupRoutes(router, nil, cfg); err != nil {
+		t.Fatalf("SetupRoutes() = %v", err)
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, route := range streamedRoutes {
+		if !registered[route] {
+			t.Errorf("streamed route %q is not registered", route)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
@@ -14,9 +15,13 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/gin-gonic/gin"
 	"github.com/remit-demo/remit-go/api/handlers"
+	"github.com/remit-demo/remit-go/api/middleware"
 	"github.com/remit-demo/remit-go/api/routes"
+	"github.com/remit-demo/remit-go/internal/compliance"
 	"github.com/remit-demo/remit-go/internal/config"
+	"github.com/remit-demo/remit-go/internal/domain"
 	"github.com/remit-demo/remit-go/internal/integration"
+	"github.com/remit-demo/remit-go/internal/metrics"
 	"github.com/remit-demo/remit-go/internal/repository"
 	"github.com/remit-demo/remit-go/internal/service"
 )
@@ -42,38 +47,155 @@ func main() {
 		log.Fatalf("unable to load AWS SDK config: %v", err)
 	}
 
-	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+	dynamoClient := dynamodb.NewFromConfig(awsCfg, repository.ClientLimits{
+		OperationTimeout: cfg.Database.DynamoDB.OperationTimeout,
+		MaxAttempts:      cfg.Database.DynamoDB.Retry.MaxAttempts,
+		MaxBackoff:       cfg.Database.DynamoDB.Retry.MaxInterval,
+	}.Apply)
 
 	// Initialize repository
 	repo := repository.NewDynamoDBRepository(
 		dynamoClient,
 		cfg.Database.DynamoDB.Tables.Transaction,
 		cfg.Database.DynamoDB.Tables.Payment,
+		cfg.Database.DynamoDB.Tables.Rate,
+		cfg.Database.DynamoDB.Tables.Idempotency,
+		cfg.Database.DynamoDB.Tables.DeadLetter,
 	)
 
+	// Refuse to start on a configuration that cannot work, such as mock
+	// integration clients in production or no currency pair
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+	for _, client := range cfg.Clients() {
+		if client.Mock() {
+			log.Printf("WARNING: %s client is a MOCK, no calls reach the provider (environment %q)", client.Name, cfg.Environment)
+		}
+	}
+
 	// Initialize external service clients
 	upiClient := integration.NewUPIClient(cfg.UPI)
 	adBankClient := integration.NewADBankClient(cfg.ADBank)
 	wiseClient := integration.NewWiseClient(cfg.Wise)
 
+	// Payment methods offered besides UPI
+	paymentProviders := map[domain.PaymentMethod]integration.PaymentProvider{}
+	if cfg.Payments.Card.Enabled {
+		paymentProviders[domain.PaymentMethodCard] = integration.NewCardClient(cfg.Payments.Card)
+	}
+	if cfg.Payments.BankTransfer.Enabled {
+		paymentProviders[domain.PaymentMethodBankTransfer] = integration.NewBankTransferClient(cfg.Payments.BankTransfer)
+	}
+
+	// Reminders go to the log until a messaging provider is integrated
+	notifier := integration.NewLogNotifier()
+
+	// Initialize compliance screening
+	complianceChecker := compliance.NewDenylist(cfg.Compliance)
+
+	// Configure transaction ID format
+	if err := domain.ConfigureIDs(cfg.IDs.Prefix, domain.IDScheme(cfg.IDs.Scheme)); err != nil {
+		log.Fatalf("invalid ID config: %v", err)
+	}
+
+	// Validated above, so the default pair exists
+	defaultPair, _ := cfg.DefaultCurrencyPair()
+
 	// Initialize service
-	svc := service.NewRemittanceService(repo, upiClient, adBankClient, wiseClient, &service.Config{
-		MinAmount:    cfg.Limits.MinAmount,
-		MaxAmount:    cfg.Limits.MaxAmount,
-		DailyLimit:   cfg.Limits.DailyLimit,
-		BaseFee:      cfg.Fees.Base.Amount,
-		VariableFee:  cfg.Fees.Percentage.Rate,
-		RateValidity: cfg.CurrencyPairs[0].MinRateValidity,
+	svc := service.NewRemittanceService(repo, upiClient, paymentProviders, adBankClient, wiseClient, complianceChecker, notifier, &service.Config{
+		MinAmount:               cfg.Limits.MinAmount,
+		MaxAmount:               cfg.Limits.MaxAmount,
+		DailyLimit:              cfg.Limits.DailyLimit,
+		MaxOpenTransactions:     cfg.Limits.MaxOpenTransactions,
+		DuplicateWindow:         cfg.Limits.DuplicateWindow,
+		RecentRecipientWindow:   cfg.Limits.RecentRecipientWindow,
+		ExportTTL:               cfg.Server.ExportTTL,
+		MaxActiveExports:        cfg.Server.MaxActiveExports,
+		MaxExportRows:           cfg.Server.MaxExportRows,
+		AmountPrecision:         cfg.Limits.AmountPrecision,
+		BaseFee:                 cfg.Fees.Base.Amount,
+		VariableFee:             cfg.Fees.Percentage.Rate,
+		FeeTiers:                cfg.Fees.Tiers,
+		FeeModel:                feeModel(cfg.Fees.Model),
+		RateValidity:            defaultPair.MinRateValidity,
+		PromoCodes:              cfg.Fees.PromoCodes,
+		PaymentLinkValidity:     cfg.UPI.LinkValidity,
+		PayeeVPA:                cfg.UPI.VPA,
+		PaymentLinkStatuses:     paymentLinkStatuses(cfg.Payments.LinkStatuses),
+		PaymentCurrencies:       paymentCurrencies(cfg.Payments.SourceCurrencies),
+		PaymentAmountTolerance:  cfg.UPI.AmountTolerance,
+		PaymentReminderLead:     cfg.UPI.Reminder.Lead,
+		ReviewThreshold:         cfg.Thresholds.HighValue,
+		TransferRetry:           cfg.Wise.Retry,
+		SettlementTolerance:     cfg.Wise.SettlementTolerance,
+		MaxConcurrentTransfers:  cfg.Wise.MaxConcurrentTransfers,
+		WiseCorridors:           cfg.Wise.Corridors,
+		TransferPollConcurrency: cfg.Wise.Poller.Concurrency,
+		TransferPollLookback:    cfg.Wise.Poller.Lookback,
+		ProcessingTimeout:       cfg.Wise.Poller.ProcessingTimeout,
+		RetryStuckTransfers:     retryStuckTransfers(cfg.Wise.Poller.ProcessingRecovery),
+		CurrencyPairs:           cfg.CurrencyPairs,
+		DefaultPair:             defaultPair.Key(),
+		Tiers:                   cfg.Tiers,
 	})
 
+	// Hold off serving until the dependencies every request needs are up
+	if cfg.Server.StartupTimeout > 0 {
+		waitCtx, cancelWait := context.WithTimeout(context.Background(), cfg.Server.StartupTimeout)
+		err := svc.WaitForDependencies(waitCtx, 0)
+		cancelWait()
+		if err != nil {
+			log.Fatalf("startup aborted: %v", err)
+		}
+	}
+
+	// Report missing GSIs up front rather than on the first history request
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := repo.CheckIndexes(checkCtx); err != nil {
+		log.Printf("WARNING: DynamoDB index check failed, transaction listing and search will not work: %v", err)
+	}
+	cancelCheck()
+
+	// Poll Wise for transfers whose callback never arrived
+	pollCtx, stopPoller := context.WithCancel(context.Background())
+	if cfg.Wise.Poller.Interval > 0 {
+		go svc.RunTransferPoller(pollCtx, cfg.Wise.Poller.Interval)
+	}
+
+	// Pick up transfers a previous process left mid-flight
+	if cfg.Wise.Poller.ResumeOnStartup {
+		go func() {
+			if err := svc.ResumeInterruptedTransactions(pollCtx); err != nil && pollCtx.Err() == nil {
+				log.Printf("resuming interrupted transactions failed: %v", err)
+			}
+		}()
+	}
+
+	// Remind senders before their payment links expire
+	if cfg.UPI.Reminder.Lead > 0 && cfg.UPI.Reminder.Interval > 0 {
+		go svc.RunPaymentReminders(pollCtx, cfg.UPI.Reminder.Interval)
+	}
+
 	// Initialize HTTP handler
-	handler := handlers.NewHandler(svc)
+	handler := handlers.NewHandler(svc, &handlers.Config{
+		VerboseErrors:  cfg.Server.VerboseErrors,
+		MaxStreamItems: cfg.Server.MaxStreamItems,
+		RateDecimals:   cfg.Server.RateDecimals,
+	})
 
-	// Set up Gin router
-	router := gin.Default()
+	// Set up Gin router, tagging each request with an ID and logging a sample
+	// of successful requests
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.RequestLogger(cfg.Server.LogSampleRate), gin.Recovery())
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("invalid trusted proxies: %v", err)
+	}
 
 	// Configure routes
-	routes.SetupRoutes(router, handler)
+	if err := routes.SetupRoutes(router, handler, cfg); err != nil {
+		log.Fatalf("invalid route configuration: %v", err)
+	}
 
 	// Start server
 	srv := &http.Server{
@@ -88,21 +210,94 @@ func main() {
 		}
 	}()
 
+	// Serve Prometheus metrics on a separate port
+	var metricsSrv *http.Server
+	if cfg.Monitoring.MetricsPort != "" {
+		metricsSrv = &http.Server{
+			Addr:    ":" + cfg.Monitoring.MetricsPort,
+			Handler: metrics.Handler(),
+		}
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("metrics listen: %s\n", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopPoller()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
+	if metricsSrv != nil {
+		metricsSrv.Shutdown(ctx)
+	}
 
 	log.Println("Server exiting")
 }
 
+// paymentLinkStatuses converts the configured status names, rejecting any
+// that are not transaction statuses
+func paymentLinkStatuses(names []string) []domain.TransactionStatus {
+	statuses := make([]domain.TransactionStatus, 0, len(names))
+	for _, name := range names {
+		status := domain.TransactionStatus(name)
+		if !slices.Contains(domain.Statuses, status) {
+			log.Fatalf("invalid payment link status %q", name)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// paymentCurrencies validates the payment methods named in the source
+// currency restrictions
+func paymentCurrencies(byMethod map[string][]string) map[domain.PaymentMethod][]string {
+	currencies := make(map[domain.PaymentMethod][]string, len(byMethod))
+	for name, allowed := range byMethod {
+		method := domain.PaymentMethod(name)
+		if !method.Valid() {
+			log.Fatalf("invalid payment method %q in payments.source_currencies", name)
+		}
+		currencies[method] = allowed
+	}
+	return currencies
+}
+
+// feeModel validates the configured fee model; empty is exclusive
+func feeModel(name string) domain.FeeModel {
+	switch model := domain.FeeModel(name); model {
+	case "", domain.FeeModelExclusive:
+		return domain.FeeModelExclusive
+	case domain.FeeModelInclusive:
+		return model
+	default:
+		log.Fatalf("invalid fee model %q", name)
+		return ""
+	}
+}
+
+// retryStuckTransfers reports whether the processing recovery setting asks
+// for stuck transfers to be retried rather than failed
+func retryStuckTransfers(recovery string) bool {
+	switch recovery {
+	case "", "fail":
+		return false
+	case "retry":
+		return true
+	default:
+		log.Fatalf("invalid processing recovery %q", recovery)
+		return false
+	}
+}
+
 func loadConfig() *config.Config {
 	// Implementation depends on your configuration management choice
 	// You could use Viper, environment variables, or other methods
@@ -120,6 +315,9 @@ func loadConfig() *config.Config {
 				},
 			},
 		},
+		Auth: config.AuthConfig{
+			JWTSecret: os.Getenv("JWT_SECRET"),
+		},
 		// ... other configuration values loaded from config files
 	}
 }
@@ -0,0 +1,14 @@
+package compliance
+
+import (
+	"context"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// Checker screens recipients before money is sent to them. Implementations
+// may consult a local denylist or an external screening service.
+type Checker interface {
+	// IsBlocked reports whether payments to the recipient must be refused
+	IsBlocked(ctx context.Context, recipient *domain.RecipientDetails) (bool, error)
+}
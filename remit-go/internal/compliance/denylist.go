@@ -0,0 +1,48 @@
+package compliance
+
+import (
+	"context"
+	"strings"
+
+	"github.com/remit-demo/remit-go/internal/config"
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// denylist is an in-memory Checker backed by configured names and accounts
+type denylist struct {
+	names    map[string]struct{}
+	accounts map[string]struct{}
+}
+
+// NewDenylist creates a Checker that blocks the configured recipients. Names
+// match case-insensitively; accounts must match exactly.
+func NewDenylist(cfg config.ComplianceConfig) Checker {
+	d := &denylist{
+		names:    make(map[string]struct{}, len(cfg.BlockedNames)),
+		accounts: make(map[string]struct{}, len(cfg.BlockedAccounts)),
+	}
+	for _, name := range cfg.BlockedNames {
+		d.names[normalizeName(name)] = struct{}{}
+	}
+	for _, account := range cfg.BlockedAccounts {
+		d.accounts[account] = struct{}{}
+	}
+	return d
+}
+
+// IsBlocked checks the recipient's name and bank account against the denylist
+func (d *denylist) IsBlocked(ctx context.Context, recipient *domain.RecipientDetails) (bool, error) {
+	if _, ok := d.names[normalizeName(recipient.Name)]; ok {
+		return true, nil
+	}
+	if _, ok := d.accounts[recipient.BankAccount]; ok {
+		return true, nil
+	}
+	return false, nil
+}
+
+// normalizeName lowercases and collapses whitespace so formatting differences
+// do not defeat the match
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
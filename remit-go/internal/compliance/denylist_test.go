@@ -0,0 +1,41 @@
+package compliance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/remit-demo/remit-go/internal/config"
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+func TestDenylist(t *testing.T) {
+	checker := NewDenylist(config.ComplianceConfig{
+		BlockedNames:    []string{"John  Smith"},
+		BlockedAccounts: []string{"99999999"},
+	})
+
+	tests := []struct {
+		name      string
+		recipient domain.RecipientDetails
+		want      bool
+	}{
+		{"clear", domain.RecipientDetails{Name: "Jane Doe", BankAccount: "12345678"}, false},
+		{"blocked name", domain.RecipientDetails{Name: "John Smith", BankAccount: "12345678"}, true},
+		{"blocked name in other case and spacing", domain.RecipientDetails{Name: "  JOHN\tsmith ", BankAccount: "12345678"}, true},
+		{"name containing a blocked name", domain.RecipientDetails{Name: "John Smithson", BankAccount: "12345678"}, false},
+		{"blocked account", domain.RecipientDetails{Name: "Jane Doe", BankAccount: "99999999"}, true},
+		{"account matched exactly", domain.RecipientDetails{Name: "Jane Doe", BankAccount: "099999999"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checker.IsBlocked(context.Background(), &tt.recipient)
+			if err != nil {
+				t.Fatalf("IsBlocked() = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsBlocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,20 +4,136 @@ import "time"
 
 // Config represents the application configuration
 type Config struct {
-	Server        ServerConfig         `yaml:"server"`
-	Database      DatabaseConfig       `yaml:"database"`
-	UPI           UPIConfig            `yaml:"upi"`
-	ADBank        ADBankConfig         `yaml:"ad_bank"`
-	Wise          WiseConfig           `yaml:"wise"`
-	Limits        LimitsConfig         `yaml:"limits"`
-	Fees          FeesConfig           `yaml:"fees"`
-	CurrencyPairs []CurrencyPairConfig `yaml:"currency_pairs"`
+	Environment   Environment           `yaml:"environment"` // dev (default), staging or prod
+	Server        ServerConfig          `yaml:"server"`
+	Database      DatabaseConfig        `yaml:"database"`
+	UPI           UPIConfig             `yaml:"upi"`
+	ADBank        ADBankConfig          `yaml:"ad_bank"`
+	Wise          WiseConfig            `yaml:"wise"`
+	Limits        LimitsConfig          `yaml:"limits"`
+	Fees          FeesConfig            `yaml:"fees"`
+	CurrencyPairs []CurrencyPairConfig  `yaml:"currency_pairs"`
+	DefaultPair   string                `yaml:"default_currency_pair"` // "SOURCE/TARGET" quoted when none is requested
+	Monitoring    MonitoringConfig      `yaml:"monitoring"`
+	Auth          AuthConfig            `yaml:"auth"`
+	Tiers         map[string]TierConfig `yaml:"tiers"`
+	Features      FeaturesConfig        `yaml:"features"`
+	Compliance    ComplianceConfig      `yaml:"compliance"`
+	Thresholds    ThresholdsConfig      `yaml:"thresholds"`
+	IDs           IDConfig              `yaml:"ids"`
+	Payments      PaymentsConfig        `yaml:"payments"`
+}
+
+// PaymentsConfig holds the payment methods offered besides UPI
+type PaymentsConfig struct {
+	// LinkStatuses are the transaction statuses a payment link may be
+	// generated from. Defaults to INITIATED and PAYMENT_PENDING.
+	LinkStatuses []string `yaml:"link_statuses"`
+
+	// SourceCurrencies lists, per payment method (UPI, CARD,
+	// BANK_TRANSFER), the source currencies it can collect. Methods not
+	// listed accept any currency.
+	SourceCurrencies map[string][]string `yaml:"source_currencies"`
+
+	Card         CardConfig         `yaml:"card"`
+	BankTransfer BankTransferConfig `yaml:"bank_transfer"`
+}
+
+// CardConfig holds card payment gateway configuration
+type CardConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Mode     ClientMode    `yaml:"mode"` // mock (default) or live
+	Endpoint string        `yaml:"endpoint"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// BankTransferConfig holds the collection account senders transfer into
+type BankTransferConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	AccountNumber string `yaml:"account_number"`
+	IFSC          string `yaml:"ifsc"`
+}
+
+// IDConfig holds the transaction ID format
+type IDConfig struct {
+	Prefix string `yaml:"prefix"` // defaults to "TXN-"
+	Scheme string `yaml:"scheme"` // timestamp (default), uuid or ulid
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
 	Port    string        `yaml:"port"`
 	Timeout TimeoutConfig `yaml:"timeout"`
+
+	// RequestTimeout is the deadline given to each request's context; past
+	// it the client gets a 504. Streaming requests are exempt. Zero disables.
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+
+	// VerboseErrors returns the parser's detail for malformed request
+	// bodies instead of a generic message. Field validation errors are
+	// always listed. Development only.
+	VerboseErrors bool `yaml:"verbose_errors"`
+
+	// MaxInFlightInitiations sheds new transactions with a 503 once this many
+	// are being initiated concurrently. Zero disables shedding.
+	MaxInFlightInitiations int `yaml:"max_in_flight_initiations"`
+
+	Security SecurityConfig `yaml:"security"`
+
+	// TrustedProxies lists the proxy addresses or CIDRs whose
+	// X-Forwarded-For header is believed when resolving the client IP.
+	// Empty trusts no proxy, so the connection's remote address is used.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// StartupTimeout bounds the wait for DynamoDB and the rate provider
+	// before serving; the process exits if they are not up by then. Zero
+	// serves without waiting.
+	StartupTimeout time.Duration `yaml:"startup_timeout"`
+
+	// MaxStreamItems caps how many transactions one streamed listing
+	// returns across all its pages. Zero means no limit.
+	MaxStreamItems int `yaml:"max_stream_items"`
+
+	// DefaultPageSize is the page size of listings that ask for none, and
+	// MaxPageSize the largest a listing may ask for; larger limits are
+	// lowered to it. Zero selects 10 and 100.
+	DefaultPageSize int `yaml:"default_page_size"`
+	MaxPageSize     int `yaml:"max_page_size"`
+
+	// CallbackAllowlist lists the provider addresses or CIDRs allowed to
+	// call the callback endpoints. Empty allows any address.
+	CallbackAllowlist []string `yaml:"callback_allowlist"`
+
+	// ExportTTL is how long a finished transaction export is kept for
+	// download. Zero keeps it for an hour.
+	ExportTTL time.Duration `yaml:"export_ttl"`
+
+	// MaxActiveExports caps the exports one user may have pending or
+	// running at once, and MaxExportRows the transactions one export may
+	// hold; a larger export fails. Zero selects 2 and 100000.
+	MaxActiveExports int `yaml:"max_active_exports"`
+	MaxExportRows    int `yaml:"max_export_rows"`
+
+	// RateDecimals is how many decimal places exchange rates are shown to
+	// clients with. Rates are stored and applied at full precision. Zero
+	// shows them unrounded.
+	RateDecimals int `yaml:"rate_decimals"`
+
+	// LogSampleRate is the fraction of successful requests that are logged,
+	// e.g. 0.1; error responses are always logged. Zero logs every request.
+	LogSampleRate float64 `yaml:"log_sample_rate"`
+}
+
+// SecurityConfig holds the security response headers and HTTPS redirect
+type SecurityConfig struct {
+	Enabled               bool          `yaml:"enabled"` // off for local development
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age"`
+	FrameOptions          string        `yaml:"frame_options"` // defaults to DENY
+	ContentSecurityPolicy string        `yaml:"content_security_policy"`
+
+	// RedirectHTTPS redirects requests forwarded as plain HTTP by a proxy,
+	// detected through X-Forwarded-Proto
+	RedirectHTTPS bool `yaml:"redirect_https"`
 }
 
 // TimeoutConfig holds timeout settings
@@ -27,6 +143,43 @@ type TimeoutConfig struct {
 	Idle  time.Duration `yaml:"idle"`
 }
 
+// MonitoringConfig holds health check and metrics settings
+type MonitoringConfig struct {
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"`
+	MetricsPort         string        `yaml:"metrics_port"`
+}
+
+// AuthConfig holds API authentication settings
+type AuthConfig struct {
+	JWTSecret string `yaml:"jwt_secret"` // HS256 signing key for bearer tokens
+}
+
+// TierConfig holds the restrictions for a user tier. Users whose tier is not
+// configured may use every enabled currency pair.
+type TierConfig struct {
+	AllowedPairs []string `yaml:"allowed_pairs"` // Pair keys such as "INR/CAD"
+}
+
+// ComplianceConfig holds the recipient denylist
+type ComplianceConfig struct {
+	BlockedNames    []string `yaml:"blocked_names"`    // Matched case-insensitively
+	BlockedAccounts []string `yaml:"blocked_accounts"` // Matched exactly
+}
+
+// Feature flag names
+const (
+	FeatureV2API = "v2_api"
+)
+
+// FeaturesConfig maps feature names to whether they are enabled. Features
+// that are not listed are disabled, so new endpoints ship dark by default.
+type FeaturesConfig map[string]bool
+
+// Enabled reports whether the named feature is switched on
+func (f FeaturesConfig) Enabled(name string) bool {
+	return f[name]
+}
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
 	DynamoDB DynamoDBConfig `yaml:"dynamodb"`
@@ -37,37 +190,136 @@ type DynamoDBConfig struct {
 	Endpoint string       `yaml:"endpoint"`
 	Region   string       `yaml:"region"`
 	Tables   TablesConfig `yaml:"tables"`
+
+	// OperationTimeout caps each DynamoDB operation, SDK retries included,
+	// even when the caller's context allows longer. Zero disables.
+	OperationTimeout time.Duration `yaml:"operation_timeout"`
+
+	// Retry bounds the SDK's retries of throttled requests. Only
+	// max_attempts and max_interval apply; zero keeps the SDK default.
+	Retry RetryConfig `yaml:"retry"`
 }
 
 // TablesConfig holds DynamoDB table names
 type TablesConfig struct {
 	Transaction string `yaml:"transaction"`
 	Payment     string `yaml:"payment"`
+	Rate        string `yaml:"rate"`
+	Idempotency string `yaml:"idempotency"`
+	DeadLetter  string `yaml:"dead_letter"`
 }
 
 // UPIConfig holds UPI payment gateway configuration
 type UPIConfig struct {
 	Provider string        `yaml:"provider"`
+	Mode     ClientMode    `yaml:"mode"` // mock (default) or live
 	Endpoint string        `yaml:"endpoint"`
 	Timeout  time.Duration `yaml:"timeout"`
 	Retry    RetryConfig   `yaml:"retry"`
 	VPA      string        `yaml:"vpa"` // Virtual Payment Address for receiving payments
+
+	// LinkValidity is how long a generated payment link stays usable. Zero
+	// means links never expire.
+	LinkValidity time.Duration `yaml:"link_validity"`
+
+	// AmountTolerance is the largest difference between the paid and expected
+	// amount, in the source currency, still accepted as a full payment
+	AmountTolerance float64 `yaml:"amount_tolerance"`
+
+	// Reminder notifies senders before their payment link expires
+	Reminder ReminderConfig `yaml:"reminder"`
+
+	// LinkTimeout overrides Timeout for payment link creation. Zero uses
+	// Timeout.
+	LinkTimeout time.Duration `yaml:"link_timeout"`
+}
+
+// ReminderConfig holds the payment reminder sweep settings
+type ReminderConfig struct {
+	// Lead is how long before a link expires the sender is reminded. Zero
+	// disables reminders.
+	Lead time.Duration `yaml:"lead"`
+
+	// Interval is how often pending payments are checked
+	Interval time.Duration `yaml:"interval"`
 }
 
 // ADBankConfig holds AD Bank API configuration
 type ADBankConfig struct {
+	Mode                ClientMode    `yaml:"mode"` // mock (default) or live
 	Endpoint            string        `yaml:"endpoint"`
 	Timeout             time.Duration `yaml:"timeout"`
 	RateRefreshInterval time.Duration `yaml:"rate_refresh_interval"`
 	Retry               RetryConfig   `yaml:"retry"`
+
+	// AccountValidationTTL is how long an account found valid is trusted
+	// without asking AD Bank again. Zero validates every time.
+	AccountValidationTTL time.Duration `yaml:"account_validation_ttl"`
+
+	// RateTimeout and ValidationTimeout override Timeout for exchange rate
+	// fetches and account validations. Zero uses Timeout.
+	RateTimeout       time.Duration `yaml:"rate_timeout"`
+	ValidationTimeout time.Duration `yaml:"validation_timeout"`
 }
 
 // WiseConfig holds Wise API configuration
 type WiseConfig struct {
+	Mode      ClientMode    `yaml:"mode"` // mock (default) or live
 	Endpoint  string        `yaml:"endpoint"`
 	Timeout   time.Duration `yaml:"timeout"`
 	ProfileID string        `yaml:"profile_id"`
 	Retry     RetryConfig   `yaml:"retry"`
+
+	// TransferTimeout and StatusTimeout override Timeout for transfer
+	// creation and transfer status checks. Zero uses Timeout.
+	TransferTimeout time.Duration `yaml:"transfer_timeout"`
+	StatusTimeout   time.Duration `yaml:"status_timeout"`
+
+	// MaxConcurrentTransfers caps CreateTransfer calls in flight across the
+	// whole process; further calls wait for a slot. Zero means no limit.
+	MaxConcurrentTransfers int `yaml:"max_concurrent_transfers"`
+
+	// Corridors lists the "SOURCE/TARGET" pairs Wise transfers, checked
+	// before a transfer is created. Empty allows every configured pair.
+	Corridors []string `yaml:"corridors"`
+
+	// TerminalErrors lists Wise error codes that are never retried. Defaults
+	// to insufficient funds and recipient/account/compliance rejections.
+	TerminalErrors []string `yaml:"terminal_errors"`
+
+	Poller PollerConfig `yaml:"poller"`
+
+	SettlementTolerance SettlementToleranceConfig `yaml:"settlement_tolerance"`
+}
+
+// SettlementToleranceConfig bounds the difference between the amount Wise
+// delivers and the quoted target amount that is accepted without review.
+// The larger of the two applies; both zero accepts any difference.
+type SettlementToleranceConfig struct {
+	Amount  float64 `yaml:"amount"`  // in the target currency
+	Percent float64 `yaml:"percent"` // fraction of the target amount, e.g. 0.005
+}
+
+// PollerConfig holds the transfer status poller settings
+type PollerConfig struct {
+	Interval    time.Duration `yaml:"interval"`    // zero disables the poller
+	Concurrency int           `yaml:"concurrency"` // parallel Wise calls
+	Lookback    time.Duration `yaml:"lookback"`    // only transfers created this recently are polled
+
+	// ProcessingTimeout is how long a transaction may stay PROCESSING with
+	// no transfer ID, e.g. after a crash during transfer creation, before it
+	// is recovered. Zero disables recovery.
+	ProcessingTimeout time.Duration `yaml:"processing_timeout"`
+
+	// ProcessingRecovery is what happens to such a transaction: "fail"
+	// (default) or "retry" the transfer. Retrying risks a duplicate transfer
+	// if Wise did create the first one.
+	ProcessingRecovery string `yaml:"processing_recovery"`
+
+	// ResumeOnStartup drives forward, once at startup, the transactions a
+	// previous process left mid-flight: paid but never transferred, or
+	// PROCESSING. Off by default.
+	ResumeOnStartup bool `yaml:"resume_on_startup"`
 }
 
 // RetryConfig holds retry settings
@@ -75,6 +327,11 @@ type RetryConfig struct {
 	MaxAttempts     int           `yaml:"max_attempts"`
 	InitialInterval time.Duration `yaml:"initial_interval"`
 	MaxInterval     time.Duration `yaml:"max_interval"`
+
+	// DisableJitter waits the full backoff interval instead of a random
+	// time up to it. Jitter keeps concurrent retries from firing together
+	// after an outage; disable it only for deterministic tests.
+	DisableJitter bool `yaml:"disable_jitter"`
 }
 
 // LimitsConfig holds transaction limit settings
@@ -82,6 +339,25 @@ type LimitsConfig struct {
 	MinAmount  float64 `yaml:"min_amount"`
 	MaxAmount  float64 `yaml:"max_amount"`
 	DailyLimit float64 `yaml:"daily_limit"`
+
+	// MaxOpenTransactions caps the non-terminal transactions a user may hold
+	// at once. Zero means unlimited.
+	MaxOpenTransactions int `yaml:"max_open_transactions"`
+
+	// DuplicateWindow treats a request without an idempotency key that
+	// matches the user's transaction created this recently, for the same
+	// amount and recipient, as a double submit and returns that
+	// transaction. Zero disables the check.
+	DuplicateWindow time.Duration `yaml:"duplicate_window"`
+
+	// RecentRecipientWindow warns, without refusing, on a transaction to a
+	// recipient the user already sent to this recently. Zero disables the
+	// warning.
+	RecentRecipientWindow time.Duration `yaml:"recent_recipient_window"`
+
+	// AmountPrecision overrides, per currency, how many decimal places an
+	// input amount may have. Currencies not listed use their minor units.
+	AmountPrecision map[string]int `yaml:"amount_precision"`
 }
 
 // FeesConfig holds fee structure configuration
@@ -89,6 +365,17 @@ type FeesConfig struct {
 	Base       FeeConfig `yaml:"base"`
 	Percentage FeeConfig `yaml:"percentage"`
 	Wise       FeeConfig `yaml:"wise"`
+
+	// Tiers replace the percentage fee with a rate and flat fee chosen by
+	// amount, in ascending order of UpTo. Empty uses Percentage.
+	Tiers []FeeTierConfig `yaml:"tiers"`
+
+	PromoCodes []PromoCodeConfig `yaml:"promo_codes"`
+
+	// Model is "exclusive" (default), charging fees on top of the source
+	// amount, or "inclusive", taking them out of it. It sets the amount the
+	// payment link asks for.
+	Model string `yaml:"model"`
 }
 
 // FeeConfig holds fee settings
@@ -100,11 +387,76 @@ type FeeConfig struct {
 	Max    float64 `yaml:"max"`
 }
 
+// FeeTierConfig is the variable fee for amounts up to and including UpTo.
+// UpTo zero means no upper bound and belongs on the last tier.
+type FeeTierConfig struct {
+	UpTo float64 `yaml:"up_to"`
+	Rate float64 `yaml:"rate"`
+	Flat float64 `yaml:"flat"`
+}
+
+// PromoCodeConfig holds the rules for a fee promo code
+type PromoCodeConfig struct {
+	Code           string    `yaml:"code"`
+	Discount       float64   `yaml:"discount"` // Fraction of the total fee waived, 1 = fee free
+	ExpiresAt      time.Time `yaml:"expires_at"`
+	MaxUsesPerUser int       `yaml:"max_uses_per_user"` // 0 = unlimited
+}
+
+// ThresholdsConfig holds the amounts that trigger additional checks
+type ThresholdsConfig struct {
+	HighValue  float64 `yaml:"high_value"` // Above this, transactions are held for review before payment
+	Suspicious float64 `yaml:"suspicious"`
+}
+
 // CurrencyPairConfig holds currency pair settings
 type CurrencyPairConfig struct {
-	Source          string        `yaml:"source"`
-	Target          string        `yaml:"target"`
-	Enabled         bool          `yaml:"enabled"`
-	Margin          float64       `yaml:"margin"`
-	MinRateValidity time.Duration `yaml:"min_rate_validity"`
+	Source          string               `yaml:"source"`
+	Target          string               `yaml:"target"`
+	Enabled         bool                 `yaml:"enabled"`
+	Margin          float64              `yaml:"margin"`
+	MinRateValidity time.Duration        `yaml:"min_rate_validity"`
+	DeliveryWindow  DeliveryWindowConfig `yaml:"delivery_window"`
+
+	// MaxRateAgeForTransfer is how old a locked rate may be when the transfer
+	// is sent. Older rates are re-quoted if the current rate is within
+	// RequoteTolerance (a fraction of the locked rate), otherwise the
+	// transaction fails with reason rate_stale. Zero disables the check.
+	MaxRateAgeForTransfer time.Duration `yaml:"max_rate_age_for_transfer"`
+	RequoteTolerance      float64       `yaml:"requote_tolerance"`
+
+	// MaxFallbackRateAge is how old the last-known rate may be to still be
+	// used when the rate provider is down. Zero disables the fallback.
+	MaxFallbackRateAge time.Duration `yaml:"max_fallback_rate_age"`
+
+	// MinTransfer and MaxTransfer are Wise's limits for the corridor, in the
+	// source currency, applied to the amount left after fees. Zero disables
+	// either check.
+	MinTransfer float64 `yaml:"min_transfer"`
+	MaxTransfer float64 `yaml:"max_transfer"`
+
+	// TransliterateNames sends Wise a Latin spelling of recipient names
+	// written in other scripts
+	TransliterateNames bool `yaml:"transliterate_names"`
+
+	// EnforceRecipientCurrency refuses recipients whose account is known to
+	// be held in a currency other than the target currency
+	EnforceRecipientCurrency bool `yaml:"enforce_recipient_currency"`
+
+	// Fees replaces the global base fee, percentage and tiers for the
+	// corridor. Promo codes and the fee model stay global. Nil uses the
+	// global fees.
+	Fees *FeesConfig `yaml:"fees"`
+}
+
+// Key returns the "SOURCE/TARGET" identifier of the pair
+func (p CurrencyPairConfig) Key() string {
+	return p.Source + "/" + p.Target
+}
+
+// DeliveryWindowConfig holds the expected time from payment receipt to
+// funds arriving with the recipient for a corridor
+type DeliveryWindowConfig struct {
+	Min time.Duration `yaml:"min"`
+	Max time.Duration `yaml:"max"`
 }
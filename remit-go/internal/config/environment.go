@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Environment names the kind of deployment
+type Environment string
+
+const (
+	EnvironmentDev     Environment = "dev"
+	EnvironmentStaging Environment = "staging"
+	EnvironmentProd    Environment = "prod"
+)
+
+// ClientMode selects whether an integration client talks to its provider
+type ClientMode string
+
+const (
+	ClientModeMock ClientMode = "mock" // canned responses, no provider calls
+	ClientModeLive ClientMode = "live"
+)
+
+// Client describes one configured integration client
+type Client struct {
+	Name     string
+	Mode     ClientMode
+	Endpoint string
+
+	// Local clients need no provider, so have no endpoint
+	Local bool
+}
+
+// liveClients names the clients with a live implementation. The others
+// return canned responses whatever their mode says.
+var liveClients = map[string]bool{
+	"wise":          true,
+	"bank_transfer": true,
+}
+
+// Mock reports whether the client runs without its provider: its mode is
+// not live, which an unset mode is not, or it has no live implementation
+func (c Client) Mock() bool {
+	return c.Mode != ClientModeLive || !liveClients[c.Name]
+}
+
+// Clients lists the integration clients the configuration enables
+func (c *Config) Clients() []Client {
+	clients := []Client{
+		{Name: "upi", Mode: c.UPI.Mode, Endpoint: c.UPI.Endpoint},
+		{Name: "ad_bank", Mode: c.ADBank.Mode, Endpoint: c.ADBank.Endpoint},
+		{Name: "wise", Mode: c.Wise.Mode, Endpoint: c.Wise.Endpoint},
+	}
+	if c.Payments.Card.Enabled {
+		clients = append(clients, Client{Name: "card", Mode: c.Payments.Card.Mode, Endpoint: c.Payments.Card.Endpoint})
+	}
+	if c.Payments.BankTransfer.Enabled {
+		// Transfer instructions are built locally, so the client is as live
+		// as the collection account it names
+		clients = append(clients, Client{Name: "bank_transfer", Mode: ClientModeLive, Local: true})
+	}
+	return clients
+}
+
+// ValidateEnvironment checks the environment name, that no client without
+// a live implementation is configured live, and that a production
+// deployment only runs live clients with real endpoints. An empty
+// environment is dev.
+func (c *Config) ValidateEnvironment() error {
+	switch c.Environment {
+	case "", EnvironmentDev, EnvironmentStaging, EnvironmentProd:
+	default:
+		return fmt.Errorf("unknown environment %q", c.Environment)
+	}
+
+	for _, client := range c.Clients() {
+		switch client.Mode {
+		case "", ClientModeMock, ClientModeLive:
+		default:
+			return fmt.Errorf("%s: unknown client mode %q", client.Name, client.Mode)
+		}
+		if client.Mode == ClientModeLive && !liveClients[client.Name] {
+			return fmt.Errorf("%s: live mode is not implemented, the client is a mock", client.Name)
+		}
+
+		if c.Environment != EnvironmentProd {
+			continue
+		}
+		if client.Mock() {
+			return fmt.Errorf("%s: mock client not allowed in prod", client.Name)
+		}
+		if !client.Local && !realEndpoint(client.Endpoint) {
+			return fmt.Errorf("%s: endpoint %q is not a real https endpoint", client.Name, client.Endpoint)
+		}
+	}
+	return nil
+}
+
+// realEndpoint reports whether endpoint is an https URL that is neither a
+// loopback address nor a documentation placeholder
+func realEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+
+	host := u.Hostname()
+	switch {
+	case host == "", host == "localhost", host == "127.0.0.1", host == "::1":
+		return false
+	case host == "example.com", strings.HasSuffix(host, ".example.com"):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate checks the configuration for mistakes that would otherwise
+// surface as a crash or misbehaviour after startup
+func (c *Config) Validate() error {
+	if err := c.ValidateEnvironment(); err != nil {
+		return err
+	}
+	if err := c.Auth.validate(); err != nil {
+		return err
+	}
+	if err := c.Server.validatePageSizes(); err != nil {
+		return err
+	}
+	if c.Server.RateDecimals < 0 {
+		return fmt.Errorf("server: rate_decimals %d must not be negative", c.Server.RateDecimals)
+	}
+	if c.Server.LogSampleRate < 0 || c.Server.LogSampleRate > 1 {
+		return fmt.Errorf("server: log_sample_rate %g must be between 0 and 1", c.Server.LogSampleRate)
+	}
+	return c.validateCurrencyPairs()
+}
+
+// MinJWTSecretLength is the shortest JWT signing key accepted, the output
+// size of the HS256 hash
+const MinJWTSecretLength = 32
+
+// placeholderJWTSecret is the value shipped in config/app.yaml, which must
+// be replaced in every deployment
+const placeholderJWTSecret = "change-me"
+
+// validate requires a signing key no one could guess: set, not the shipped
+// placeholder and at least MinJWTSecretLength bytes
+func (a *AuthConfig) validate() error {
+	switch {
+	case a.JWTSecret == "":
+		return errors.New("auth: jwt_secret is not set")
+	case a.JWTSecret == placeholderJWTSecret:
+		return errors.New("auth: jwt_secret is the placeholder value")
+	case len(a.JWTSecret) < MinJWTSecretLength:
+		return fmt.Errorf("auth: jwt_secret must be at least %d bytes", MinJWTSecretLength)
+	}
+	return nil
+}
+
+// validatePageSizes requires the default page size to fit within the
+// maximum when both are set
+func (s *ServerConfig) validatePageSizes() error {
+	if s.DefaultPageSize < 0 || s.MaxPageSize < 0 {
+		return errors.New("server: page sizes must not be negative")
+	}
+	if s.DefaultPageSize > 0 && s.MaxPageSize > 0 && s.DefaultPageSize > s.MaxPageSize {
+		return fmt.Errorf("server: default_page_size %d exceeds max_page_size %d", s.DefaultPageSize, s.MaxPageSize)
+	}
+	return nil
+}
+
+// validateCurrencyPairs requires at least one enabled pair and a default
+// pair that is configured and enabled
+func (c *Config) validateCurrencyPairs() error {
+	if _, ok := c.DefaultCurrencyPair(); !ok {
+		if c.DefaultPair != "" {
+			return fmt.Errorf("default_currency_pair %q is not a configured, enabled pair", c.DefaultPair)
+		}
+		return errors.New("currency_pairs: at least one enabled pair is required")
+	}
+	return nil
+}
+
+// DefaultCurrencyPair returns the pair named by DefaultPair, or the first
+// enabled pair when none is named. It reports false when that pair is
+// missing or disabled.
+func (c *Config) DefaultCurrencyPair() (CurrencyPairConfig, bool) {
+	for _, pair := range c.CurrencyPairs {
+		if !pair.Enabled {
+			continue
+		}
+		if c.DefaultPair == "" || pair.Key() == c.DefaultPair {
+			return pair, true
+		}
+	}
+	return CurrencyPairConfig{}, false
+}
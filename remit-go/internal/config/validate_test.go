@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// validConfig returns a configuration that passes Validate
+func validConfig() *Config {
+	return &Config{
+		Auth:          AuthConfig{JWTSecret: strings.Repeat("s", MinJWTSecretLength)},
+		CurrencyPairs: []CurrencyPairConfig{{Source: "INR", Target: "CAD", Enabled: true}},
+	}
+}
+
+func TestValidateJWTSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr string
+	}{
+		{"long enough", strings.Repeat("s", MinJWTSecretLength), ""},
+		{"empty", "", "jwt_secret is not set"},
+		{"placeholder", "change-me", "placeholder"},
+		{"too short", strings.Repeat("s", MinJWTSecretLength-1), "at least 32 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Auth.JWTSecret = tt.secret
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateClientModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(c *Config)
+		wantErr   string
+	}{
+		{"all mock in dev", func(c *Config) {}, ""},
+		{"live wise in dev", func(c *Config) { c.Wise.Mode = ClientModeLive }, ""},
+		{"live upi", func(c *Config) { c.UPI.Mode = ClientModeLive }, "upi: live mode is not implemented"},
+		{"live ad bank", func(c *Config) { c.ADBank.Mode = ClientModeLive }, "ad_bank: live mode is not implemented"},
+		{"live card", func(c *Config) {
+			c.Payments.Card.Enabled = true
+			c.Payments.Card.Mode = ClientModeLive
+		}, "card: live mode is not implemented"},
+		{"unknown mode", func(c *Config) { c.Wise.Mode = "sandbox" }, `unknown client mode "sandbox"`},
+		{"mock in prod", func(c *Config) { c.Environment = EnvironmentProd }, "mock client not allowed in prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.configure(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePageSizes(t *testing.T) {
+	tests := []struct {
+		name        string
+		defaultSize int
+		maxSize     int
+		wantErr     string
+	}{
+		{"unset", 0, 0, ""},
+		{"default within maximum", 20, 50, ""},
+		{"default equal to maximum", 50, 50, ""},
+		{"only default", 200, 0, ""},
+		{"default above maximum", 60, 50, "default_page_size 60 exceeds max_page_size 50"},
+		{"negative default", -1, 0, "page sizes must not be negative"},
+		{"negative maximum", 0, -1, "page sizes must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Server.DefaultPageSize = tt.defaultSize
+			cfg.Server.MaxPageSize = tt.maxSize
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLogSampleRate(t *testing.T) {
+	tests := []struct {
+		rate    float64
+		wantErr bool
+	}{
+		{0, false},
+		{0.1, false},
+		{1, false},
+		{-0.1, true},
+		{1.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.rate), func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Server.LogSampleRate = tt.rate
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "log_sample_rate") {
+				t.Errorf("Validate() = %v, want it to name log_sample_rate", err)
+			}
+		})
+	}
+}
+
+func TestValidateCurrencyPairs(t *testing.T) {
+	inrCAD := CurrencyPairConfig{Source: "INR", Target: "CAD", Enabled: true}
+	inrUSD := CurrencyPairConfig{Source: "INR", Target: "USD", Enabled: true}
+	disabled := CurrencyPairConfig{Source: "INR", Target: "GBP"}
+
+	tests := []struct {
+		name        string
+		pairs       []CurrencyPairConfig
+		defaultPair string
+		wantErr     string
+		wantDefault string
+	}{
+		{"no pairs", nil, "", "at least one enabled pair is required", ""},
+		{"only disabled pairs", []CurrencyPairConfig{disabled}, "", "at least one enabled pair is required", ""},
+		{"first enabled pair", []CurrencyPairConfig{disabled, inrCAD, inrUSD}, "", "", "INR/CAD"},
+		{"named default", []CurrencyPairConfig{inrCAD, inrUSD}, "INR/USD", "", "INR/USD"},
+		{"unknown default", []CurrencyPairConfig{inrCAD}, "INR/EUR", `default_currency_pair "INR/EUR" is not a configured, enabled pair`, ""},
+		{"disabled default", []CurrencyPairConfig{inrCAD, disabled}, "INR/GBP", `default_currency_pair "INR/GBP" is not a configured, enabled pair`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.CurrencyPairs = tt.pairs
+			cfg.DefaultPair = tt.defaultPair
+
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() = %v, want nil", err)
+			}
+			if pair, ok := cfg.DefaultCurrencyPair(); !ok || pair.Key() != tt.wantDefault {
+				t.Errorf("DefaultCurrencyPair() = %s, %v; want %s", pair.Key(), ok, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestClients(t *testing.T) {
+	cfg := validConfig()
+	cfg.Wise.Mode = ClientModeLive
+	cfg.UPI.Mode = ClientModeMock
+	cfg.Payments.BankTransfer.Enabled = true
+
+	mock := make(map[string]bool)
+	for _, client := range cfg.Clients() {
+		mock[client.Name] = client.Mock()
+	}
+	want := map[string]bool{"upi": true, "ad_bank": true, "wise": false, "bank_transfer": false}
+	if len(mock) != len(want) {
+		t.Fatalf("Clients() = %v, want %v", mock, want)
+	}
+	for name, wantMock := range want {
+		if got, ok := mock[name]; !ok || got != wantMock {
+			t.Errorf("%s: listed = %v, Mock() = %v; want listed, Mock() = %v", name, ok, got, wantMock)
+		}
+	}
+}
+
+func TestValidateRateDecimals(t *testing.T) {
+	tests := []struct {
+		decimals int
+		wantErr  bool
+	}{
+		{0, false},
+		{6, false},
+		{-1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.decimals), func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Server.RateDecimals = tt.decimals
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "rate_decimals") {
+				t.Errorf("Validate() = %v, want it to name rate_decimals", err)
+			}
+		})
+	}
+}
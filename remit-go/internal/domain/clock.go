@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// Now returns the current time in UTC. Timestamps stored on domain values
+// are taken from here so they serialize as RFC 3339 with a Z suffix
+// whatever the server's local zone.
+func Now() time.Time {
+	return time.Now().UTC()
+}
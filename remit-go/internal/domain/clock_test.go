@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampsInUTC(t *testing.T) {
+	// Run as a server whose local zone is not UTC
+	local := time.Local
+	time.Local = time.FixedZone("IST", 5*3600+1800)
+	t.Cleanup(func() { time.Local = local })
+
+	tx := NewTransaction("user-1", 10000, "INR", "CAD", &RecipientDetails{})
+	tx.SetRates(0.0165, 0.016, 0.03, FeeModelExclusive)
+	tx.UpdateStatus(StatusPaymentPending)
+	rate := NewExchangeRate("ad_bank", "INR", "CAD", 0.016)
+
+	tests := []struct {
+		name string
+		ts   time.Time
+	}{
+		{"created", tx.CreatedAt},
+		{"updated", tx.UpdatedAt},
+		{"rate locked", tx.RateLockedAt},
+		{"status changed", tx.StatusHistory[len(tx.StatusHistory)-1].At},
+		{"rate fetched", rate.FetchedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.ts.IsZero() {
+				t.Fatal("timestamp not set")
+			}
+			if tt.ts.Location() != time.UTC {
+				t.Errorf("location = %v, want UTC", tt.ts.Location())
+			}
+			data, err := json.Marshal(tt.ts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(string(data), `Z"`) {
+				t.Errorf("JSON = %s, want a Z suffix", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Digit grouping conventions
+const (
+	groupingWestern = iota // 1,234,567.89
+	groupingIndian         // 12,34,567.89 (lakhs and crores)
+)
+
+// currency describes how amounts in a currency are written
+type currency struct {
+	Symbol     string
+	MinorUnits int
+	Grouping   int
+}
+
+// currencies holds the formatting conventions of supported currencies
+var currencies = map[string]currency{
+	"INR": {Symbol: "₹", MinorUnits: 2, Grouping: groupingIndian},
+	"CAD": {Symbol: "CA$", MinorUnits: 2, Grouping: groupingWestern},
+	"USD": {Symbol: "US$", MinorUnits: 2, Grouping: groupingWestern},
+	"EUR": {Symbol: "€", MinorUnits: 2, Grouping: groupingWestern},
+	"GBP": {Symbol: "£", MinorUnits: 2, Grouping: groupingWestern},
+}
+
+// defaultMinorUnits is used for currencies without a known convention
+const defaultMinorUnits = 2
+
+// CurrencyPrecision returns the number of minor-unit digits of a currency
+func CurrencyPrecision(code string) int {
+	if c, ok := currencies[code]; ok {
+		return c.MinorUnits
+	}
+	return defaultMinorUnits
+}
+
+// RoundAmount rounds an amount to the minor units of its currency, so the
+// same value always serializes to the same number
+func RoundAmount(amount float64, code string) float64 {
+	scale := math.Pow10(CurrencyPrecision(code))
+	return math.Round(amount*scale) / scale
+}
+
+// CeilAmount rounds an amount up to a whole minor unit of its currency
+func CeilAmount(amount float64, code string) float64 {
+	scale := math.Pow10(CurrencyPrecision(code))
+	// Tolerate float error so an exact amount is not bumped a minor unit
+	return math.Ceil(amount*scale-1e-6) / scale
+}
+
+// RoundRate rounds an exchange rate to decimals places for display. Rates
+// are stored and applied at full precision; zero decimals leaves the rate
+// as is.
+func RoundRate(rate float64, decimals int) float64 {
+	if decimals <= 0 {
+		return rate
+	}
+	scale := math.Pow10(decimals)
+	return math.Round(rate*scale) / scale
+}
+
+// FormatAmount renders an amount following the conventions of its currency,
+// e.g. "₹1,00,000.00" for INR or "CA$1,600.00" for CAD. Unknown currencies
+// fall back to western grouping prefixed with the currency code.
+func FormatAmount(amount float64, code string) string {
+	c, ok := currencies[code]
+	if !ok {
+		c = currency{Symbol: code + " ", MinorUnits: defaultMinorUnits, Grouping: groupingWestern}
+	}
+
+	// Round as RoundAmount does, so the display matches the serialized amount
+	amount = RoundAmount(amount, code)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = math.Abs(amount)
+	}
+
+	digits := strconv.FormatFloat(amount, 'f', c.MinorUnits, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var grouped string
+	if c.Grouping == groupingIndian {
+		grouped = groupIndian(whole)
+	} else {
+		grouped = groupThousands(whole)
+	}
+
+	if fraction != "" {
+		grouped += "." + fraction
+	}
+
+	return sign + c.Symbol + grouped
+}
+
+// groupThousands inserts a separator every three digits
+func groupThousands(whole string) string {
+	if len(whole) <= 3 {
+		return whole
+	}
+
+	var b strings.Builder
+	head := len(whole) % 3
+	if head > 0 {
+		b.WriteString(whole[:head])
+	}
+	for i := head; i < len(whole); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(whole[i : i+3])
+	}
+	return b.String()
+}
+
+// groupIndian separates the last three digits, then every two digits
+func groupIndian(whole string) string {
+	if len(whole) <= 3 {
+		return whole
+	}
+
+	rest, last := whole[:len(whole)-3], whole[len(whole)-3:]
+
+	var b strings.Builder
+	head := len(rest) % 2
+	if head > 0 {
+		b.WriteString(rest[:head])
+	}
+	for i := head; i < len(rest); i += 2 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(rest[i : i+2])
+	}
+	b.WriteByte(',')
+	b.WriteString(last)
+	return b.String()
+}
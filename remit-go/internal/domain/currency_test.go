@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount float64
+		code   string
+		want   string
+	}{
+		{0, "INR", "₹0.00"},
+		{999.5, "INR", "₹999.50"},
+		{1000, "INR", "₹1,000.00"},
+		{100000, "INR", "₹1,00,000.00"},
+		{12345678.9, "INR", "₹1,23,45,678.90"},
+		{1600, "CAD", "CA$1,600.00"},
+		{1234567.891, "USD", "US$1,234,567.89"},
+		{100, "EUR", "€100.00"},
+		{-1500.25, "GBP", "-£1,500.25"},
+		{1234.5, "XYZ", "XYZ 1,234.50"},
+		{160.005, "CAD", "CA$160.01"}, // as RoundAmount rounds it
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatAmount(tt.amount, tt.code); got != tt.want {
+				t.Errorf("FormatAmount(%v, %s) = %q, want %q", tt.amount, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundRate(t *testing.T) {
+	tests := []struct {
+		rate     float64
+		decimals int
+		want     float64
+	}{
+		{0.01612374, 6, 0.016124},
+		{0.01612374, 4, 0.0161},
+		{0.01612374, 0, 0.01612374},
+		{0.01612374, -1, 0.01612374},
+		{83.4567891, 2, 83.46},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.rate, "/", tt.decimals), func(t *testing.T) {
+			if got := RoundRate(tt.rate, tt.decimals); got != tt.want {
+				t.Errorf("RoundRate(%v, %d) = %v, want %v", tt.rate, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
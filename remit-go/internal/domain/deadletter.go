@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// DeadLetter records a background operation that failed for good, with
+// enough to inspect it and run it again
+type DeadLetter struct {
+	ID            string            `json:"id" dynamodbav:"dead_letter_id"`
+	Operation     string            `json:"operation" dynamodbav:"operation"`
+	TransactionID string            `json:"transaction_id,omitempty" dynamodbav:"transaction_id,omitempty"`
+	Payload       map[string]string `json:"payload,omitempty" dynamodbav:"payload,omitempty"`
+	Error         string            `json:"error" dynamodbav:"error"`
+	CreatedAt     time.Time         `json:"created_at" dynamodbav:"created_at"`
+}
+
+// NewDeadLetter records the failure of an operation
+func NewDeadLetter(id, operation, txID string, payload map[string]string, err error) *DeadLetter {
+	return &DeadLetter{
+		ID:            id,
+		Operation:     operation,
+		TransactionID: txID,
+		Payload:       payload,
+		Error:         err.Error(),
+		CreatedAt:     Now(),
+	}
+}
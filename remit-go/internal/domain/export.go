@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// ExportStatus is the state of an export job
+type ExportStatus string
+
+const (
+	ExportPending   ExportStatus = "PENDING"
+	ExportRunning   ExportStatus = "RUNNING"
+	ExportReady     ExportStatus = "READY"
+	ExportFailed    ExportStatus = "FAILED"
+	ExportCancelled ExportStatus = "CANCELLED"
+)
+
+// ExportJob is a user's transaction history export produced in the
+// background. The file itself is fetched separately once the job is READY.
+type ExportJob struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"-"`
+	Status      ExportStatus `json:"status"`
+	Format      string       `json:"format"`
+	Rows        int          `json:"rows"` // transactions written so far
+	Error       string       `json:"error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time    `json:"expires_at"` // the job and its file are dropped after this
+}
+
+// IsFinished reports whether the job will not change any more
+func (j *ExportJob) IsFinished() bool {
+	return j.Status == ExportReady || j.Status == ExportFailed || j.Status == ExportCancelled
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IDScheme selects how the unique part of a transaction ID is generated
+type IDScheme string
+
+const (
+	IDSchemeTimestamp IDScheme = "timestamp" // 20060102150405-<8 random hex digits>
+	IDSchemeUUID      IDScheme = "uuid"      // random (version 4) UUID
+	IDSchemeULID      IDScheme = "ulid"      // lexicographically sortable ULID
+)
+
+const (
+	defaultIDPrefix = "TXN-"
+	paymentIDPrefix = "PAY-"
+)
+
+// idPrefix and idScheme shape new transaction IDs. Set once at startup
+// through ConfigureIDs.
+var (
+	idPrefix = defaultIDPrefix
+	idScheme = IDSchemeTimestamp
+)
+
+// ConfigureIDs sets the prefix and scheme of new transaction IDs, e.g.
+// "TXN-PROD-" to tell deployments apart. An empty prefix or scheme keeps the
+// default. Must be called before any transaction is created.
+func ConfigureIDs(prefix string, scheme IDScheme) error {
+	switch scheme {
+	case "":
+		scheme = IDSchemeTimestamp
+	case IDSchemeTimestamp, IDSchemeUUID, IDSchemeULID:
+	default:
+		return fmt.Errorf("unknown ID scheme %q", scheme)
+	}
+	if prefix == "" {
+		prefix = defaultIDPrefix
+	}
+
+	idPrefix, idScheme = prefix, scheme
+	return nil
+}
+
+// PaymentID returns the ID of the payment for a transaction
+func PaymentID(txID string) string {
+	return paymentIDPrefix + txID
+}
+
+// TransactionIDFromPaymentID returns the transaction a payment belongs to
+func TransactionIDFromPaymentID(paymentID string) (string, bool) {
+	return strings.CutPrefix(paymentID, paymentIDPrefix)
+}
+
+// generateTransactionID generates a unique transaction ID. Every scheme
+// carries at least 32 random bits, so IDs created in the same instant differ.
+func generateTransactionID() string {
+	now := time.Now()
+	switch idScheme {
+	case IDSchemeUUID:
+		return idPrefix + newUUID()
+	case IDSchemeULID:
+		return idPrefix + newULID(now)
+	default:
+		return idPrefix + now.UTC().Format("20060102150405") + "-" + hex.EncodeToString(randomBytes(4))
+	}
+}
+
+func newUUID() string {
+	b := randomBytes(16)
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// crockford is the base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID encodes a 48-bit millisecond timestamp followed by 80 random bits
+// as 26 Crockford base32 characters
+func newULID(now time.Time) string {
+	var b [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(now.UnixMilli()))
+	copy(b[:6], ms[2:])
+	copy(b[6:], randomBytes(10))
+
+	// 128 bits as 26 five-bit groups, the first holding the top 3 bits
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return b
+}
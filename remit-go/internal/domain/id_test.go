@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestConfigureIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		scheme  IDScheme
+		want    string // pattern of generated IDs
+		wantErr bool
+	}{
+		{"defaults", "", "", `^TXN-\d{14}-[0-9a-f]{8}$`, false},
+		{"prefix", "TXN-PROD-", IDSchemeTimestamp, `^TXN-PROD-\d{14}-[0-9a-f]{8}$`, false},
+		{"uuid", "", IDSchemeUUID, `^TXN-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, false},
+		{"ulid", "T-", IDSchemeULID, `^T-[0-9A-HJKMNP-TV-Z]{26}$`, false},
+		{"unknown scheme", "", "sequential", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { idPrefix, idScheme = defaultIDPrefix, IDSchemeTimestamp })
+
+			err := ConfigureIDs(tt.prefix, tt.scheme)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConfigureIDs() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if idPrefix != defaultIDPrefix || idScheme != IDSchemeTimestamp {
+					t.Errorf("rejected scheme changed the IDs to %q %q", idPrefix, idScheme)
+				}
+				return
+			}
+
+			first, second := generateTransactionID(), generateTransactionID()
+			if !regexp.MustCompile(tt.want).MatchString(first) {
+				t.Errorf("ID = %q, want it to match %s", first, tt.want)
+			}
+			if first == second {
+				t.Errorf("IDs generated together are equal: %q", first)
+			}
+		})
+	}
+}
+
+func TestULIDSortsByTime(t *testing.T) {
+	earlier := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Millisecond)
+
+	for range 100 {
+		if a, b := newULID(earlier), newULID(later); a >= b {
+			t.Fatalf("ULID %s at %s sorts after %s a millisecond later", a, earlier, b)
+		}
+	}
+}
+
+func TestPaymentIDRoundTrip(t *testing.T) {
+	tests := []struct {
+		paymentID string
+		wantTx    string
+		wantOK    bool
+	}{
+		{PaymentID("TXN-PROD-1"), "TXN-PROD-1", true},
+		{"TXN-PROD-1", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.paymentID, func(t *testing.T) {
+			txID, ok := TransactionIDFromPaymentID(tt.paymentID)
+			if ok != tt.wantOK || (ok && txID != tt.wantTx) {
+				t.Errorf("TransactionIDFromPaymentID(%q) = %q, %v; want %q, %v", tt.paymentID, txID, ok, tt.wantTx, tt.wantOK)
+			}
+		})
+	}
+}
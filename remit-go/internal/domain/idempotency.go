@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord ties an idempotency key to the transaction its first
+// request created. TransactionID is empty while that request is in flight.
+type IdempotencyRecord struct {
+	Key           string `json:"key" dynamodbav:"idempotency_key"`
+	TransactionID string `json:"transaction_id,omitempty" dynamodbav:"transaction_id,omitempty"`
+	// ExpiresAt is in Unix seconds, the format DynamoDB TTL expects
+	ExpiresAt int64 `json:"expires_at" dynamodbav:"expires_at"`
+}
+
+// NewIdempotencyRecord creates a pending record that expires after ttl
+func NewIdempotencyRecord(key string, ttl time.Duration) *IdempotencyRecord {
+	return &IdempotencyRecord{
+		Key:       key,
+		ExpiresAt: Now().Add(ttl).Unix(),
+	}
+}
+
+// IsExpired checks if the record no longer applies. DynamoDB deletes expired
+// items lazily, so reads must check this themselves.
+func (r *IdempotencyRecord) IsExpired(now time.Time) bool {
+	return now.Unix() >= r.ExpiresAt
+}
@@ -0,0 +1,31 @@
+package domain
+
+// Limits describes what a user may send and what it costs
+type Limits struct {
+	Currency       string      `json:"currency"`
+	MinAmount      float64     `json:"min_amount"`
+	MaxAmount      float64     `json:"max_amount"`
+	DailyLimit     float64     `json:"daily_limit"`
+	UsedToday      float64     `json:"used_today"`
+	RemainingToday float64     `json:"remaining_today"`
+	Fees           FeeSchedule `json:"fees"`
+}
+
+// FeeSchedule holds the fee parameters applied to new transactions. The
+// variable fee is Rate times the amount, clamped to [Min, Max], unless Tiers
+// are set, in which case it comes from the tier covering the amount.
+type FeeSchedule struct {
+	BaseFee        float64   `json:"base_fee"`
+	VariableRate   float64   `json:"variable_rate"`
+	VariableFeeMin float64   `json:"variable_fee_min"`
+	VariableFeeMax float64   `json:"variable_fee_max"`
+	Tiers          []FeeTier `json:"tiers,omitempty"`
+}
+
+// FeeTier is the variable fee, Flat plus Rate times the amount, for amounts
+// up to and including UpTo. UpTo zero means no upper bound.
+type FeeTier struct {
+	UpTo float64 `json:"up_to,omitempty"`
+	Rate float64 `json:"rate"`
+	Flat float64 `json:"flat,omitempty"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+// PaymentMethod is how the sender funds a transaction
+type PaymentMethod string
+
+const (
+	PaymentMethodUPI          PaymentMethod = "UPI"
+	PaymentMethodCard         PaymentMethod = "CARD"
+	PaymentMethodBankTransfer PaymentMethod = "BANK_TRANSFER"
+)
+
+// DefaultPaymentMethod is used when a transaction names none
+const DefaultPaymentMethod = PaymentMethodUPI
+
+// Valid reports whether m is one of the known payment methods
+func (m PaymentMethod) Valid() bool {
+	switch m {
+	case PaymentMethodUPI, PaymentMethodCard, PaymentMethodBankTransfer:
+		return true
+	}
+	return false
+}
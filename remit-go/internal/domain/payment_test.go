@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestPaymentMethodValid(t *testing.T) {
+	tests := []struct {
+		method PaymentMethod
+		want   bool
+	}{
+		{PaymentMethodUPI, true},
+		{PaymentMethodCard, true},
+		{PaymentMethodBankTransfer, true},
+		{"upi", false},
+		{"CASH", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			if got := tt.method.Valid(); got != tt.want {
+				t.Errorf("Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// Quote previews what a transfer would cost and deliver at current rates
+type Quote struct {
+	SourceAmount   float64   `json:"source_amount"`
+	SourceCurrency string    `json:"source_currency"`
+	TargetAmount   float64   `json:"target_amount"`
+	TargetCurrency string    `json:"target_currency"`
+	MidMarketRate  float64   `json:"mid_market_rate"`
+	ExchangeRate   float64   `json:"exchange_rate"` // rate offered to the customer
+	FallbackRate   bool      `json:"fallback_rate,omitempty"`
+	RateSource     string    `json:"rate_source,omitempty"` // provider of the mid-market rate
+	RateFetchedAt  time.Time `json:"rate_fetched_at"`
+	Fees           *Fees     `json:"fees"`
+	ExpiresAt      time.Time `json:"expires_at"`
+
+	// SourceDisplay and TargetDisplay are the amounts formatted for their
+	// currencies, filled only when a client asks for them
+	SourceDisplay string `json:"source_display,omitempty"`
+	TargetDisplay string `json:"target_display,omitempty"`
+}
+
+// FormatAmounts fills SourceDisplay and TargetDisplay
+func (q *Quote) FormatAmounts() {
+	q.SourceDisplay = FormatAmount(q.SourceAmount, q.SourceCurrency)
+	q.TargetDisplay = FormatAmount(q.TargetAmount, q.TargetCurrency)
+}
+
+// RoundRates rounds the rates for display, see RoundRate
+func (q *Quote) RoundRates(decimals int) {
+	q.MidMarketRate = RoundRate(q.MidMarketRate, decimals)
+	q.ExchangeRate = RoundRate(q.ExchangeRate, decimals)
+}
+
+// FXSpread is the cost of the exchange rate margin: the target amount lost
+// by converting at the customer rate instead of the mid-market rate
+func FXSpread(sourceAmount, midMarketRate, customerRate float64) float64 {
+	return sourceAmount * (midMarketRate - customerRate)
+}
+
+// SourceAmountFor returns the smallest amount, in whole minor units of the
+// source currency, that converts to at least targetAmount at rate. It is the
+// net amount needed; fees are left to the caller.
+func SourceAmountFor(targetAmount, rate float64, sourceCurrency string) float64 {
+	return CeilAmount(targetAmount/rate, sourceCurrency)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// ExchangeRate is a rate quoted by the rate provider for a currency pair
+type ExchangeRate struct {
+	Pair           string    `json:"pair" dynamodbav:"pair"` // "SOURCE/TARGET"
+	SourceCurrency string    `json:"source_currency" dynamodbav:"source_currency"`
+	TargetCurrency string    `json:"target_currency" dynamodbav:"target_currency"`
+	Rate           float64   `json:"rate" dynamodbav:"rate"`
+	FetchedAt      time.Time `json:"fetched_at" dynamodbav:"fetched_at"`
+
+	// Provider names the rate provider the rate came from
+	Provider string `json:"provider,omitempty" dynamodbav:"provider,omitempty"`
+}
+
+// NewExchangeRate records a rate fetched now from provider
+func NewExchangeRate(provider, sourceCurrency, targetCurrency string, rate float64) *ExchangeRate {
+	return &ExchangeRate{
+		Pair:           RatePair(sourceCurrency, targetCurrency),
+		SourceCurrency: sourceCurrency,
+		TargetCurrency: targetCurrency,
+		Rate:           rate,
+		FetchedAt:      Now(),
+		Provider:       provider,
+	}
+}
+
+// RatePair returns the "SOURCE/TARGET" key of a currency pair
+func RatePair(sourceCurrency, targetCurrency string) string {
+	return sourceCurrency + "/" + targetCurrency
+}
+
+// Age returns how long ago the rate was fetched
+func (r *ExchangeRate) Age(now time.Time) time.Duration {
+	return now.Sub(r.FetchedAt)
+}
+
+// CurrencyPair is a corridor and whether new transactions may use it
+type CurrencyPair struct {
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Enabled bool   `json:"enabled"`
+}
@@ -0,0 +1,97 @@
+package domain
+
+import "time"
+
+// ReconciliationReport aggregates transactions created within a date range
+type ReconciliationReport struct {
+	From          time.Time                    `json:"from"`
+	To            time.Time                    `json:"to"`
+	CountByStatus map[TransactionStatus]int    `json:"count_by_status"`
+	Totals        map[string]*CurrencyTotals   `json:"totals"`
+	Rates         map[string]*RateVarianceStat `json:"rates"`
+
+	// SettlementReview lists completed transactions whose delivered amount
+	// varied from the quote beyond the settlement tolerance
+	SettlementReview []string `json:"settlement_review,omitempty"`
+}
+
+// CurrencyTotals holds the completed volume in a single currency. Sent and
+// fees accrue to the source currency, delivered amounts and their variance
+// from the quotes to the target. Delivered counts the amount Wise reported
+// where it did, the quoted target amount otherwise.
+type CurrencyTotals struct {
+	Sent               float64 `json:"sent"`
+	FeesCollected      float64 `json:"fees_collected"`
+	Delivered          float64 `json:"delivered"`
+	SettlementVariance float64 `json:"settlement_variance"`
+}
+
+// RateVarianceStat summarizes the rates applied on a currency pair
+type RateVarianceStat struct {
+	Count   int     `json:"count"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Average float64 `json:"average"`
+	Spread  float64 `json:"spread"` // Max - Min
+}
+
+// NewReconciliationReport creates an empty report for the range
+func NewReconciliationReport(from, to time.Time) *ReconciliationReport {
+	return &ReconciliationReport{
+		From:          from,
+		To:            to,
+		CountByStatus: make(map[TransactionStatus]int),
+		Totals:        make(map[string]*CurrencyTotals),
+		Rates:         make(map[string]*RateVarianceStat),
+	}
+}
+
+// Add folds a transaction into the report. Only completed transactions
+// contribute to money totals and rate variance.
+func (r *ReconciliationReport) Add(tx *Transaction) {
+	r.CountByStatus[tx.Status]++
+	if !tx.IsCompleted() {
+		return
+	}
+
+	source := r.totals(tx.SourceCurrency)
+	source.Sent += tx.SourceAmount
+	if tx.Fees != nil {
+		source.FeesCollected += tx.Fees.TotalFee
+	}
+	target := r.totals(tx.TargetCurrency)
+	if tx.DeliveredAmount != nil {
+		target.Delivered += *tx.DeliveredAmount
+		target.SettlementVariance += tx.SettlementVariance
+	} else {
+		target.Delivered += tx.TargetAmount
+	}
+	if tx.SettlementReview {
+		r.SettlementReview = append(r.SettlementReview, tx.ID)
+	}
+
+	pair := tx.SourceCurrency + "/" + tx.TargetCurrency
+	stat, ok := r.Rates[pair]
+	if !ok {
+		stat = &RateVarianceStat{Min: tx.ExchangeRate, Max: tx.ExchangeRate}
+		r.Rates[pair] = stat
+	}
+	stat.Count++
+	if tx.ExchangeRate < stat.Min {
+		stat.Min = tx.ExchangeRate
+	}
+	if tx.ExchangeRate > stat.Max {
+		stat.Max = tx.ExchangeRate
+	}
+	stat.Average += (tx.ExchangeRate - stat.Average) / float64(stat.Count)
+	stat.Spread = stat.Max - stat.Min
+}
+
+func (r *ReconciliationReport) totals(currency string) *CurrencyTotals {
+	t, ok := r.Totals[currency]
+	if !ok {
+		t = &CurrencyTotals{}
+		r.Totals[currency] = t
+	}
+	return t
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReconciliationReportAdd(t *testing.T) {
+	completed := func(id string, amount, rate float64, delivered *float64) *Transaction {
+		tx := NewTransaction("user-1", amount, "INR", "CAD", &RecipientDetails{})
+		tx.ID = id
+		tx.SetFees(&Fees{BaseFee: 50, TotalFee: 50})
+		tx.SetExchangeRate(rate, FeeModelExclusive)
+		tx.Status = StatusCompleted
+		tx.DeliveredAmount = delivered
+		return tx
+	}
+	delivered := 159.5
+	pending := NewTransaction("user-1", 5000, "INR", "CAD", &RecipientDetails{})
+	pending.Status = StatusPaymentPending
+
+	report := NewReconciliationReport(time.Time{}, time.Now())
+	flagged := completed("TXN-2", 10000, 0.018, &delivered)
+	flagged.SettlementVariance, flagged.SettlementReview = delivered-180, true
+	for _, tx := range []*Transaction{
+		completed("TXN-1", 10000, 0.016, nil),
+		flagged,
+		pending,
+	} {
+		report.Add(tx)
+	}
+
+	counts := []struct {
+		status TransactionStatus
+		want   int
+	}{
+		{StatusCompleted, 2},
+		{StatusPaymentPending, 1},
+		{StatusFailed, 0},
+	}
+	for _, c := range counts {
+		if got := report.CountByStatus[c.status]; got != c.want {
+			t.Errorf("CountByStatus[%s] = %d, want %d", c.status, got, c.want)
+		}
+	}
+
+	// The pending transaction counts but adds no money
+	source, target := report.Totals["INR"], report.Totals["CAD"]
+	if source == nil || source.Sent != 20000 || source.FeesCollected != 100 {
+		t.Errorf("INR totals = %+v, want 20000 sent and 100 in fees", source)
+	}
+	// The quote where Wise reported nothing, the reported amount otherwise
+	if want := 160 + delivered; target == nil || math.Abs(target.Delivered-want) > 1e-9 {
+		t.Errorf("CAD totals = %+v, want %v delivered", target, want)
+	}
+	if want := delivered - 180; math.Abs(target.SettlementVariance-want) > 1e-9 {
+		t.Errorf("CAD settlement variance = %v, want %v", target.SettlementVariance, want)
+	}
+	if len(report.SettlementReview) != 1 || report.SettlementReview[0] != "TXN-2" {
+		t.Errorf("SettlementReview = %v, want TXN-2", report.SettlementReview)
+	}
+
+	stat := report.Rates["INR/CAD"]
+	if stat == nil {
+		t.Fatalf("Rates = %v, want INR/CAD", report.Rates)
+	}
+	if stat.Count != 2 || stat.Min != 0.016 || stat.Max != 0.018 ||
+		math.Abs(stat.Average-0.017) > 1e-12 || math.Abs(stat.Spread-0.002) > 1e-12 {
+		t.Errorf("INR/CAD rates = %+v", stat)
+	}
+}
@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,8 +18,32 @@ const (
 	StatusProcessing      TransactionStatus = "PROCESSING"
 	StatusCompleted       TransactionStatus = "COMPLETED"
 	StatusFailed          TransactionStatus = "FAILED"
+	StatusPaymentMismatch TransactionStatus = "PAYMENT_MISMATCH"
+	StatusPendingReview   TransactionStatus = "PENDING_REVIEW"
 )
 
+// Reasons recorded when a transaction fails. Transfer creation failures record
+// the provider's error code instead.
+const (
+	FailureReasonPaymentFailed  = "payment_failed"
+	FailureReasonTransferFailed = "transfer_failed"
+	FailureReasonReviewRejected = "rejected_in_review"
+	FailureReasonRateStale      = "rate_stale"
+	FailureReasonTransferStuck  = "transfer_initiation_timed_out"
+)
+
+// Statuses lists every transaction status
+var Statuses = []TransactionStatus{
+	StatusInitiated,
+	StatusPendingReview,
+	StatusPaymentPending,
+	StatusPaymentReceived,
+	StatusPaymentMismatch,
+	StatusProcessing,
+	StatusCompleted,
+	StatusFailed,
+}
+
 // Transaction represents a remittance transaction
 type Transaction struct {
 	ID               string            `json:"id" dynamodbav:"transaction_id"`
@@ -25,14 +53,76 @@ type Transaction struct {
 	TargetAmount     float64           `json:"target_amount" dynamodbav:"target_amount"`
 	TargetCurrency   string            `json:"target_currency" dynamodbav:"target_currency"`
 	ExchangeRate     float64           `json:"exchange_rate" dynamodbav:"exchange_rate"`
+	MidMarketRate    float64           `json:"mid_market_rate,omitempty" dynamodbav:"mid_market_rate,omitempty"`
+	RateLockedAt     time.Time         `json:"rate_locked_at" dynamodbav:"rate_locked_at"`
+	FallbackRate     bool              `json:"fallback_rate,omitempty" dynamodbav:"fallback_rate,omitempty"` // rate came from the last-known rate, reconcile later
 	Fees             *Fees             `json:"fees" dynamodbav:"fees"`
 	Status           TransactionStatus `json:"status" dynamodbav:"status"`
+	PaymentMethod    PaymentMethod     `json:"payment_method,omitempty" dynamodbav:"payment_method,omitempty"`
 	PaymentDetails   *PaymentDetails   `json:"payment_details" dynamodbav:"payment_details"`
 	RecipientDetails *RecipientDetails `json:"recipient_details" dynamodbav:"recipient_details"`
 	TransferID       string            `json:"transfer_id,omitempty" dynamodbav:"transfer_id,omitempty"`
+	Reference        string            `json:"reference,omitempty" dynamodbav:"reference,omitempty"` // customer's reference, or their idempotency key
+	FailureReason    string            `json:"failure_reason,omitempty" dynamodbav:"failure_reason,omitempty"`
+	RequiresReview   bool              `json:"requires_review,omitempty" dynamodbav:"requires_review,omitempty"`
 	CreatedAt        time.Time         `json:"created_at" dynamodbav:"created_at"`
 	UpdatedAt        time.Time         `json:"updated_at" dynamodbav:"updated_at"`
 	CompletedAt      *time.Time        `json:"completed_at,omitempty" dynamodbav:"completed_at,omitempty"`
+	StatusHistory    []StatusChange    `json:"status_history,omitempty" dynamodbav:"status_history,omitempty"`
+
+	// RecipientHash keys the recipient index: RecipientHash of the user and
+	// the recipient account, so the account is not stored as a plain key
+	RecipientHash string `json:"-" dynamodbav:"recipient_hash,omitempty"`
+
+	// DeliveredAmount is what Wise reported the recipient received and
+	// SettlementVariance its difference from TargetAmount. SettlementReview
+	// is set when the variance is beyond the settlement tolerance.
+	DeliveredAmount    *float64 `json:"delivered_amount,omitempty" dynamodbav:"delivered_amount,omitempty"`
+	SettlementVariance float64  `json:"settlement_variance,omitempty" dynamodbav:"settlement_variance,omitempty"`
+	SettlementReview   bool     `json:"settlement_review,omitempty" dynamodbav:"settlement_review,omitempty"`
+
+	// ProcessingStartedAt is when the transaction was claimed for a Wise
+	// transfer, set before the transfer is created
+	ProcessingStartedAt *time.Time `json:"processing_started_at,omitempty" dynamodbav:"processing_started_at,omitempty"`
+
+	// RateSource is the provider of the mid-market rate and RateFetchedAt
+	// when it was fetched, which for a fallback rate predates RateLockedAt
+	RateSource    string     `json:"rate_source,omitempty" dynamodbav:"rate_source,omitempty"`
+	RateFetchedAt *time.Time `json:"rate_fetched_at,omitempty" dynamodbav:"rate_fetched_at,omitempty"`
+
+	// Margin is the pair's margin, as a fraction, taken off MidMarketRate
+	// to give ExchangeRate
+	Margin float64 `json:"margin,omitempty" dynamodbav:"margin,omitempty"`
+
+	// ReminderSentAt is when the sender was reminded that the payment link
+	// is about to expire. Cleared when a new link is attached.
+	ReminderSentAt *time.Time `json:"reminder_sent_at,omitempty" dynamodbav:"reminder_sent_at,omitempty"`
+
+	// Audit fields recording who initiated the transaction and from where.
+	// Hidden from user responses; only admin endpoints expose them.
+	CreatedBy   string `json:"-" dynamodbav:"created_by,omitempty"`
+	CreatedByIP string `json:"-" dynamodbav:"created_by_ip,omitempty"`
+
+	// RedactedAt is set once the recipient's personal data has been erased,
+	// by the admin recorded in RedactedBy
+	RedactedAt *time.Time `json:"redacted_at,omitempty" dynamodbav:"redacted_at,omitempty"`
+	RedactedBy string     `json:"-" dynamodbav:"redacted_by,omitempty"`
+
+	// EstimatedDelivery is computed on demand and never persisted
+	EstimatedDelivery *DeliveryEstimate `json:"estimated_delivery,omitempty" dynamodbav:"-"`
+
+	// Replayed marks a transaction returned for a repeated idempotency key
+	// rather than created by the request. Never persisted.
+	Replayed bool `json:"idempotent_replayed,omitempty" dynamodbav:"-"`
+
+	// Warnings are notices about the request that created the transaction,
+	// returned with that response only. Never persisted.
+	Warnings []Warning `json:"warnings,omitempty" dynamodbav:"-"`
+
+	// SourceDisplay and TargetDisplay are the amounts formatted for their
+	// currencies, filled only when a client asks for them. Never persisted.
+	SourceDisplay string `json:"source_display,omitempty" dynamodbav:"-"`
+	TargetDisplay string `json:"target_display,omitempty" dynamodbav:"-"`
 }
 
 // Fees represents the fee structure for a transaction
@@ -41,28 +131,139 @@ type Fees struct {
 	VariableFee float64 `json:"variable_fee" dynamodbav:"variable_fee"`
 	WiseFee     float64 `json:"wise_fee" dynamodbav:"wise_fee"`
 	TotalFee    float64 `json:"total_fee" dynamodbav:"total_fee"`
+	PromoCode   string  `json:"promo_code,omitempty" dynamodbav:"promo_code,omitempty"`
+	Discount    float64 `json:"discount,omitempty" dynamodbav:"discount,omitempty"`
+
+	// FXSpread discloses the exchange rate margin as a cost, in the target
+	// currency. It is already reflected in the rate, not in TotalFee.
+	FXSpread float64 `json:"fx_spread" dynamodbav:"fx_spread"`
+}
+
+// Warning is a notice about a request that did not stop it from succeeding
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
+// WarningRecentRecipient means the user already sent money to the
+// recipient within the recent recipient window
+const WarningRecentRecipient = "recent_recipient"
+
 // PaymentDetails contains UPI payment information
 type PaymentDetails struct {
-	PaymentID   string     `json:"payment_id" dynamodbav:"payment_id"`
-	UPIID       string     `json:"upi_id" dynamodbav:"upi_id"`
-	PaymentLink string     `json:"payment_link" dynamodbav:"payment_link"`
-	Status      string     `json:"status" dynamodbav:"status"`
-	PaidAt      *time.Time `json:"paid_at,omitempty" dynamodbav:"paid_at,omitempty"`
+	PaymentID   string        `json:"payment_id" dynamodbav:"payment_id"`
+	Method      PaymentMethod `json:"method,omitempty" dynamodbav:"method,omitempty"`
+	UPIID       string        `json:"upi_id,omitempty" dynamodbav:"upi_id,omitempty"`
+	PaymentLink string        `json:"payment_link" dynamodbav:"payment_link"`
+	Status      string        `json:"status" dynamodbav:"status"`
+	PaidAt      *time.Time    `json:"paid_at,omitempty" dynamodbav:"paid_at,omitempty"`
+	ExpiresAt   *time.Time    `json:"expires_at,omitempty" dynamodbav:"expires_at,omitempty"`
+
+	// PaidAmount is the amount collected, in Currency, once the payment
+	// succeeded: as reported by the provider, else the amount requested
+	PaidAmount float64 `json:"paid_amount,omitempty" dynamodbav:"paid_amount,omitempty"`
+	Currency   string  `json:"currency,omitempty" dynamodbav:"currency,omitempty"`
+}
+
+// PaymentStatusCancelled marks a payment whose link the sender voided
+const PaymentStatusCancelled = "CANCELLED"
+
+// IsExpired checks if a pending payment link can no longer be used
+func (p *PaymentDetails) IsExpired(now time.Time) bool {
+	return p.Status == "PENDING" && p.ExpiresAt != nil && now.After(*p.ExpiresAt)
 }
 
 // RecipientDetails contains information about the recipient
 type RecipientDetails struct {
-	BankAccount string `json:"bank_account" dynamodbav:"bank_account"`
-	BankCode    string `json:"bank_code" dynamodbav:"bank_code"`
-	Name        string `json:"name" dynamodbav:"name"`
+	BankAccount string `json:"bank_account" dynamodbav:"bank_account" binding:"required,max=20"`
+	BankCode    string `json:"bank_code" dynamodbav:"bank_code" binding:"required,max=11"`
+	Name        string `json:"name" dynamodbav:"name" binding:"required,max=100"`
+
+	// AccountCurrency is the currency the account is held in, as given by
+	// the sender or reported by AD Bank. Empty when unknown.
+	AccountCurrency string `json:"account_currency,omitempty" dynamodbav:"account_currency,omitempty" binding:"omitempty,iso4217"`
+
+	// BankName is the name of the bank BankCode belongs to, looked up at
+	// initiation. Empty when the lookup failed.
+	BankName string `json:"bank_name,omitempty" dynamodbav:"bank_name,omitempty"`
+
+	// NameTransliterated is the Latin spelling of Name sent to Wise, for
+	// corridors that reject other scripts. Name stays for display.
+	NameTransliterated string `json:"name_transliterated,omitempty" dynamodbav:"name_transliterated,omitempty"`
+}
+
+// StatusChange records when a transaction entered a status
+type StatusChange struct {
+	Status TransactionStatus `json:"status" dynamodbav:"status"`
+	At     time.Time         `json:"at" dynamodbav:"at"`
+}
+
+// Timeline event types
+const (
+	TimelineStatus   = "status"
+	TimelinePayment  = "payment"
+	TimelineTransfer = "transfer"
+)
+
+// RateBreakdown shows how a transaction's customer rate was derived from
+// the mid-market rate
+type RateBreakdown struct {
+	TransactionID  string     `json:"transaction_id"`
+	SourceCurrency string     `json:"source_currency"`
+	TargetCurrency string     `json:"target_currency"`
+	MidMarketRate  float64    `json:"mid_market_rate"`
+	MarginPercent  float64    `json:"margin_percent"` // e.g. 0.5 for a 0.5% margin
+	CustomerRate   float64    `json:"customer_rate"`
+	RateSource     string     `json:"rate_source,omitempty"`
+	RateFetchedAt  *time.Time `json:"rate_fetched_at,omitempty"`
+	RateLockedAt   time.Time  `json:"rate_locked_at"`
+}
+
+// TimelineEntry is a single event in a transaction's chronological history
+type TimelineEntry struct {
+	At          time.Time         `json:"at"`
+	Type        string            `json:"type"`
+	Status      TransactionStatus `json:"status,omitempty"`
+	Description string            `json:"description"`
+}
+
+// DeliveryWindow is the expected time from payment receipt to funds arriving
+// with the recipient
+type DeliveryWindow struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// DeliveryEstimate describes when the recipient can expect the funds
+type DeliveryEstimate struct {
+	Status      TransactionStatus `json:"status"`
+	EarliestAt  *time.Time        `json:"earliest_at,omitempty"`
+	LatestAt    *time.Time        `json:"latest_at,omitempty"`
+	DeliveredAt *time.Time        `json:"delivered_at,omitempty"`
+}
+
+// Estimate projects the window from the given start time. Bounds that have
+// already passed are clamped to now, since the funds are then due imminently.
+func (w DeliveryWindow) Estimate(start, now time.Time) *DeliveryEstimate {
+	earliest := start.Add(w.Min)
+	if earliest.Before(now) {
+		earliest = now
+	}
+	latest := start.Add(w.Max)
+	if latest.Before(earliest) {
+		latest = earliest
+	}
+	return &DeliveryEstimate{
+		Status:     StatusInitiated,
+		EarliestAt: &earliest,
+		LatestAt:   &latest,
+	}
 }
 
 // NewTransaction creates a new transaction with default values
 func NewTransaction(userID string, sourceAmount float64, sourceCurrency, targetCurrency string, recipient *RecipientDetails) *Transaction {
-	now := time.Now()
-	return &Transaction{
+	now := Now()
+	tx := &Transaction{
 		ID:               generateTransactionID(),
 		UserID:           userID,
 		SourceAmount:     sourceAmount,
@@ -72,13 +273,26 @@ func NewTransaction(userID string, sourceAmount float64, sourceCurrency, targetC
 		RecipientDetails: recipient,
 		CreatedAt:        now,
 		UpdatedAt:        now,
+		StatusHistory:    []StatusChange{{Status: StatusInitiated, At: now}},
 	}
+	if recipient != nil {
+		tx.RecipientHash = RecipientHash(userID, recipient.BankAccount)
+	}
+	return tx
+}
+
+// RecipientHash returns the recipient index key of a user's transactions to
+// a bank account. It is scoped to the user, so one user's key never matches
+// another's transactions.
+func RecipientHash(userID, bankAccount string) string {
+	sum := sha256.Sum256([]byte(userID + "|" + strings.TrimSpace(bankAccount)))
+	return hex.EncodeToString(sum[:])
 }
 
-// generateTransactionID generates a unique transaction ID
-func generateTransactionID() string {
-	// Implementation using UUID or other unique ID generator
-	return "TXN-" + time.Now().Format("20060102150405")
+// ReassignID gives a transaction that has not been stored yet a fresh ID,
+// after its first one collided with an existing transaction
+func (t *Transaction) ReassignID() {
+	t.ID = generateTransactionID()
 }
 
 // IsCompleted checks if the transaction is completed
@@ -91,31 +305,247 @@ func (t *Transaction) IsFailed() bool {
 	return t.Status == StatusFailed
 }
 
-// UpdateStatus updates the transaction status and updated_at timestamp
+// IsTerminal checks if the transaction has reached a final state
+func (t *Transaction) IsTerminal() bool {
+	return t.IsCompleted() || t.IsFailed()
+}
+
+// Redact erases the recipient's name and account, keeping the bank code and
+// name needed for reconciliation, and records who redacted it and when
+func (t *Transaction) Redact(by string, at time.Time) {
+	if t.RecipientDetails != nil {
+		t.RecipientDetails = &RecipientDetails{
+			BankCode: t.RecipientDetails.BankCode,
+			BankName: t.RecipientDetails.BankName,
+		}
+	}
+	t.RecipientHash = ""
+	t.RedactedAt = &at
+	t.RedactedBy = by
+	t.UpdatedAt = at
+}
+
+// Fail marks the transaction failed and records why
+func (t *Transaction) Fail(reason string) {
+	t.FailureReason = reason
+	t.UpdateStatus(StatusFailed)
+}
+
+// UpdateStatus updates the transaction status and updated_at timestamp,
+// recording the change in the status history
 func (t *Transaction) UpdateStatus(status TransactionStatus) {
+	now := Now()
+	if t.Status != status || len(t.StatusHistory) == 0 {
+		t.StatusHistory = append(t.StatusHistory, StatusChange{Status: status, At: now})
+	}
 	t.Status = status
-	t.UpdatedAt = time.Now()
+	t.UpdatedAt = now
 	if status == StatusCompleted {
-		now := time.Now()
 		t.CompletedAt = &now
 	}
 }
 
+// RateBreakdown returns the rates locked on the transaction. Transactions
+// stored before the margin was recorded have it derived from the two rates.
+func (t *Transaction) RateBreakdown() *RateBreakdown {
+	margin := t.Margin
+	if margin == 0 && t.MidMarketRate > 0 {
+		margin = 1 - t.ExchangeRate/t.MidMarketRate
+	}
+
+	return &RateBreakdown{
+		TransactionID:  t.ID,
+		SourceCurrency: t.SourceCurrency,
+		TargetCurrency: t.TargetCurrency,
+		MidMarketRate:  t.MidMarketRate,
+		MarginPercent:  margin * 100,
+		CustomerRate:   t.ExchangeRate,
+		RateSource:     t.RateSource,
+		RateFetchedAt:  t.RateFetchedAt,
+		RateLockedAt:   t.RateLockedAt,
+	}
+}
+
+// RoundRates rounds the rates for display, see RoundRate
+func (b *RateBreakdown) RoundRates(decimals int) {
+	b.MidMarketRate = RoundRate(b.MidMarketRate, decimals)
+	b.CustomerRate = RoundRate(b.CustomerRate, decimals)
+}
+
+// Timeline assembles the status history, payment and transfer milestones
+// into a single chronological list
+func (t *Transaction) Timeline() []TimelineEntry {
+	var entries []TimelineEntry
+
+	for _, change := range t.StatusHistory {
+		entries = append(entries, TimelineEntry{
+			At:          change.At,
+			Type:        TimelineStatus,
+			Status:      change.Status,
+			Description: statusDescriptions[change.Status],
+		})
+
+		if change.Status == StatusProcessing && t.TransferID != "" {
+			entries = append(entries, TimelineEntry{
+				At:          change.At,
+				Type:        TimelineTransfer,
+				Description: "Transfer " + t.TransferID + " created with Wise",
+			})
+		}
+	}
+
+	if t.PaymentDetails != nil && t.PaymentDetails.PaidAt != nil {
+		entries = append(entries, TimelineEntry{
+			At:          *t.PaymentDetails.PaidAt,
+			Type:        TimelinePayment,
+			Description: "Payment " + t.PaymentDetails.PaymentID + " received",
+		})
+	}
+
+	if t.CompletedAt != nil && t.TransferID != "" {
+		entries = append(entries, TimelineEntry{
+			At:          *t.CompletedAt,
+			Type:        TimelineTransfer,
+			Description: "Transfer " + t.TransferID + " delivered to recipient",
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].At.Before(entries[j].At)
+	})
+
+	return entries
+}
+
+var statusDescriptions = map[TransactionStatus]string{
+	StatusInitiated:       "Transaction created and exchange rate locked",
+	StatusPaymentPending:  "Payment link generated, awaiting payment",
+	StatusPaymentReceived: "Payment received",
+	StatusProcessing:      "Transfer to recipient in progress",
+	StatusCompleted:       "Funds delivered to recipient",
+	StatusFailed:          "Transaction failed",
+	StatusPaymentMismatch: "Paid amount does not match, held for manual review",
+	StatusPendingReview:   "Held for compliance review before payment",
+}
+
+// EstimateDelivery computes the delivery window for the transaction based on
+// its current status. The window starts once payment is received; until then
+// it is projected from now.
+func (t *Transaction) EstimateDelivery(window DeliveryWindow, now time.Time) *DeliveryEstimate {
+	switch t.Status {
+	case StatusCompleted:
+		return &DeliveryEstimate{Status: t.Status, DeliveredAt: t.CompletedAt}
+	case StatusFailed:
+		return &DeliveryEstimate{Status: t.Status}
+	case StatusPaymentReceived, StatusProcessing:
+		start := t.UpdatedAt
+		if t.PaymentDetails != nil && t.PaymentDetails.PaidAt != nil {
+			start = *t.PaymentDetails.PaidAt
+		}
+		estimate := window.Estimate(start, now)
+		estimate.Status = t.Status
+		return estimate
+	default:
+		estimate := window.Estimate(now, now)
+		estimate.Status = t.Status
+		return estimate
+	}
+}
+
+// FormatAmounts fills SourceDisplay and TargetDisplay
+func (t *Transaction) FormatAmounts() {
+	t.SourceDisplay = FormatAmount(t.SourceAmount, t.SourceCurrency)
+	t.TargetDisplay = FormatAmount(t.TargetAmount, t.TargetCurrency)
+}
+
 // SetPaymentDetails updates the payment details for the transaction
 func (t *Transaction) SetPaymentDetails(details *PaymentDetails) {
 	t.PaymentDetails = details
-	t.UpdatedAt = time.Now()
+	t.UpdatedAt = Now()
 }
 
 // SetExchangeRate sets the exchange rate and calculates the target amount
-func (t *Transaction) SetExchangeRate(rate float64) {
+// from the amount converted under model. Fees must be set first.
+func (t *Transaction) SetExchangeRate(rate float64, model FeeModel) {
+	now := Now()
 	t.ExchangeRate = rate
-	t.TargetAmount = t.SourceAmount * rate
-	t.UpdatedAt = time.Now()
+	t.TargetAmount = t.NetAmount(model) * rate
+	t.RateLockedAt = now
+	t.UpdatedAt = now
+}
+
+// RoundRates rounds the rates for display, see RoundRate. Amounts already
+// derived from them keep their full precision.
+func (t *Transaction) RoundRates(decimals int) {
+	t.ExchangeRate = RoundRate(t.ExchangeRate, decimals)
+	t.MidMarketRate = RoundRate(t.MidMarketRate, decimals)
+}
+
+// SetRates locks the customer exchange rate along with the mid-market rate
+// and margin it was derived from, and discloses the spread between them on
+// the fees. Both apply to the amount converted under model.
+func (t *Transaction) SetRates(midMarketRate, customerRate, margin float64, model FeeModel) {
+	t.MidMarketRate = midMarketRate
+	t.Margin = margin
+	t.SetExchangeRate(customerRate, model)
+	if t.Fees != nil {
+		t.Fees.FXSpread = FXSpread(t.NetAmount(model), midMarketRate, customerRate)
+	}
+}
+
+// SetRateSource records where and when the mid-market rate was obtained
+func (t *Transaction) SetRateSource(rate *ExchangeRate) {
+	fetchedAt := rate.FetchedAt
+	t.RateSource = rate.Provider
+	t.RateFetchedAt = &fetchedAt
+}
+
+// RateAge returns how long ago the exchange rate was locked
+func (t *Transaction) RateAge(now time.Time) time.Duration {
+	lockedAt := t.RateLockedAt
+	if lockedAt.IsZero() {
+		lockedAt = t.CreatedAt
+	}
+	return now.Sub(lockedAt)
+}
+
+// FeeModel says how fees relate to the source amount
+type FeeModel string
+
+const (
+	FeeModelExclusive FeeModel = "exclusive" // fees are charged on top of the source amount
+	FeeModelInclusive FeeModel = "inclusive" // fees are taken out of the source amount
+)
+
+// CollectibleAmount returns what the sender pays under model, rounded to
+// the source currency's minor units: the source amount plus fees when they
+// are exclusive, the source amount alone when they are inclusive
+func (t *Transaction) CollectibleAmount(model FeeModel) float64 {
+	amount := t.SourceAmount
+	if model != FeeModelInclusive && t.Fees != nil {
+		amount += t.Fees.TotalFee
+	}
+	return RoundAmount(amount, t.SourceCurrency)
+}
+
+// NetAmount returns the source amount converted under model, see
+// NetSourceAmount
+func (t *Transaction) NetAmount(model FeeModel) float64 {
+	return NetSourceAmount(t.SourceAmount, t.Fees, model, t.SourceCurrency)
+}
+
+// NetSourceAmount returns the part of amount that is converted under model,
+// rounded to the currency's minor units: all of it when fees are exclusive
+// and charged on top, what is left after fees when they are inclusive
+func NetSourceAmount(amount float64, fees *Fees, model FeeModel, currency string) float64 {
+	if model == FeeModelInclusive && fees != nil {
+		amount -= fees.TotalFee
+	}
+	return RoundAmount(amount, currency)
 }
 
 // SetFees sets the fee structure for the transaction
 func (t *Transaction) SetFees(fees *Fees) {
 	t.Fees = fees
-	t.UpdatedAt = time.Now()
+	t.UpdatedAt = Now()
 }
@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateDelivery(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	window := DeliveryWindow{Min: time.Hour, Max: 24 * time.Hour}
+	paid := now.Add(-2 * time.Hour)
+	completed := now.Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		status        TransactionStatus
+		paidAt        *time.Time
+		wantEarliest  time.Time
+		wantLatest    time.Time
+		wantDelivered *time.Time
+	}{
+		{"unpaid is projected from now", StatusPaymentPending, nil, now.Add(time.Hour), now.Add(24 * time.Hour), nil},
+		{"paid starts at payment", StatusProcessing, &paid, now, paid.Add(24 * time.Hour), nil},
+		{"completed reports delivery", StatusCompleted, &paid, time.Time{}, time.Time{}, &completed},
+		{"failed has no window", StatusFailed, &paid, time.Time{}, time.Time{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := NewTransaction("user-1", 10000, "INR", "CAD", &RecipientDetails{})
+			tx.Status = tt.status
+			tx.PaymentDetails = &PaymentDetails{PaidAt: tt.paidAt}
+			if tt.status == StatusCompleted {
+				tx.CompletedAt = &completed
+			}
+
+			got := tx.EstimateDelivery(window, now)
+			if got.Status != tt.status {
+				t.Errorf("Status = %s, want %s", got.Status, tt.status)
+			}
+			if !sameTime(got.EarliestAt, tt.wantEarliest) || !sameTime(got.LatestAt, tt.wantLatest) {
+				t.Errorf("window = %v to %v, want %v to %v", got.EarliestAt, got.LatestAt, tt.wantEarliest, tt.wantLatest)
+			}
+			if !sameTime(got.DeliveredAt, derefTime(tt.wantDelivered)) {
+				t.Errorf("DeliveredAt = %v, want %v", got.DeliveredAt, tt.wantDelivered)
+			}
+		})
+	}
+}
+
+// sameTime reports whether got is want, a nil got standing for the zero time
+func sameTime(got *time.Time, want time.Time) bool {
+	if got == nil {
+		return want.IsZero()
+	}
+	return got.Equal(want)
+}
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	paidAt, completedAt := at(3), at(30)
+
+	tx := NewTransaction("user-1", 10000, "INR", "CAD", &RecipientDetails{})
+	tx.TransferID = "TR-1"
+	tx.StatusHistory = []StatusChange{
+		{Status: StatusInitiated, At: at(0)},
+		{Status: StatusPaymentPending, At: at(1)},
+		{Status: StatusPaymentReceived, At: at(4)},
+		{Status: StatusProcessing, At: at(5)},
+		{Status: StatusCompleted, At: at(30)},
+	}
+	tx.PaymentDetails = &PaymentDetails{PaymentID: "PAY-1", PaidAt: &paidAt}
+	tx.CompletedAt = &completedAt
+
+	want := []struct {
+		at     time.Time
+		typ    string
+		status TransactionStatus
+	}{
+		{at(0), TimelineStatus, StatusInitiated},
+		{at(1), TimelineStatus, StatusPaymentPending},
+		{at(3), TimelinePayment, ""},
+		{at(4), TimelineStatus, StatusPaymentReceived},
+		{at(5), TimelineStatus, StatusProcessing},
+		{at(5), TimelineTransfer, ""},
+		{at(30), TimelineStatus, StatusCompleted},
+		{at(30), TimelineTransfer, ""},
+	}
+
+	got := tx.Timeline()
+	if len(got) != len(want) {
+		t.Fatalf("Timeline() has %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if !got[i].At.Equal(w.at) || got[i].Type != w.typ || got[i].Status != w.status {
+			t.Errorf("entry %d = %s %s %s, want %s %s %s", i, got[i].At, got[i].Type, got[i].Status, w.at, w.typ, w.status)
+		}
+		if got[i].Description == "" {
+			t.Errorf("entry %d has no description", i)
+		}
+	}
+}
+
+func TestUpdateStatusRecordsHistory(t *testing.T) {
+	tx := NewTransaction("user-1", 10000, "INR", "CAD", &RecipientDetails{})
+	tx.UpdateStatus(StatusPaymentPending)
+	tx.UpdateStatus(StatusPaymentPending) // unchanged, not recorded again
+	tx.UpdateStatus(StatusCompleted)
+
+	var statuses []TransactionStatus
+	for _, change := range tx.StatusHistory {
+		statuses = append(statuses, change.Status)
+	}
+	want := []TransactionStatus{StatusInitiated, StatusPaymentPending, StatusCompleted}
+	if len(statuses) != len(want) {
+		t.Fatalf("history = %v, want %v", statuses, want)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("history = %v, want %v", statuses, want)
+		}
+	}
+	if tx.CompletedAt == nil {
+		t.Error("CompletedAt not set on completion")
+	}
+}
+
+func TestRecipientHash(t *testing.T) {
+	base := RecipientHash("user-1", "12345678")
+
+	tests := []struct {
+		name      string
+		userID    string
+		account   string
+		wantEqual bool
+	}{
+		{"same user and account", "user-1", "12345678", true},
+		{"surrounding spaces", "user-1", " 12345678 ", true},
+		{"other account", "user-1", "87654321", false},
+		{"other user", "user-2", "12345678", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RecipientHash(tt.userID, tt.account); (got == base) != tt.wantEqual {
+				t.Errorf("RecipientHash(%q, %q) = %s, base %s; want equal %v", tt.userID, tt.account, got, base, tt.wantEqual)
+			}
+		})
+	}
+
+	if tx := NewTransaction("user-1", 10000, "INR", "CAD", &RecipientDetails{BankAccount: "12345678"}); tx.RecipientHash != base {
+		t.Errorf("NewTransaction() recipient hash = %q, want %q", tx.RecipientHash, base)
+	}
+	if strings.Contains(base, "12345678") {
+		t.Errorf("RecipientHash() = %s contains the account number", base)
+	}
+}
+
+func TestRateBreakdown(t *testing.T) {
+	tests := []struct {
+		name       string
+		mid        float64
+		customer   float64
+		margin     float64
+		wantMargin float64 // percent
+	}{
+		{"stored margin", 0.016, 0.01592, 0.005, 0.5},
+		{"derived from the rates", 0.016, 0.01592, 0, 0.5},
+		{"no margin", 0.016, 0.016, 0, 0},
+		{"no mid-market rate", 0, 0.016, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := NewTransaction("user-1", 10000, "INR", "CAD", &RecipientDetails{})
+			tx.SetRates(tt.mid, tt.customer, tt.margin, FeeModelExclusive)
+			tx.RateSource = "ad_bank"
+
+			got := tx.RateBreakdown()
+			if got.TransactionID != tx.ID || got.SourceCurrency != "INR" || got.TargetCurrency != "CAD" {
+				t.Errorf("breakdown = %+v, want it for %s INR/CAD", got, tx.ID)
+			}
+			if got.MidMarketRate != tt.mid || got.CustomerRate != tt.customer || got.RateSource != "ad_bank" {
+				t.Errorf("rates = %v mid, %v customer from %q; want %v, %v from ad_bank", got.MidMarketRate, got.CustomerRate, got.RateSource, tt.mid, tt.customer)
+			}
+			if math.Abs(got.MarginPercent-tt.wantMargin) > 1e-9 {
+				t.Errorf("MarginPercent = %v, want %v", got.MarginPercent, tt.wantMargin)
+			}
+			if !got.RateLockedAt.Equal(tx.RateLockedAt) {
+				t.Errorf("RateLockedAt = %v, want %v", got.RateLockedAt, tx.RateLockedAt)
+			}
+		})
+	}
+}
+
+func TestHighPrecisionRate(t *testing.T) {
+	const mid, customer = 0.01612374, 0.01604312
+	tests := []struct {
+		name       string
+		model      FeeModel
+		wantTarget float64
+	}{
+		{"exclusive", FeeModelExclusive, 10000 * customer},
+		{"inclusive", FeeModelInclusive, 9850 * customer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := NewTransaction("user-1", 10000, "INR", "CAD", &RecipientDetails{})
+			tx.SetFees(&Fees{BaseFee: 50, VariableFee: 100, TotalFee: 150})
+			tx.SetRates(mid, customer, 0.005, tt.model)
+
+			if tx.ExchangeRate != customer || tx.MidMarketRate != mid {
+				t.Errorf("rates = %v mid, %v customer; want %v, %v", tx.MidMarketRate, tx.ExchangeRate, mid, customer)
+			}
+			if math.Abs(tx.TargetAmount-tt.wantTarget) > 1e-9 {
+				t.Errorf("TargetAmount = %v, want %v", tx.TargetAmount, tt.wantTarget)
+			}
+
+			// Rounding for display leaves the amounts derived from the rate
+			target := tx.TargetAmount
+			tx.RoundRates(4)
+			if tx.ExchangeRate != 0.016 || tx.MidMarketRate != 0.0161 || tx.TargetAmount != target {
+				t.Errorf("after RoundRates(4): rates %v, %v, target %v; want 0.016, 0.0161, %v", tx.ExchangeRate, tx.MidMarketRate, tx.TargetAmount, target)
+			}
+		})
+	}
+}
+
+func TestNetAmount(t *testing.T) {
+	fees := &Fees{BaseFee: 50, VariableFee: 100, TotalFee: 150}
+
+	tests := []struct {
+		name  string
+		fees  *Fees
+		model FeeModel
+		want  float64
+	}{
+		{"exclusive converts the whole amount", fees, FeeModelExclusive, 10000},
+		{"unset model is exclusive", fees, "", 10000},
+		{"inclusive deducts fees", fees, FeeModelInclusive, 9850},
+		{"inclusive without fees", nil, FeeModelInclusive, 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := NewTransaction("user-1", 10000, "INR", "CAD", &RecipientDetails{})
+			tx.SetFees(tt.fees)
+			if got := tx.NetAmount(tt.model); got != tt.want {
+				t.Fatalf("NetAmount() = %v, want %v", got, tt.want)
+			}
+
+			// The target amount and spread follow the amount converted
+			tx.SetRates(0.0165, 0.016, 0.03, tt.model)
+			if want := tt.want * 0.016; math.Abs(tx.TargetAmount-want) > 1e-9 {
+				t.Errorf("TargetAmount = %v, want %v", tx.TargetAmount, want)
+			}
+			if tt.fees != nil {
+				if want := tt.want * 0.0005; math.Abs(tx.Fees.FXSpread-want) > 1e-9 {
+					t.Errorf("FXSpread = %v, want %v", tx.Fees.FXSpread, want)
+				}
+			}
+
+			// What the sender pays and what is converted differ by the
+			// fees under exactly one of the models
+			if tt.fees != nil {
+				if diff := tx.CollectibleAmount(tt.model) - tx.NetAmount(tt.model); diff != tt.fees.TotalFee {
+					t.Errorf("collectible - net = %v, want the fees %v", diff, tt.fees.TotalFee)
+				}
+			}
+		})
+	}
+}
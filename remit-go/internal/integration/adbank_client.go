@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
@@ -18,12 +19,28 @@ type adBankClient struct {
 	rateCache     map[string]float64
 	rateCacheMu   sync.RWMutex
 	lastRateCheck time.Time
+
+	// Cache for bank names, which do not change
+	bankNames   map[string]string
+	bankNamesMu sync.RWMutex
+
+	// Cache of accounts found valid, by bank code and account number
+	validAccounts   map[string]cachedAccount
+	validAccountsMu sync.Mutex
+}
+
+// cachedAccount is a valid account's validation and when it expires
+type cachedAccount struct {
+	validation AccountValidation
+	expiresAt  time.Time
 }
 
 // NewADBankClient creates a new AD Bank API client
 func NewADBankClient(cfg config.ADBankConfig) ADBankClient {
+	// Operations are bounded by their own timeouts; the client only stops
+	// a request outliving the longest of them
 	client := &http.Client{
-		Timeout: cfg.Timeout,
+		Timeout: max(cfg.Timeout, cfg.RateTimeout, cfg.ValidationTimeout),
 	}
 
 	return &adBankClient{
@@ -31,6 +48,9 @@ func NewADBankClient(cfg config.ADBankConfig) ADBankClient {
 		config:    cfg,
 		baseURL:   cfg.Endpoint,
 		rateCache: make(map[string]float64),
+		bankNames: make(map[string]string),
+
+		validAccounts: make(map[string]cachedAccount),
 	}
 }
 
@@ -46,22 +66,102 @@ func (c *adBankClient) GetExchangeRate(ctx context.Context, sourceCurrency, targ
 	}
 	c.rateCacheMu.RUnlock()
 
-	// Implementation would make an HTTP request to get current rates
+	ctx, cancel := operationContext(ctx, c.config.RateTimeout, c.config.Timeout)
+	defer cancel()
+
+	// Implementation would make an HTTP request with ctx to get current rates
 	// This is a mock implementation
 	c.rateCacheMu.Lock()
 	defer c.rateCacheMu.Unlock()
 
-	// Mock exchange rate: 1 INR = 0.016 CAD
-	rate := 0.016
+	// Mock exchange rate: 1 INR = 0.0160473 CAD, at the precision a live
+	// provider quotes, which is kept as is
+	rate := 0.0160473
 	c.rateCache[sourceCurrency+targetCurrency] = rate
 	c.lastRateCheck = time.Now()
 
 	return rate, nil
 }
 
-// ValidateAccount validates a bank account
-func (c *adBankClient) ValidateAccount(ctx context.Context, bankCode, accountNumber string) (bool, error) {
-	// Implementation would make an HTTP request to validate account
+// ValidateAccount validates a bank account. An account found valid is not
+// checked again for AccountValidationTTL; invalid accounts and failed
+// checks are never cached.
+func (c *adBankClient) ValidateAccount(ctx context.Context, bankCode, accountNumber string) (*AccountValidation, error) {
+	key := bankCode + "/" + accountNumber
+	now := time.Now()
+
+	c.validAccountsMu.Lock()
+	cached, ok := c.validAccounts[key]
+	if ok && now.After(cached.expiresAt) {
+		delete(c.validAccounts, key)
+		ok = false
+	}
+	c.validAccountsMu.Unlock()
+	if ok {
+		validation := cached.validation
+		return &validation, nil
+	}
+
+	ctx, cancel := operationContext(ctx, c.config.ValidationTimeout, c.config.Timeout)
+	defer cancel()
+
+	// Implementation would make an HTTP request with ctx to validate account
+	// This is a mock implementation; the mock bank does not report the
+	// account currency
+	validation := AccountValidation{Valid: true}
+
+	c.rememberAccount(key, validation, now)
+	return &validation, nil
+}
+
+// rememberAccount caches a validation made at now if the account was found
+// valid and caching is on
+func (c *adBankClient) rememberAccount(key string, validation AccountValidation, now time.Time) {
+	if !validation.Valid || c.config.AccountValidationTTL <= 0 {
+		return
+	}
+	c.validAccountsMu.Lock()
+	c.validAccounts[key] = cachedAccount{validation: validation, expiresAt: now.Add(c.config.AccountValidationTTL)}
+	c.validAccountsMu.Unlock()
+}
+
+// mockBanks maps the bank prefix of an IFSC to the bank's name
+var mockBanks = map[string]string{
+	"HDFC": "HDFC Bank",
+	"ICIC": "ICICI Bank",
+	"SBIN": "State Bank of India",
+	"UTIB": "Axis Bank",
+	"ADBK": "AD Bank",
+}
+
+// LookupBank returns the name of the bank a bank code belongs to
+func (c *adBankClient) LookupBank(ctx context.Context, bankCode string) (string, error) {
+	// Check cache first
+	c.bankNamesMu.RLock()
+	name, ok := c.bankNames[bankCode]
+	c.bankNamesMu.RUnlock()
+	if ok {
+		return name, nil
+	}
+
+	// Implementation would make an HTTP request to look up the bank code
 	// This is a mock implementation
-	return true, nil
+	if len(bankCode) < 4 {
+		return "", fmt.Errorf("unknown bank code %q", bankCode)
+	}
+	name, ok = mockBanks[bankCode[:4]]
+	if !ok {
+		return "", fmt.Errorf("unknown bank code %q", bankCode)
+	}
+
+	c.bankNamesMu.Lock()
+	c.bankNames[bankCode] = name
+	c.bankNamesMu.Unlock()
+
+	return name, nil
+}
+
+// Ping checks the AD Bank API is reachable
+func (c *adBankClient) Ping(ctx context.Context) error {
+	return ping(ctx, c.client, c.baseURL)
 }
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+func TestLookupBank(t *testing.T) {
+	tests := []struct {
+		bankCode string
+		want     string
+		wantErr  bool
+	}{
+		{"HDFC0001234", "HDFC Bank", false},
+		{"SBIN0005943", "State Bank of India", false},
+		{"XXXX0000001", "", true},
+		{"HDF", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bankCode, func(t *testing.T) {
+			client := NewADBankClient(config.ADBankConfig{}).(*adBankClient)
+
+			name, err := client.LookupBank(context.Background(), tt.bankCode)
+			if (err != nil) != tt.wantErr || name != tt.want {
+				t.Fatalf("LookupBank(%q) = %q, %v; want %q, error %v", tt.bankCode, name, err, tt.want, tt.wantErr)
+			}
+			if _, cached := client.bankNames[tt.bankCode]; cached == tt.wantErr {
+				t.Errorf("cached = %v, want only found names cached", cached)
+			}
+		})
+	}
+}
+
+func TestLookupBankCached(t *testing.T) {
+	client := NewADBankClient(config.ADBankConfig{}).(*adBankClient)
+	if _, err := client.LookupBank(context.Background(), "HDFC0001234"); err != nil {
+		t.Fatalf("LookupBank() = %v", err)
+	}
+
+	// A renamed bank keeps the cached name: lookups are not repeated
+	client.bankNames["HDFC0001234"] = "HDFC Bank Ltd"
+	if name, err := client.LookupBank(context.Background(), "HDFC0001234"); err != nil || name != "HDFC Bank Ltd" {
+		t.Errorf("LookupBank() = %q, %v; want the cached name", name, err)
+	}
+}
+
+func TestValidateAccountCache(t *testing.T) {
+	const key = "TD001/12345678"
+
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		cached     *cachedAccount // entry present before the call
+		want       string         // currency of the validation returned
+		wantCached bool
+	}{
+		{"miss", time.Minute, nil, "", true},
+		{"hit", time.Minute, &cachedAccount{AccountValidation{Valid: true, Currency: "CAD"}, time.Now().Add(time.Minute)}, "CAD", true},
+		{"expired", time.Minute, &cachedAccount{AccountValidation{Valid: true, Currency: "CAD"}, time.Now().Add(-time.Second)}, "", true},
+		{"caching off", 0, nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewADBankClient(config.ADBankConfig{AccountValidationTTL: tt.ttl}).(*adBankClient)
+			if tt.cached != nil {
+				client.validAccounts[key] = *tt.cached
+			}
+
+			validation, err := client.ValidateAccount(context.Background(), "TD001", "12345678")
+			if err != nil || !validation.Valid || validation.Currency != tt.want {
+				t.Fatalf("ValidateAccount() = %+v, %v; want valid in %q", validation, err, tt.want)
+			}
+			entry, cached := client.validAccounts[key]
+			if cached != tt.wantCached {
+				t.Fatalf("cached = %v, want %v", cached, tt.wantCached)
+			}
+			if cached && !entry.expiresAt.After(time.Now()) {
+				t.Errorf("cache entry expires at %v, want in the future", entry.expiresAt)
+			}
+		})
+	}
+}
+
+func TestRememberAccountSkipsInvalid(t *testing.T) {
+	client := NewADBankClient(config.ADBankConfig{AccountValidationTTL: time.Minute}).(*adBankClient)
+
+	client.rememberAccount("TD001/12345678", AccountValidation{Valid: false}, time.Now())
+	if _, cached := client.validAccounts["TD001/12345678"]; cached {
+		t.Error("invalid account cached")
+	}
+}
+
+func TestValidateAccountConcurrent(t *testing.T) {
+	client := NewADBankClient(config.ADBankConfig{AccountValidationTTL: time.Minute}).(*adBankClient)
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ValidateAccount(context.Background(), "TD001", fmt.Sprint(i%4)); err != nil {
+				t.Errorf("ValidateAccount() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(client.validAccounts); n != 4 {
+		t.Errorf("cached accounts = %d, want 4", n)
+	}
+}
@@ -0,0 +1,36 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+type bankTransferClient struct {
+	config config.BankTransferConfig
+}
+
+// NewBankTransferClient creates a provider for funding by bank transfer
+// (NEFT/IMPS) into the collection account
+func NewBankTransferClient(cfg config.BankTransferConfig) PaymentProvider {
+	return &bankTransferClient{config: cfg}
+}
+
+// GeneratePaymentLink returns the transfer instructions as a link: the
+// collection account, IFSC, amount and the reference the sender must quote
+// so the incoming transfer can be matched to the transaction
+func (c *bankTransferClient) GeneratePaymentLink(ctx context.Context, txID string, amount float64) (string, error) {
+	q := url.Values{}
+	q.Set("account", c.config.AccountNumber)
+	q.Set("ifsc", c.config.IFSC)
+	q.Set("amount", fmt.Sprintf("%.2f", amount))
+	q.Set("reference", txID)
+	return "banktransfer://pay?" + q.Encode(), nil
+}
+
+// Ping always succeeds; bank transfer instructions need no upstream call
+func (c *bankTransferClient) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+type cardClient struct {
+	client  *http.Client
+	config  config.CardConfig
+	baseURL string
+}
+
+// NewCardClient creates a new card payment gateway client
+func NewCardClient(cfg config.CardConfig) PaymentProvider {
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+	}
+
+	return &cardClient{
+		client:  client,
+		config:  cfg,
+		baseURL: cfg.Endpoint,
+	}
+}
+
+// GeneratePaymentLink creates a hosted card checkout page for the payment
+func (c *cardClient) GeneratePaymentLink(ctx context.Context, txID string, amount float64) (string, error) {
+	// Implementation would make an HTTP request to create a checkout session
+	// This is a mock implementation
+	return fmt.Sprintf("%s/checkout/%s?amount=%.2f", c.baseURL, txID, amount), nil
+}
+
+// Ping checks the card gateway is reachable
+func (c *cardClient) Ping(ctx context.Context) error {
+	return ping(ctx, c.client, c.baseURL)
+}
@@ -4,24 +4,66 @@ import (
 	"context"
 )
 
+// PaymentProvider collects the source funds for one payment method. The
+// returned link is the artifact the sender uses to pay: a UPI intent, a card
+// checkout page or bank transfer instructions.
+type PaymentProvider interface {
+	Pinger
+	GeneratePaymentLink(ctx context.Context, txID string, amount float64) (string, error)
+}
+
 // UPIClient defines the interface for UPI payment gateway
 type UPIClient interface {
-	GeneratePaymentLink(ctx context.Context, txID string, amount float64) (string, error)
+	PaymentProvider
 	VerifyPayment(ctx context.Context, paymentID string) (string, error)
 }
 
 // ADBankClient defines the interface for AD Bank API
 type ADBankClient interface {
+	Pinger
 	GetExchangeRate(ctx context.Context, sourceCurrency, targetCurrency string) (float64, error)
-	ValidateAccount(ctx context.Context, bankCode, accountNumber string) (bool, error)
+	ValidateAccount(ctx context.Context, bankCode, accountNumber string) (*AccountValidation, error)
+	LookupBank(ctx context.Context, bankCode string) (string, error)
+}
+
+// AccountValidation is AD Bank's verdict on a recipient account
+type AccountValidation struct {
+	Valid    bool
+	Currency string // currency the account is held in; empty when the bank does not say
 }
 
 // WiseClient defines the interface for Wise API
 type WiseClient interface {
+	Pinger
 	CreateTransfer(ctx context.Context, req *WiseTransferRequest) (string, error)
 	GetTransferStatus(ctx context.Context, transferID string) (string, error)
 }
 
+// Notifier delivers messages to users
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// Notification is a message for a user about one of their transactions
+type Notification struct {
+	UserID        string
+	TransactionID string
+	Kind          string // what the message is about, e.g. payment_reminder
+	Message       string
+}
+
+// PaymentInspector is implemented by UPI clients that can return the
+// provider's payment record as received, for diagnostics
+type PaymentInspector interface {
+	InspectPayment(ctx context.Context, paymentID string) (map[string]interface{}, error)
+}
+
+// TransferInspector is implemented by Wise clients that can return the
+// provider's transfer record as received, for diagnostics
+type TransferInspector interface {
+	InspectTransfer(ctx context.Context, transferID string) (map[string]interface{}, error)
+}
+
 // WiseTransferRequest represents a transfer request to Wise
 type WiseTransferRequest struct {
 	SourceAmount   float64 `json:"source_amount"`
@@ -30,4 +72,13 @@ type WiseTransferRequest struct {
 	RecipientName  string  `json:"recipient_name"`
 	BankAccount    string  `json:"bank_account"`
 	BankCode       string  `json:"bank_code"`
+
+	// CustomerReference is shown on the transfer in the Wise dashboard; we
+	// set our transaction ID so a transfer can be traced back to it
+	CustomerReference string `json:"customer_reference,omitempty"`
+
+	// CustomerTransactionID is Wise's idempotency key: a repeated request
+	// with the same value returns the transfer already created rather than
+	// paying out again. Without it a timed-out request is not retried.
+	CustomerTransactionID string `json:"customerTransactionId,omitempty"`
 }
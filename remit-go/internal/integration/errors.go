@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Wise error codes that can never succeed on retry. Used when no terminal
+// codes are configured.
+var defaultTerminalTransferCodes = []string{
+	"insufficient_funds",
+	"invalid_recipient",
+	"invalid_account",
+	"compliance_rejected",
+}
+
+// TransferError is a classified failure from the transfer provider
+type TransferError struct {
+	Code      string // provider error code, e.g. "insufficient_funds"
+	Retryable bool
+	Err       error
+}
+
+func (e *TransferError) Error() string {
+	kind := "terminal"
+	if e.Retryable {
+		kind = "retryable"
+	}
+	if e.Err == nil {
+		return fmt.Sprintf("%s transfer error: %s", kind, e.Code)
+	}
+	return fmt.Sprintf("%s transfer error: %s: %v", kind, e.Code, e.Err)
+}
+
+func (e *TransferError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether err is a transfer failure worth retrying.
+// Unclassified errors are treated as terminal.
+func IsRetryable(err error) bool {
+	var te *TransferError
+	return errors.As(err, &te) && te.Retryable
+}
+
+// FailureReason returns the provider error code behind err, or a generic
+// reason when err is unclassified
+func FailureReason(err error) string {
+	var te *TransferError
+	if errors.As(err, &te) && te.Code != "" {
+		return te.Code
+	}
+	return "transfer_failed"
+}
@@ -0,0 +1,20 @@
+package integration
+
+import (
+	"context"
+	"log"
+)
+
+type logNotifier struct{}
+
+// NewLogNotifier creates a notifier that only logs its notifications, for
+// deployments without a messaging provider
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+// Notify logs the notification
+func (logNotifier) Notify(ctx context.Context, n *Notification) error {
+	log.Printf("notification: kind=%s user_id=%s transaction_id=%s message=%q", n.Kind, n.UserID, n.TransactionID, n.Message)
+	return nil
+}
@@ -0,0 +1,39 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// pingTimeout bounds a health probe regardless of the client's own timeout
+const pingTimeout = 2 * time.Second
+
+// Pinger is implemented by clients that can cheaply probe their upstream
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// ping sends a HEAD request to baseURL. Any response below 500 means the
+// provider is reachable; authentication or routing errors still prove it is up.
+func ping(ctx context.Context, client *http.Client, baseURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build ping request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", baseURL, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%s answered %d", baseURL, resp.StatusCode)
+	}
+	return nil
+}
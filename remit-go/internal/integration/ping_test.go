@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		down    bool
+		wantErr bool
+	}{
+		{"ok", http.StatusOK, false, false},
+		{"unauthorized still up", http.StatusUnauthorized, false, false},
+		{"not found still up", http.StatusNotFound, false, false},
+		{"server error", http.StatusServiceUnavailable, false, true},
+		{"unreachable", 0, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodHead {
+					t.Errorf("method = %s, want HEAD", r.Method)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			if tt.down {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			err := ping(context.Background(), server.Client(), server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ping() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package integration
+
+import "strings"
+
+// redactedValue replaces sensitive values in provider payloads
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys are substrings of payload keys whose values identify people
+// or accounts, or grant access
+var sensitiveKeys = []string{
+	"account", "iban", "ifsc", "vpa", "name", "email", "phone",
+	"address", "token", "secret", "signature", "card",
+}
+
+// Redact returns a copy of a decoded provider payload with the values of
+// sensitive keys replaced, at any depth
+func Redact(payload map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(payload))
+	for key, value := range payload {
+		if isSensitiveKey(key) {
+			out[key] = redactedValue
+			continue
+		}
+		out[key] = redactValue(value)
+	}
+	return out
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Redact(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]interface{}
+		want    map[string]interface{}
+	}{
+		{
+			"top-level keys",
+			map[string]interface{}{"id": "TR-1", "status": "outgoing_payment_sent", "payee_vpa": "remit@okbank", "Email": "jane@example.com"},
+			map[string]interface{}{"id": "TR-1", "status": "outgoing_payment_sent", "payee_vpa": redactedValue, "Email": redactedValue},
+		},
+		{
+			"nested objects",
+			map[string]interface{}{"recipient": map[string]interface{}{"accountHolderName": "Jane Doe", "currency": "CAD"}},
+			map[string]interface{}{"recipient": map[string]interface{}{"accountHolderName": redactedValue, "currency": "CAD"}},
+		},
+		{
+			"objects in lists",
+			map[string]interface{}{"events": []interface{}{map[string]interface{}{"token": "tok_1", "state": "done"}, "raw"}},
+			map[string]interface{}{"events": []interface{}{map[string]interface{}{"token": redactedValue, "state": "done"}, "raw"}},
+		},
+		{
+			"sensitive object redacted whole",
+			map[string]interface{}{"card": map[string]interface{}{"last4": "4242"}},
+			map[string]interface{}{"card": redactedValue},
+		},
+		{"empty", map[string]interface{}{}, map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.payload); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package integration
+
+import (
+	"context"
+	"time"
+)
+
+// operationContext bounds one provider call by the operation's timeout, or
+// by the client's timeout when the operation has none. With neither set ctx
+// is returned as is.
+func operationContext(ctx context.Context, timeout, fallback time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = fallback
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+func TestOperationContext(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		fallback     time.Duration
+		wantDeadline time.Duration // zero for none
+	}{
+		{"operation timeout", time.Second, time.Minute, time.Second},
+		{"falls back to the client timeout", 0, time.Minute, time.Minute},
+		{"longer than the client timeout", time.Minute, time.Second, time.Minute},
+		{"neither set", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			ctx, cancel := operationContext(context.Background(), tt.timeout, tt.fallback)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != (tt.wantDeadline > 0) {
+				t.Fatalf("deadline set = %v, want %v", ok, tt.wantDeadline > 0)
+			}
+			if !ok {
+				return
+			}
+			if got := deadline.Sub(start); got < tt.wantDeadline || got > tt.wantDeadline+time.Second {
+				t.Errorf("deadline in %v, want %v", got, tt.wantDeadline)
+			}
+		})
+	}
+}
+
+func TestWiseOperationTimeouts(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 468956, "status": "outgoing_payment_sent"})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		transfer    time.Duration
+		status      time.Duration
+		wantTimeout map[string]bool // by operation
+	}{
+		{"client timeout for both", time.Second, 0, 0, map[string]bool{"transfer": false, "status": false}},
+		{"short status timeout", time.Second, 0, 50 * time.Millisecond, map[string]bool{"transfer": false, "status": true}},
+		{"short transfer timeout", time.Second, 50 * time.Millisecond, 0, map[string]bool{"transfer": true, "status": false}},
+		{"overrides outlast the client timeout", 50 * time.Millisecond, time.Second, time.Second, map[string]bool{"transfer": false, "status": false}},
+		{"short client timeout", 50 * time.Millisecond, 0, 0, map[string]bool{"transfer": true, "status": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewWiseClient(config.WiseConfig{
+				Mode:            config.ClientModeLive,
+				Endpoint:        server.URL + "/v1",
+				Timeout:         tt.timeout,
+				TransferTimeout: tt.transfer,
+				StatusTimeout:   tt.status,
+			})
+
+			_, err := client.CreateTransfer(context.Background(), &WiseTransferRequest{SourceAmount: 1000})
+			if timedOut := err != nil; timedOut != tt.wantTimeout["transfer"] {
+				t.Errorf("CreateTransfer() = %v, want timeout %v", err, tt.wantTimeout["transfer"])
+			}
+			_, err = client.GetTransferStatus(context.Background(), "468956")
+			if timedOut := err != nil; timedOut != tt.wantTimeout["status"] {
+				t.Errorf("GetTransferStatus() = %v, want timeout %v", err, tt.wantTimeout["status"])
+			}
+		})
+	}
+}
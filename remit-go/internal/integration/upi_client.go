@@ -16,8 +16,10 @@ type upiClient struct {
 
 // NewUPIClient creates a new UPI payment gateway client
 func NewUPIClient(cfg config.UPIConfig) UPIClient {
+	// Operations are bounded by their own timeouts; the client only stops
+	// a request outliving the longest of them
 	client := &http.Client{
-		Timeout: cfg.Timeout,
+		Timeout: max(cfg.Timeout, cfg.LinkTimeout),
 	}
 
 	return &upiClient{
@@ -29,7 +31,10 @@ func NewUPIClient(cfg config.UPIConfig) UPIClient {
 
 // GeneratePaymentLink creates a new UPI payment link
 func (c *upiClient) GeneratePaymentLink(ctx context.Context, txID string, amount float64) (string, error) {
-	// Implementation would make an HTTP request to generate payment link
+	ctx, cancel := operationContext(ctx, c.config.LinkTimeout, c.config.Timeout)
+	defer cancel()
+
+	// Implementation would make an HTTP request with ctx to generate payment link
 	// This is a mock implementation
 	paymentLink := fmt.Sprintf("upi://pay?pa=%s&pn=RemitGo&am=%f&tr=%s",
 		c.config.VPA,
@@ -45,3 +50,20 @@ func (c *upiClient) VerifyPayment(ctx context.Context, paymentID string) (string
 	// This is a mock implementation
 	return "SUCCESS", nil
 }
+
+// InspectPayment returns the gateway's payment record as received
+func (c *upiClient) InspectPayment(ctx context.Context, paymentID string) (map[string]interface{}, error) {
+	// Implementation would make the same request as VerifyPayment and
+	// decode the body without interpreting it
+	// This is a mock implementation
+	return map[string]interface{}{
+		"payment_id": paymentID,
+		"status":     "SUCCESS",
+		"payee_vpa":  c.config.VPA,
+	}, nil
+}
+
+// Ping checks the UPI gateway is reachable
+func (c *upiClient) Ping(ctx context.Context) error {
+	return ping(ctx, c.client, c.baseURL)
+}
@@ -1,46 +1,197 @@
 package integration
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/remit-demo/remit-go/internal/config"
 )
 
 type wiseClient struct {
-	client    *http.Client
-	config    config.WiseConfig
-	baseURL   string
-	profileID string
+	client        *http.Client
+	config        config.WiseConfig
+	baseURL       string
+	profileID     string
+	terminalCodes map[string]bool
 }
 
 // NewWiseClient creates a new Wise API client
 func NewWiseClient(cfg config.WiseConfig) WiseClient {
+	// Operations are bounded by their own timeouts; the client only stops
+	// a request outliving the longest of them
 	client := &http.Client{
-		Timeout: cfg.Timeout,
+		Timeout: max(cfg.Timeout, cfg.TransferTimeout, cfg.StatusTimeout),
+	}
+
+	codes := cfg.TerminalErrors
+	if len(codes) == 0 {
+		codes = defaultTerminalTransferCodes
+	}
+	terminalCodes := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		terminalCodes[code] = true
 	}
 
 	return &wiseClient{
-		client:    client,
-		config:    cfg,
-		baseURL:   cfg.Endpoint,
-		profileID: cfg.ProfileID,
+		client:        client,
+		config:        cfg,
+		baseURL:       cfg.Endpoint,
+		profileID:     cfg.ProfileID,
+		terminalCodes: terminalCodes,
 	}
 }
 
-// CreateTransfer initiates a new transfer via Wise
+// wiseTransferBody is the transfer creation request sent to Wise
+type wiseTransferBody struct {
+	Profile string `json:"profile"`
+	*WiseTransferRequest
+}
+
+// wiseErrorBody is the body of a failed Wise call
+type wiseErrorBody struct {
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// CreateTransfer initiates a new transfer via Wise. Failures are returned
+// as a *TransferError classified by classifyError. Unless the client is
+// live, no request is made and a made-up transfer ID is returned.
 func (c *wiseClient) CreateTransfer(ctx context.Context, req *WiseTransferRequest) (string, error) {
-	// Implementation would make an HTTP request to create transfer
-	// This is a mock implementation
-	transferID := fmt.Sprintf("TR-%d", time.Now().Unix())
-	return transferID, nil
+	if c.config.Mode != config.ClientModeLive {
+		return fmt.Sprintf("TR-%d", time.Now().Unix()), nil
+	}
+
+	ctx, cancel := operationContext(ctx, c.config.TransferTimeout, c.config.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(wiseTransferBody{Profile: c.profileID, WiseTransferRequest: req})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transfer request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/transfers", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build transfer request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return "", c.classifyError(0, "", err, req.CustomerTransactionID != "")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		var failure wiseErrorBody
+		code := ""
+		if json.NewDecoder(resp.Body).Decode(&failure) == nil && len(failure.Errors) > 0 {
+			code = failure.Errors[0].Code
+		}
+		return "", c.classifyError(resp.StatusCode, code, fmt.Errorf("wise answered %d", resp.StatusCode), req.CustomerTransactionID != "")
+	}
+
+	var created struct {
+		ID json.Number `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", c.classifyError(resp.StatusCode, "invalid_response", fmt.Errorf("failed to decode transfer: %w", err), req.CustomerTransactionID != "")
+	}
+	if created.ID == "" {
+		return "", c.classifyError(resp.StatusCode, "invalid_response", errors.New("wise returned a transfer without an ID"), req.CustomerTransactionID != "")
+	}
+	return created.ID.String(), nil
 }
 
-// GetTransferStatus checks the status of a transfer
+// GetTransferStatus checks the status of a transfer: COMPLETED, FAILED or
+// PROCESSING
 func (c *wiseClient) GetTransferStatus(ctx context.Context, transferID string) (string, error) {
-	// Implementation would make an HTTP request to check transfer status
-	// This is a mock implementation
-	return "COMPLETED", nil
+	ctx, cancel := operationContext(ctx, c.config.StatusTimeout, c.config.Timeout)
+	defer cancel()
+
+	record, err := c.InspectTransfer(ctx, transferID)
+	if err != nil {
+		return "", err
+	}
+	status, _ := record["status"].(string)
+	return transferStatus(status), nil
+}
+
+// InspectTransfer returns Wise's transfer record as received. In mock mode
+// every transfer has been sent.
+func (c *wiseClient) InspectTransfer(ctx context.Context, transferID string) (map[string]interface{}, error) {
+	if c.config.Mode != config.ClientModeLive {
+		return map[string]interface{}{
+			"id":     transferID,
+			"status": "outgoing_payment_sent",
+		}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/transfers/"+url.PathEscape(transferID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build transfer request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer %s: %w", transferID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get transfer %s: wise answered %d", transferID, resp.StatusCode)
+	}
+
+	var record map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		return nil, fmt.Errorf("failed to decode transfer %s: %w", transferID, err)
+	}
+	return record, nil
+}
+
+// transferStatus maps a Wise transfer state to COMPLETED, FAILED or
+// PROCESSING
+func transferStatus(state string) string {
+	switch state {
+	case "outgoing_payment_sent":
+		return "COMPLETED"
+	case "cancelled", "funds_refunded", "bounced_back", "charged_back":
+		return "FAILED"
+	default:
+		return "PROCESSING"
+	}
+}
+
+// classifyError turns a failed Wise call into a *TransferError. Throttling
+// and server errors are retryable; configured terminal codes and any other
+// client error are not. A timeout is ambiguous, as Wise may have created the
+// transfer, so it is retryable only for an idempotent request.
+func (c *wiseClient) classifyError(statusCode int, code string, err error, idempotent bool) error {
+	var netErr net.Error
+	switch {
+	case c.terminalCodes[code]:
+		return &TransferError{Code: code, Err: err}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return &TransferError{Code: "timeout", Retryable: idempotent, Err: err}
+	case statusCode == http.StatusTooManyRequests, statusCode >= http.StatusInternalServerError:
+		if code == "" {
+			code = http.StatusText(statusCode)
+		}
+		return &TransferError{Code: code, Retryable: true, Err: err}
+	default:
+		if code == "" {
+			code = "request_rejected"
+		}
+		return &TransferError{Code: code, Err: err}
+	}
+}
+
+// Ping checks the Wise API is reachable
+func (c *wiseClient) Ping(ctx context.Context) error {
+	return ping(ctx, c.client, c.baseURL)
 }
@@ -0,0 +1,196 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/config"
+)
+
+func TestWiseCreateTransfer(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		delay          time.Duration
+		terminalErrors []string
+		wantID         string
+		wantCode       string
+		wantRetryable  bool
+	}{
+		{name: "created", status: http.StatusOK, body: `{"id": 468956}`, wantID: "468956"},
+		{name: "throttled", status: http.StatusTooManyRequests, wantCode: "Too Many Requests", wantRetryable: true},
+		{name: "server error", status: http.StatusBadGateway, body: `{"errors": [{"code": "upstream_down"}]}`, wantCode: "upstream_down", wantRetryable: true},
+		{name: "timeout", status: http.StatusOK, body: `{"id": 1}`, delay: 200 * time.Millisecond, wantCode: "timeout", wantRetryable: true},
+		{name: "default terminal code", status: http.StatusUnprocessableEntity, body: `{"errors": [{"code": "insufficient_funds"}]}`, wantCode: "insufficient_funds"},
+		{name: "configured terminal code", status: http.StatusUnprocessableEntity, body: `{"errors": [{"code": "recipient_blocked"}]}`,
+			terminalErrors: []string{"recipient_blocked"}, wantCode: "recipient_blocked"},
+		{name: "configured terminal code on a server error", status: http.StatusServiceUnavailable, body: `{"errors": [{"code": "recipient_blocked"}]}`,
+			terminalErrors: []string{"recipient_blocked"}, wantCode: "recipient_blocked"},
+		{name: "other client error", status: http.StatusBadRequest, body: `not json`, wantCode: "request_rejected"},
+		{name: "created without an ID", status: http.StatusOK, body: `{}`, wantCode: "invalid_response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := make(chan map[string]interface{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/transfers" {
+					t.Errorf("request = %s %s, want POST /v1/transfers", r.Method, r.URL.Path)
+				}
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				requests <- body
+				if tt.delay > 0 {
+					select {
+					case <-time.After(tt.delay):
+					case <-r.Context().Done():
+					}
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewWiseClient(config.WiseConfig{
+				Mode:            config.ClientModeLive,
+				Endpoint:        server.URL + "/v1",
+				ProfileID:       "profile-1",
+				Timeout:         time.Second,
+				TransferTimeout: 50 * time.Millisecond,
+				TerminalErrors:  tt.terminalErrors,
+			})
+			id, err := client.CreateTransfer(context.Background(), &WiseTransferRequest{
+				SourceAmount:          1000,
+				SourceCurrency:        "INR",
+				TargetCurrency:        "CAD",
+				CustomerReference:     "tx-1",
+				CustomerTransactionID: "tx-1",
+			})
+
+			got := <-requests
+			if got["profile"] != "profile-1" || got["source_amount"] != 1000.0 || got["customer_reference"] != "tx-1" || got["customerTransactionId"] != "tx-1" {
+				t.Errorf("request body = %v, want the profile, transfer, customer reference and idempotency key", got)
+			}
+			if tt.wantCode == "" {
+				if err != nil || id != tt.wantID {
+					t.Fatalf("CreateTransfer() = %q, %v; want %q", id, err, tt.wantID)
+				}
+				return
+			}
+			var te *TransferError
+			if !errors.As(err, &te) {
+				t.Fatalf("CreateTransfer() error = %v, want a *TransferError", err)
+			}
+			if te.Code != tt.wantCode || te.Retryable != tt.wantRetryable {
+				t.Errorf("error code = %q, retryable = %v; want %q, %v", te.Code, te.Retryable, tt.wantCode, tt.wantRetryable)
+			}
+			if IsRetryable(err) != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %v, want %v", IsRetryable(err), tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestWiseCreateTransferTimeoutRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		wantRetryable bool
+	}{
+		{"idempotent", "TXN-1", true},
+		{"without a key", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&body)
+				keys = append(keys, body["customerTransactionId"])
+				// The first request reaches Wise but its answer is late
+				if len(keys) == 1 {
+					select {
+					case <-time.After(200 * time.Millisecond):
+					case <-r.Context().Done():
+					}
+					return
+				}
+				w.Write([]byte(`{"id": 468956}`))
+			}))
+			defer server.Close()
+
+			client := NewWiseClient(config.WiseConfig{
+				Mode:            config.ClientModeLive,
+				Endpoint:        server.URL + "/v1",
+				TransferTimeout: 50 * time.Millisecond,
+			})
+			req := &WiseTransferRequest{SourceAmount: 1000, CustomerReference: "TXN-1", CustomerTransactionID: tt.key}
+
+			_, err := client.CreateTransfer(context.Background(), req)
+			if IsRetryable(err) != tt.wantRetryable {
+				t.Fatalf("CreateTransfer() = %v, want retryable %v", err, tt.wantRetryable)
+			}
+			if !tt.wantRetryable {
+				return
+			}
+
+			// A retry repeats the key, so Wise returns the transfer it made
+			id, err := client.CreateTransfer(context.Background(), req)
+			if err != nil || id != "468956" {
+				t.Fatalf("retried CreateTransfer() = %q, %v; want 468956", id, err)
+			}
+			if len(keys) != 2 || keys[0] != tt.key || keys[1] != tt.key {
+				t.Errorf("idempotency keys sent = %v, want %s on both attempts", keys, tt.key)
+			}
+		})
+	}
+}
+
+func TestWiseCreateTransferMock(t *testing.T) {
+	client := NewWiseClient(config.WiseConfig{Endpoint: "http://127.0.0.1:0"})
+	id, err := client.CreateTransfer(context.Background(), &WiseTransferRequest{SourceAmount: 1000})
+	if err != nil || !strings.HasPrefix(id, "TR-") {
+		t.Fatalf("CreateTransfer() = %q, %v; want a mock transfer ID", id, err)
+	}
+}
+
+func TestWiseGetTransferStatus(t *testing.T) {
+	tests := []struct {
+		state string
+		want  string
+	}{
+		{"outgoing_payment_sent", "COMPLETED"},
+		{"funds_refunded", "FAILED"},
+		{"cancelled", "FAILED"},
+		{"processing", "PROCESSING"},
+		{"incoming_payment_waiting", "PROCESSING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/transfers/468956" {
+					t.Errorf("path = %s, want /v1/transfers/468956", r.URL.Path)
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": 468956, "status": tt.state})
+			}))
+			defer server.Close()
+
+			client := NewWiseClient(config.WiseConfig{Mode: config.ClientModeLive, Endpoint: server.URL + "/v1", Timeout: time.Second})
+			got, err := client.GetTransferStatus(context.Background(), "468956")
+			if err != nil || got != tt.want {
+				t.Fatalf("GetTransferStatus() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "remit"
+
+var (
+	// BackgroundTasksStarted counts background tasks launched outside a request, by task
+	BackgroundTasksStarted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "background_tasks_started_total",
+		Help:      "Background tasks started, by task.",
+	}, []string{"task"})
+
+	// BackgroundTasksFailed counts background tasks that returned an error, by task
+	BackgroundTasksFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "background_tasks_failed_total",
+		Help:      "Background tasks that failed, by task.",
+	}, []string{"task"})
+
+	// InFlightInitiations is the number of transaction initiations being served
+	InFlightInitiations = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "in_flight_initiations",
+		Help:      "Transaction initiation requests currently in flight.",
+	})
+
+	// FeesCollected sums the fees of completed transactions, in the source
+	// currency, by currency pair
+	FeesCollected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fees_collected_total",
+		Help:      "Fees of completed transactions in the source currency, by currency pair.",
+	}, []string{"pair"})
+
+	// FXMarginEarned sums the exchange rate margin of completed
+	// transactions, in the target currency, by currency pair
+	FXMarginEarned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fx_margin_earned_total",
+		Help:      "Exchange rate margin of completed transactions in the target currency, by currency pair.",
+	}, []string{"pair"})
+
+	// InFlightTransfers is the number of Wise CreateTransfer calls in flight
+	InFlightTransfers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "in_flight_transfers",
+		Help:      "Wise transfer creation calls currently in flight.",
+	})
+)
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
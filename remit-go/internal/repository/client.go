@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ClientLimits bounds how long the DynamoDB client spends on one operation.
+// The SDK retries throttled requests with backoff, which under load can
+// keep an operation going well past what its caller is willing to wait.
+type ClientLimits struct {
+	// OperationTimeout caps each operation, retries included, on top of
+	// the caller's own deadline. Zero leaves only the caller's deadline.
+	OperationTimeout time.Duration
+
+	// MaxAttempts and MaxBackoff bound the SDK retryer. Zero keeps the SDK
+	// default.
+	MaxAttempts int
+	MaxBackoff  time.Duration
+}
+
+// Apply configures a DynamoDB client with the limits. Pass it to
+// dynamodb.NewFromConfig.
+func (l ClientLimits) Apply(o *dynamodb.Options) {
+	if l.MaxAttempts > 0 || l.MaxBackoff > 0 {
+		o.Retryer = retry.NewStandard(func(so *retry.StandardOptions) {
+			if l.MaxAttempts > 0 {
+				so.MaxAttempts = l.MaxAttempts
+			}
+			if l.MaxBackoff > 0 {
+				so.MaxBackoff = l.MaxBackoff
+			}
+		})
+	}
+
+	if l.OperationTimeout > 0 {
+		o.APIOptions = append(o.APIOptions, operationTimeout(l.OperationTimeout))
+	}
+}
+
+// operationTimeout derives the operation's context, which the retry loop
+// and its backoff sleeps both honour, with the timeout
+func operationTimeout(timeout time.Duration) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OperationTimeout",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return next.HandleInitialize(ctx, in)
+			},
+		), middleware.Before)
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+func TestClientLimits(t *testing.T) {
+	throttled := func(call dynamoCall) dynamoResponse {
+		return dynamoResponse{errorType: "ProvisionedThroughputExceededException", message: "Rate of requests exceeds the allowed throughput"}
+	}
+
+	tests := []struct {
+		name         string
+		limits       ClientLimits
+		deadline     time.Duration // of the caller, zero for none
+		wantAttempts int           // zero when bounded by a deadline instead
+		wantDeadline bool
+	}{
+		{"attempts bounded", ClientLimits{MaxAttempts: 3, MaxBackoff: time.Millisecond}, 0, 3, false},
+		{"caller deadline", ClientLimits{MaxAttempts: 50, MaxBackoff: time.Second}, 50 * time.Millisecond, 0, true},
+		{"operation timeout", ClientLimits{OperationTimeout: 50 * time.Millisecond, MaxAttempts: 50, MaxBackoff: time.Second}, 0, 0, true},
+		{"caller deadline under operation timeout", ClientLimits{OperationTimeout: time.Minute, MaxAttempts: 50, MaxBackoff: time.Second}, 50 * time.Millisecond, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDynamo{respond: throttled}
+			srv := httptest.NewServer(fake)
+			t.Cleanup(srv.Close)
+			client := dynamodb.New(dynamodb.Options{
+				Region:       "us-east-1",
+				BaseEndpoint: aws.String(srv.URL),
+				Credentials:  aws.AnonymousCredentials{},
+			}, tt.limits.Apply)
+			repo := NewDynamoDBRepository(client, "transactions", "payments", "rates", "idempotency", "dead_letters")
+
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			start := time.Now()
+			_, err := repo.GetTransaction(ctx, "TXN-1")
+			elapsed := time.Since(start)
+
+			if err == nil {
+				t.Fatal("GetTransaction() succeeded against a throttled table")
+			}
+			if got := errors.Is(err, context.DeadlineExceeded); got != tt.wantDeadline {
+				t.Errorf("GetTransaction() = %v, want deadline error %v", err, tt.wantDeadline)
+			}
+			if tt.wantDeadline && elapsed > 500*time.Millisecond {
+				t.Errorf("GetTransaction() took %v, want it to return at the deadline", elapsed)
+			}
+			if n := len(fake.received()); tt.wantAttempts > 0 && n != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", n, tt.wantAttempts)
+			}
+		})
+	}
+}
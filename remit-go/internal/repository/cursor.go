@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// encodeCursor turns a DynamoDB LastEvaluatedKey into an opaque, URL-safe
+// pagination cursor. An empty key yields an empty cursor.
+func encodeCursor(key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	var plain map[string]interface{}
+	if err := attributevalue.UnmarshalMap(key, &plain); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(plain)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// maxCursorLength bounds the cursors accepted; real ones stay well below it
+const maxCursorLength = 1024
+
+// decodeCursor reverses encodeCursor. The decoded key must consist of
+// exactly keyAttrs, each a string, which is all a LastEvaluatedKey of the
+// tables and indexes here holds. Malformed or forged cursors return
+// ErrInvalidInput.
+func decodeCursor(cursor string, keyAttrs ...string) (map[string]types.AttributeValue, error) {
+	if len(cursor) > maxCursorLength {
+		return nil, ErrInvalidInput
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	var plain map[string]interface{}
+	if err := json.Unmarshal(data, &plain); err != nil || len(plain) != len(keyAttrs) {
+		return nil, ErrInvalidInput
+	}
+	for _, attr := range keyAttrs {
+		if s, ok := plain[attr].(string); !ok || s == "" {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	key, err := attributevalue.MarshalMap(plain)
+	if err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	return key, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	key := map[string]types.AttributeValue{
+		"transaction_id": &types.AttributeValueMemberS{Value: "TXN-1"},
+		"status":         &types.AttributeValueMemberS{Value: "COMPLETED"},
+		"created_at":     &types.AttributeValueMemberS{Value: "2026-03-02T12:00:00Z"},
+	}
+
+	cursor, err := encodeCursor(key)
+	if err != nil {
+		t.Fatalf("encodeCursor() = %v", err)
+	}
+	if strings.ContainsAny(cursor, "+/=") {
+		t.Errorf("cursor %q is not URL-safe", cursor)
+	}
+
+	got, err := decodeCursor(cursor, "transaction_id", "status", "created_at")
+	if err != nil {
+		t.Fatalf("decodeCursor() = %v", err)
+	}
+	for name, want := range key {
+		if s, ok := got[name].(*types.AttributeValueMemberS); !ok || s.Value != want.(*types.AttributeValueMemberS).Value {
+			t.Errorf("%s = %#v, want %#v", name, got[name], want)
+		}
+	}
+
+	if cursor, err := encodeCursor(nil); cursor != "" || err != nil {
+		t.Errorf("encodeCursor(nil) = %q, %v; want empty", cursor, err)
+	}
+}
+
+func TestDecodeCursorRejectsForgeries(t *testing.T) {
+	encode := func(json string) string { return base64.RawURLEncoding.EncodeToString([]byte(json)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "%%%"},
+		{"not JSON", encode("TXN-1")},
+		{"missing attribute", encode(`{"transaction_id":"TXN-1","status":"COMPLETED"}`)},
+		{"extra attribute", encode(`{"transaction_id":"TXN-1","status":"COMPLETED","created_at":"x","amount":"1"}`)},
+		{"other attribute", encode(`{"transaction_id":"TXN-1","status":"COMPLETED","user_id":"x"}`)},
+		{"number attribute", encode(`{"transaction_id":"TXN-1","status":"COMPLETED","created_at":1}`)},
+		{"empty attribute", encode(`{"transaction_id":"","status":"COMPLETED","created_at":"x"}`)},
+		{"too long", strings.Repeat("A", maxCursorLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, "transaction_id", "status", "created_at"); err != ErrInvalidInput {
+				t.Errorf("decodeCursor() = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
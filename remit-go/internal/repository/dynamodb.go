@@ -2,47 +2,163 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
 	"github.com/remit-demo/remit-go/internal/domain"
 )
 
+// Global secondary indexes on the transaction table
+const (
+	UserIndexName      = "user_id-created_at-index"
+	StatusIndexName    = "status-created_at-index"
+	ReferenceIndexName = "reference-index"
+	RecipientIndexName = "recipient_hash-created_at-index"
+)
+
 type DynamoDBRepository struct {
 	client        *dynamodb.Client
 	txTableName   string
 	payTableName  string
+	rateTableName string
+	idemTableName string
+	dlqTableName  string
 }
 
 // NewDynamoDBRepository creates a new DynamoDB repository instance
-func NewDynamoDBRepository(client *dynamodb.Client, txTableName, payTableName string) *DynamoDBRepository {
+func NewDynamoDBRepository(client *dynamodb.Client, txTableName, payTableName, rateTableName, idemTableName, dlqTableName string) *DynamoDBRepository {
 	return &DynamoDBRepository{
 		client:        client,
 		txTableName:   txTableName,
 		payTableName:  payTableName,
+		rateTableName: rateTableName,
+		idemTableName: idemTableName,
+		dlqTableName:  dlqTableName,
+	}
+}
+
+// Ping checks DynamoDB is reachable and the transaction table exists
+func (r *DynamoDBRepository) Ping(ctx context.Context) error {
+	_, err := r.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(r.txTableName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe table %s: %w", r.txTableName, err)
 	}
+	return nil
+}
+
+// CheckIndexes verifies the transaction table has the GSIs the queries rely
+// on. Meant to be called at startup so a missing index is reported before
+// the first request fails.
+func (r *DynamoDBRepository) CheckIndexes(ctx context.Context) error {
+	result, err := r.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(r.txTableName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe table %s: %w", r.txTableName, err)
+	}
+
+	existing := make(map[string]bool)
+	for _, gsi := range result.Table.GlobalSecondaryIndexes {
+		existing[aws.ToString(gsi.IndexName)] = true
+	}
+
+	var missing []string
+	for _, name := range []string{UserIndexName, StatusIndexName, ReferenceIndexName, RecipientIndexName} {
+		if !existing[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: table %s is missing %s", ErrIndexMisconfigured, r.txTableName, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// queryError wraps a failed Query. DynamoDB reports a query against an index
+// that does not exist as a ValidationException, which becomes
+// ErrIndexMisconfigured so callers can tell it apart from transient failures.
+func queryError(err error, op string) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ValidationException" &&
+		strings.Contains(strings.ToLower(apiErr.ErrorMessage()), "index") {
+		return fmt.Errorf("%w: %s", ErrIndexMisconfigured, apiErr.ErrorMessage())
+	}
+	return fmt.Errorf("failed to %s: %w", op, err)
+}
+
+// maxQueryPages bounds the queries made to fill one page of results
+const maxQueryPages = 10
+
+// queryPage runs input until it has collected limit items or the results are
+// exhausted. DynamoDB may stop a query short of its Limit (the 1 MB read cap,
+// or items dropped by a filter), so one call can return a sparse page. The
+// returned key is nil only when no more items remain; after maxQueryPages
+// queries it is the key to resume from.
+func (r *DynamoDBRepository) queryPage(ctx context.Context, input *dynamodb.QueryInput, limit int, op string) ([]map[string]types.AttributeValue, map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	var lastKey map[string]types.AttributeValue
+
+	for pages := 0; pages < maxQueryPages; pages++ {
+		input.Limit = aws.Int32(int32(limit - len(items)))
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, nil, queryError(err, op)
+		}
+		items = append(items, result.Items...)
+		lastKey = result.LastEvaluatedKey
+		if len(lastKey) == 0 {
+			return items, nil, nil
+		}
+		input.ExclusiveStartKey = lastKey
+		if len(items) >= limit {
+			break
+		}
+	}
+	if len(items) < limit {
+		return items, lastKey, nil
+	}
+
+	// A full page still reports a key when the last item happened to end
+	// the results; look one item ahead so no cursor leads to an empty page
+	input.Limit = aws.Int32(1)
+	input.ExclusiveStartKey = lastKey
+	peek, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, nil, queryError(err, op)
+	}
+	if len(peek.Items) == 0 && len(peek.LastEvaluatedKey) == 0 {
+		return items, nil, nil
+	}
+	return items, lastKey, nil
 }
 
 // CreateTransaction creates a new transaction in DynamoDB
 func (r *DynamoDBRepository) CreateTransaction(ctx context.Context, tx *domain.Transaction) error {
-	item, err := attributevalue.MarshalMap(tx)
+	item, err := marshalMap("transaction", tx)
 	if err != nil {
-		return fmt.Errorf("failed to marshal transaction: %w", err)
+		return err
 	}
 
 	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.txTableName),
-		Item:      item,
+		TableName:           aws.String(r.txTableName),
+		Item:                item,
 		ConditionExpression: aws.String("attribute_not_exists(transaction_id)"),
 	})
 
 	if err != nil {
 		var ccfe *types.ConditionalCheckFailedException
-		if _, ok := err.(*types.ConditionalCheckFailedException); ok {
+		if errors.As(err, &ccfe) {
 			return ErrAlreadyExists
 		}
 		return fmt.Errorf("failed to create transaction: %w", err)
@@ -78,22 +194,22 @@ func (r *DynamoDBRepository) GetTransaction(ctx context.Context, id string) (*do
 
 // UpdateTransaction updates an existing transaction
 func (r *DynamoDBRepository) UpdateTransaction(ctx context.Context, tx *domain.Transaction) error {
-	tx.UpdatedAt = time.Now()
-	
-	item, err := attributevalue.MarshalMap(tx)
+	tx.UpdatedAt = domain.Now()
+
+	item, err := marshalMap("transaction", tx)
 	if err != nil {
-		return fmt.Errorf("failed to marshal transaction: %w", err)
+		return err
 	}
 
 	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.txTableName),
-		Item:      item,
+		TableName:           aws.String(r.txTableName),
+		Item:                item,
 		ConditionExpression: aws.String("attribute_exists(transaction_id)"),
 	})
 
 	if err != nil {
 		var ccfe *types.ConditionalCheckFailedException
-		if _, ok := err.(*types.ConditionalCheckFailedException); ok {
+		if errors.As(err, &ccfe) {
 			return ErrNotFound
 		}
 		return fmt.Errorf("failed to update transaction: %w", err)
@@ -102,39 +218,319 @@ func (r *DynamoDBRepository) UpdateTransaction(ctx context.Context, tx *domain.T
 	return nil
 }
 
-// ListTransactionsByUser retrieves transactions for a specific user
-func (r *DynamoDBRepository) ListTransactionsByUser(ctx context.Context, userID string, limit int, lastKey string) ([]*domain.Transaction, string, error) {
+// UpdateTransactionStatus moves a transaction from one status to another,
+// writing only the status, its history entry, the timestamps and fields. It
+// fails with ErrStatusMismatch when the stored status is no longer from, so a
+// concurrent change is never overwritten. With from equal to to only the
+// fields and updated_at are written, guarded by the status.
+func (r *DynamoDBRepository) UpdateTransactionStatus(ctx context.Context, id string, from, to domain.TransactionStatus, fields ...Field) error {
+	now := domain.Now()
+
+	nowValue, err := marshal("timestamp", now)
+	if err != nil {
+		return err
+	}
+
+	sets := []string{"updated_at = :now"}
+	conditions := []string{"#status = :from"}
+	names := map[string]string{"#status": "status"}
+	values := map[string]types.AttributeValue{
+		":from": &types.AttributeValueMemberS{Value: string(from)},
+		":now":  nowValue,
+	}
+	if from != to {
+		change, err := marshal("status change", []domain.StatusChange{{Status: to, At: now}})
+		if err != nil {
+			return err
+		}
+		sets = append(sets,
+			"#status = :to",
+			"status_history = list_append(if_not_exists(status_history, :empty), :change)",
+		)
+		values[":to"] = &types.AttributeValueMemberS{Value: string(to)}
+		values[":change"] = change
+		values[":empty"] = &types.AttributeValueMemberL{Value: []types.AttributeValue{}}
+		if to == domain.StatusCompleted {
+			sets = append(sets, "completed_at = :now")
+		}
+	}
+	var removes []string
+	for i, f := range fields {
+		name, placeholder := fmt.Sprintf("#f%d", i), fmt.Sprintf(":f%d", i)
+		names[name] = f.Name
+		if f.Remove {
+			removes = append(removes, name)
+			continue
+		}
+		if f.Require && f.Value == nil {
+			conditions = append(conditions, "attribute_not_exists("+name+")")
+			continue
+		}
+
+		value, err := marshal(f.Name, f.Value)
+		if err != nil {
+			return err
+		}
+		values[placeholder] = value
+		if f.Require {
+			conditions = append(conditions, name+" = "+placeholder)
+			continue
+		}
+		sets = append(sets, name+" = "+placeholder)
+		if f.IfAbsent {
+			conditions = append(conditions, "attribute_not_exists("+name+")")
+		}
+	}
+	update := "SET " + strings.Join(sets, ", ")
+	if len(removes) > 0 {
+		update += " REMOVE " + strings.Join(removes, ", ")
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.txTableName),
+		Key: map[string]types.AttributeValue{
+			"transaction_id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:                    aws.String(update),
+		ConditionExpression:                 aws.String(strings.Join(conditions, " AND ")),
+		ExpressionAttributeNames:            names,
+		ExpressionAttributeValues:           values,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+
+	if err != nil {
+		var ccfe *types.ConditionalCheckFailedException
+		if errors.As(err, &ccfe) {
+			if ccfe.Item == nil {
+				return ErrNotFound
+			}
+			return ErrStatusMismatch
+		}
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+
+	return nil
+}
+
+// maxBatchGetKeys is the most keys DynamoDB accepts in one BatchGetItem call
+const maxBatchGetKeys = 100
+
+// BatchGetTransactions retrieves the transactions with the given IDs. IDs
+// that do not exist are left out of the result, which is in no particular
+// order.
+func (r *DynamoDBRepository) BatchGetTransactions(ctx context.Context, ids []string) ([]*domain.Transaction, error) {
+	var transactions []*domain.Transaction
+	for start := 0; start < len(ids); start += maxBatchGetKeys {
+		end := min(start+maxBatchGetKeys, len(ids))
+
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, map[string]types.AttributeValue{
+				"transaction_id": &types.AttributeValueMemberS{Value: id},
+			})
+		}
+
+		request := map[string]types.KeysAndAttributes{
+			r.txTableName: {Keys: keys},
+		}
+		// Keys DynamoDB could not serve within its limits come back as
+		// unprocessed and are requested again
+		for len(request) > 0 {
+			result, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: request,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to batch get transactions: %w", err)
+			}
+
+			var page []*domain.Transaction
+			if err := attributevalue.UnmarshalListOfMaps(result.Responses[r.txTableName], &page); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal transactions: %w", err)
+			}
+			transactions = append(transactions, page...)
+
+			request = result.UnprocessedKeys
+		}
+	}
+
+	return transactions, nil
+}
+
+// ListTransactionsByUser retrieves transactions for a specific user in the
+// given creation time order
+func (r *DynamoDBRepository) ListTransactionsByUser(ctx context.Context, userID string, limit int, lastKey string, order SortOrder) ([]*domain.Transaction, string, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(r.txTableName),
-		IndexName:             aws.String("user_id-created_at-index"),
+		IndexName:              aws.String(UserIndexName),
 		KeyConditionExpression: aws.String("user_id = :uid"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":uid": &types.AttributeValueMemberS{Value: userID},
 		},
-		Limit:            aws.Int32(int32(limit)),
-		ScanIndexForward: aws.Bool(false), // Latest first
+		ScanIndexForward: aws.Bool(order == SortAscending),
 	}
 
 	if lastKey != "" {
-		// Parse and set ExclusiveStartKey based on lastKey
-		// Implementation depends on how you encode/decode the last evaluated key
+		startKey, err := decodeCursor(lastKey, "transaction_id", "user_id", "created_at")
+		if err != nil {
+			return nil, "", err
+		}
+		// A cursor from another user's listing would leak their history
+		if uid := startKey["user_id"].(*types.AttributeValueMemberS); uid.Value != userID {
+			return nil, "", ErrInvalidInput
+		}
+		input.ExclusiveStartKey = startKey
 	}
 
-	result, err := r.client.Query(ctx, input)
+	items, lastEvaluated, err := r.queryPage(ctx, input, limit, "query transactions")
+	if err != nil {
+		return nil, "", err
+	}
+
+	var transactions []*domain.Transaction
+	err = attributevalue.UnmarshalListOfMaps(items, &transactions)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal transactions: %w", err)
+	}
+
+	nextKey, err := encodeCursor(lastEvaluated)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode pagination key: %w", err)
+	}
+
+	return transactions, nextKey, nil
+}
+
+// ListTransactionsByRecipient retrieves the transactions with a recipient
+// hash, see domain.RecipientHash, in the given creation time order. It
+// queries the sparse recipient_hash-created_at-index GSI, which must use
+// recipient_hash as partition key and created_at as sort key and project ALL
+// attributes.
+func (r *DynamoDBRepository) ListTransactionsByRecipient(ctx context.Context, recipientHash string, limit int, lastKey string, order SortOrder) ([]*domain.Transaction, string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.txTableName),
+		IndexName:              aws.String(RecipientIndexName),
+		KeyConditionExpression: aws.String("recipient_hash = :hash"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":hash": &types.AttributeValueMemberS{Value: recipientHash},
+		},
+		ScanIndexForward: aws.Bool(order == SortAscending),
+	}
+
+	if lastKey != "" {
+		startKey, err := decodeCursor(lastKey, "transaction_id", "recipient_hash", "created_at")
+		if err != nil {
+			return nil, "", err
+		}
+		// A cursor from another recipient's listing would skip this one
+		if hash := startKey["recipient_hash"].(*types.AttributeValueMemberS); hash.Value != recipientHash {
+			return nil, "", ErrInvalidInput
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	items, lastEvaluated, err := r.queryPage(ctx, input, limit, "query transactions by recipient")
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to query transactions: %w", err)
+		return nil, "", err
 	}
 
 	var transactions []*domain.Transaction
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &transactions)
+	if err := attributevalue.UnmarshalListOfMaps(items, &transactions); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal transactions: %w", err)
+	}
+
+	nextKey, err := encodeCursor(lastEvaluated)
 	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode pagination key: %w", err)
+	}
+
+	return transactions, nextKey, nil
+}
+
+// ListTransactionsByReference retrieves a page of the transactions of any
+// user that carry a reference. It queries the sparse reference-index GSI,
+// which must use reference as partition key and project ALL attributes.
+func (r *DynamoDBRepository) ListTransactionsByReference(ctx context.Context, reference string, limit int, lastKey string) ([]*domain.Transaction, string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.txTableName),
+		IndexName:              aws.String(ReferenceIndexName),
+		KeyConditionExpression: aws.String("reference = :ref"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ref": &types.AttributeValueMemberS{Value: reference},
+		},
+	}
+
+	if lastKey != "" {
+		startKey, err := decodeCursor(lastKey, "transaction_id", "reference")
+		if err != nil {
+			return nil, "", err
+		}
+		// A cursor from another reference's search would skip this one
+		if ref := startKey["reference"].(*types.AttributeValueMemberS); ref.Value != reference {
+			return nil, "", ErrInvalidInput
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	items, lastEvaluated, err := r.queryPage(ctx, input, limit, "query transactions by reference")
+	if err != nil {
+		return nil, "", err
+	}
+
+	var transactions []*domain.Transaction
+	if err := attributevalue.UnmarshalListOfMaps(items, &transactions); err != nil {
 		return nil, "", fmt.Errorf("failed to unmarshal transactions: %w", err)
 	}
 
-	var nextKey string
-	if result.LastEvaluatedKey != nil {
-		// Encode LastEvaluatedKey into a string
-		// Implementation depends on how you want to encode the pagination token
+	nextKey, err := encodeCursor(lastEvaluated)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode pagination key: %w", err)
+	}
+
+	return transactions, nextKey, nil
+}
+
+// ListTransactionsByStatus retrieves transactions in a status created within
+// [from, to], oldest first. It queries the status-created_at-index GSI, which
+// must use status as partition key and created_at as sort key and project
+// ALL attributes so full transactions can be returned without a table fetch.
+func (r *DynamoDBRepository) ListTransactionsByStatus(ctx context.Context, status domain.TransactionStatus, from, to time.Time, limit int, cursor string) ([]*domain.Transaction, string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.txTableName),
+		IndexName:              aws.String(StatusIndexName),
+		KeyConditionExpression: aws.String("#status = :status AND created_at BETWEEN :from AND :to"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: string(status)},
+			":from":   &types.AttributeValueMemberS{Value: from.UTC().Format(time.RFC3339Nano)},
+			":to":     &types.AttributeValueMemberS{Value: to.UTC().Format(time.RFC3339Nano)},
+		},
+		Limit:            aws.Int32(int32(limit)),
+		ScanIndexForward: aws.Bool(true), // Oldest first
+	}
+
+	if cursor != "" {
+		startKey, err := decodeCursor(cursor, "transaction_id", "status", "created_at")
+		if err != nil {
+			return nil, "", err
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	result, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, "", queryError(err, "query transactions by status")
+	}
+
+	var transactions []*domain.Transaction
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &transactions); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal transactions: %w", err)
+	}
+
+	nextKey, err := encodeCursor(result.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode pagination key: %w", err)
 	}
 
 	return transactions, nextKey, nil
@@ -142,22 +538,22 @@ func (r *DynamoDBRepository) ListTransactionsByUser(ctx context.Context, userID
 
 // CreatePayment creates a new payment record
 func (r *DynamoDBRepository) CreatePayment(ctx context.Context, txID string, payment *domain.PaymentDetails) error {
-	item, err := attributevalue.MarshalMap(payment)
+	item, err := marshalMap("payment", payment)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payment: %w", err)
+		return err
 	}
 
 	item["transaction_id"] = &types.AttributeValueMemberS{Value: txID}
 
 	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.payTableName),
-		Item:      item,
+		TableName:           aws.String(r.payTableName),
+		Item:                item,
 		ConditionExpression: aws.String("attribute_not_exists(payment_id)"),
 	})
 
 	if err != nil {
 		var ccfe *types.ConditionalCheckFailedException
-		if _, ok := err.(*types.ConditionalCheckFailedException); ok {
+		if errors.As(err, &ccfe) {
 			return ErrAlreadyExists
 		}
 		return fmt.Errorf("failed to create payment: %w", err)
@@ -168,22 +564,22 @@ func (r *DynamoDBRepository) CreatePayment(ctx context.Context, txID string, pay
 
 // UpdatePayment updates an existing payment record
 func (r *DynamoDBRepository) UpdatePayment(ctx context.Context, txID string, payment *domain.PaymentDetails) error {
-	item, err := attributevalue.MarshalMap(payment)
+	item, err := marshalMap("payment", payment)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payment: %w", err)
+		return err
 	}
 
 	item["transaction_id"] = &types.AttributeValueMemberS{Value: txID}
 
 	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.payTableName),
-		Item:      item,
+		TableName:           aws.String(r.payTableName),
+		Item:                item,
 		ConditionExpression: aws.String("attribute_exists(payment_id)"),
 	})
 
 	if err != nil {
 		var ccfe *types.ConditionalCheckFailedException
-		if _, ok := err.(*types.ConditionalCheckFailedException); ok {
+		if errors.As(err, &ccfe) {
 			return ErrNotFound
 		}
 		return fmt.Errorf("failed to update payment: %w", err)
@@ -215,4 +611,238 @@ func (r *DynamoDBRepository) GetPayment(ctx context.Context, paymentID string) (
 	}
 
 	return &payment, nil
-} 
\ No newline at end of file
+}
+
+// SaveRate stores the latest rate for its pair, replacing the previous one
+func (r *DynamoDBRepository) SaveRate(ctx context.Context, rate *domain.ExchangeRate) error {
+	item, err := marshalMap("rate", rate)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.rateTableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save rate: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastRate retrieves the last stored rate for a currency pair
+func (r *DynamoDBRepository) GetLastRate(ctx context.Context, sourceCurrency, targetCurrency string) (*domain.ExchangeRate, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.rateTableName),
+		Key: map[string]types.AttributeValue{
+			"pair": &types.AttributeValueMemberS{Value: domain.RatePair(sourceCurrency, targetCurrency)},
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var rate domain.ExchangeRate
+	if err := attributevalue.UnmarshalMap(result.Item, &rate); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rate: %w", err)
+	}
+
+	return &rate, nil
+}
+
+// PutIdempotencyRecord creates a record for a new key. The write only
+// succeeds if no live record holds the key, so of concurrent writers exactly
+// one wins and the others get ErrAlreadyExists.
+func (r *DynamoDBRepository) PutIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	item, err := marshalMap("idempotency record", record)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.idemTableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(idempotency_key) OR expires_at <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+
+	if err != nil {
+		var ccfe *types.ConditionalCheckFailedException
+		if errors.As(err, &ccfe) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to put idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteIdempotencyRecord stores the transaction created under a key
+func (r *DynamoDBRepository) CompleteIdempotencyRecord(ctx context.Context, key, txID string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.idemTableName),
+		Key: map[string]types.AttributeValue{
+			"idempotency_key": &types.AttributeValueMemberS{Value: key},
+		},
+		UpdateExpression:    aws.String("SET transaction_id = :tx"),
+		ConditionExpression: aws.String("attribute_exists(idempotency_key)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":tx": &types.AttributeValueMemberS{Value: txID},
+		},
+	})
+
+	if err != nil {
+		var ccfe *types.ConditionalCheckFailedException
+		if errors.As(err, &ccfe) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to complete idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyRecord frees a key whose request failed
+func (r *DynamoDBRepository) DeleteIdempotencyRecord(ctx context.Context, key string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.idemTableName),
+		Key: map[string]types.AttributeValue{
+			"idempotency_key": &types.AttributeValueMemberS{Value: key},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// GetIdempotencyRecord retrieves the live record for a key. Records past
+// their TTL that DynamoDB has not removed yet are reported as not found.
+func (r *DynamoDBRepository) GetIdempotencyRecord(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.idemTableName),
+		Key: map[string]types.AttributeValue{
+			"idempotency_key": &types.AttributeValueMemberS{Value: key},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var record domain.IdempotencyRecord
+	if err := attributevalue.UnmarshalMap(result.Item, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+
+	if record.IsExpired(time.Now()) {
+		return nil, ErrNotFound
+	}
+
+	return &record, nil
+}
+
+// PutDeadLetter stores a failed background operation
+func (r *DynamoDBRepository) PutDeadLetter(ctx context.Context, dl *domain.DeadLetter) error {
+	item, err := marshalMap("dead letter", dl)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.dlqTableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeadLetter retrieves a dead letter by ID
+func (r *DynamoDBRepository) GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.dlqTableName),
+		Key: map[string]types.AttributeValue{
+			"dead_letter_id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var dl domain.DeadLetter
+	if err := attributevalue.UnmarshalMap(result.Item, &dl); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead letter: %w", err)
+	}
+
+	return &dl, nil
+}
+
+// ListDeadLetters pages through the dead letters in no particular order. The
+// table is expected to stay small, so it is scanned.
+func (r *DynamoDBRepository) ListDeadLetters(ctx context.Context, limit int, cursor string) ([]*domain.DeadLetter, string, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(r.dlqTableName),
+		Limit:     aws.Int32(int32(limit)),
+	}
+
+	if cursor != "" {
+		startKey, err := decodeCursor(cursor, "dead_letter_id")
+		if err != nil {
+			return nil, "", err
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	result, err := r.client.Scan(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scan dead letters: %w", err)
+	}
+
+	var deadLetters []*domain.DeadLetter
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &deadLetters); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal dead letters: %w", err)
+	}
+
+	nextKey, err := encodeCursor(result.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode pagination key: %w", err)
+	}
+
+	return deadLetters, nextKey, nil
+}
+
+// DeleteDeadLetter removes a dead letter once it has been replayed
+func (r *DynamoDBRepository) DeleteDeadLetter(ctx context.Context, id string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.dlqTableName),
+		Key: map[string]types.AttributeValue{
+			"dead_letter_id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,810 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// dynamoCall is a request received by fakeDynamo
+type dynamoCall struct {
+	op   string // e.g. "Query"
+	body map[string]interface{}
+}
+
+// dynamoResponse is fakeDynamo's answer to a call: a JSON body with a 200,
+// or the named DynamoDB error with a 400
+type dynamoResponse struct {
+	body      interface{}
+	errorType string
+	message   string
+}
+
+// fakeDynamo serves the DynamoDB JSON protocol, answering each call with
+// respond and recording it
+type fakeDynamo struct {
+	mu      sync.Mutex
+	calls   []dynamoCall
+	respond func(call dynamoCall) dynamoResponse
+}
+
+func (f *fakeDynamo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	call := dynamoCall{op: strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")}
+	if err := json.Unmarshal(data, &call.body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	respond := f.respond
+	f.mu.Unlock()
+
+	resp := dynamoResponse{body: map[string]interface{}{}}
+	if respond != nil {
+		resp = respond(call)
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	if resp.errorType != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"__type":  "com.amazonaws.dynamodb.v20120810#" + resp.errorType,
+			"message": resp.message,
+			"Item":    resp.body,
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(resp.body)
+}
+
+// received returns the calls made so far
+func (f *fakeDynamo) received() []dynamoCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]dynamoCall(nil), f.calls...)
+}
+
+// newTestRepo returns a repository on a client talking to a fakeDynamo
+// answering with respond
+func newTestRepo(t *testing.T, respond func(call dynamoCall) dynamoResponse) (*DynamoDBRepository, *fakeDynamo) {
+	t.Helper()
+	fake := &fakeDynamo{respond: respond}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(srv.URL),
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+	return NewDynamoDBRepository(client, "transactions", "payments", "rates", "idempotency", "dead_letters"), fake
+}
+
+// wire converts an attribute value to its DynamoDB JSON form
+func wire(value types.AttributeValue) interface{} {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]interface{}{"S": v.Value}
+	case *types.AttributeValueMemberN:
+		return map[string]interface{}{"N": v.Value}
+	case *types.AttributeValueMemberBOOL:
+		return map[string]interface{}{"BOOL": v.Value}
+	case *types.AttributeValueMemberNULL:
+		return map[string]interface{}{"NULL": true}
+	case *types.AttributeValueMemberL:
+		list := make([]interface{}, 0, len(v.Value))
+		for _, elem := range v.Value {
+			list = append(list, wire(elem))
+		}
+		return map[string]interface{}{"L": list}
+	case *types.AttributeValueMemberM:
+		return map[string]interface{}{"M": wireItem(v.Value)}
+	case *types.AttributeValueMemberSS:
+		return map[string]interface{}{"SS": v.Value}
+	default:
+		panic("unsupported attribute value")
+	}
+}
+
+// wireItem converts an item to its DynamoDB JSON form
+func wireItem(item map[string]types.AttributeValue) map[string]interface{} {
+	out := make(map[string]interface{}, len(item))
+	for name, value := range item {
+		out[name] = wire(value)
+	}
+	return out
+}
+
+// wireOf marshals v and converts it to its DynamoDB JSON form
+func wireOf(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	item, err := attributevalue.MarshalMap(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wireItem(item)
+}
+
+// str returns the string of a DynamoDB JSON attribute in a request body
+func str(body map[string]interface{}, path ...string) string {
+	var v interface{} = body
+	for _, key := range path {
+		m, _ := v.(map[string]interface{})
+		v = m[key]
+	}
+	s, _ := v.(string)
+	return s
+}
+
+func TestListTransactionsByStatus(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	to := from.Add(24 * time.Hour)
+	lastKey := map[string]interface{}{
+		"transaction_id": map[string]interface{}{"S": "TXN-1"},
+		"status":         map[string]interface{}{"S": "COMPLETED"},
+		"created_at":     map[string]interface{}{"S": "2026-03-01T10:00:00Z"},
+	}
+
+	tx := domain.NewTransaction("user-1", 10000, "INR", "CAD", &domain.RecipientDetails{})
+	tx.ID, tx.Status = "TXN-1", domain.StatusCompleted
+	repo, fake := newTestRepo(t, func(call dynamoCall) dynamoResponse {
+		return dynamoResponse{body: map[string]interface{}{
+			"Items":            []interface{}{wireOf(t, tx)},
+			"LastEvaluatedKey": lastKey,
+		}}
+	})
+
+	txns, cursor, err := repo.ListTransactionsByStatus(ctx, domain.StatusCompleted, from, to, 1, "")
+	if err != nil {
+		t.Fatalf("ListTransactionsByStatus() = %v", err)
+	}
+	if len(txns) != 1 || txns[0].ID != "TXN-1" || cursor == "" {
+		t.Fatalf("ListTransactionsByStatus() = %d transactions, cursor %q; want TXN-1 and a cursor", len(txns), cursor)
+	}
+
+	query := fake.received()[0].body
+	if query["IndexName"] != StatusIndexName || query["ScanIndexForward"] != true {
+		t.Errorf("query index = %v, forward = %v; want %s oldest first", query["IndexName"], query["ScanIndexForward"], StatusIndexName)
+	}
+	values := query["ExpressionAttributeValues"].(map[string]interface{})
+	if got := str(values, ":status", "S"); got != "COMPLETED" {
+		t.Errorf(":status = %q", got)
+	}
+	if got := str(values, ":from", "S"); got != "2026-02-28T18:30:00Z" {
+		t.Errorf(":from = %q, want the bound in UTC", got)
+	}
+
+	// The cursor resumes the query where it stopped
+	if _, _, err := repo.ListTransactionsByStatus(ctx, domain.StatusCompleted, from, to, 1, cursor); err != nil {
+		t.Fatalf("ListTransactionsByStatus(cursor) = %v", err)
+	}
+	start := fake.received()[1].body["ExclusiveStartKey"].(map[string]interface{})
+	if str(start, "transaction_id", "S") != "TXN-1" || str(start, "created_at", "S") != "2026-03-01T10:00:00Z" {
+		t.Errorf("ExclusiveStartKey = %v, want the last evaluated key", start)
+	}
+
+	// A forged cursor is refused before querying
+	if _, _, err := repo.ListTransactionsByStatus(ctx, domain.StatusCompleted, from, to, 1, "Zm9yZ2Vk"); err != ErrInvalidInput {
+		t.Errorf("forged cursor: error = %v, want ErrInvalidInput", err)
+	}
+	if n := len(fake.received()); n != 2 {
+		t.Errorf("queries = %d, want 2", n)
+	}
+}
+
+func TestCheckIndexes(t *testing.T) {
+	indexes := func(names ...string) dynamoResponse {
+		gsis := make([]interface{}, 0, len(names))
+		for _, name := range names {
+			gsis = append(gsis, map[string]interface{}{"IndexName": name})
+		}
+		return dynamoResponse{body: map[string]interface{}{
+			"Table": map[string]interface{}{"TableName": "transactions", "GlobalSecondaryIndexes": gsis},
+		}}
+	}
+
+	tests := []struct {
+		name          string
+		resp          dynamoResponse
+		wantErr       bool
+		wantMisconfig bool
+		wantInMessage string
+	}{
+		{"all present", indexes(UserIndexName, StatusIndexName, ReferenceIndexName, RecipientIndexName), false, false, ""},
+		{"one missing", indexes(UserIndexName, ReferenceIndexName, RecipientIndexName), true, true, StatusIndexName},
+		{"none", indexes(), true, true, UserIndexName},
+		{"table missing", dynamoResponse{errorType: "ResourceNotFoundException", message: "Requested resource not found"}, true, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, fake := newTestRepo(t, func(call dynamoCall) dynamoResponse { return tt.resp })
+
+			err := repo.CheckIndexes(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckIndexes() = %v, want error %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrIndexMisconfigured) != tt.wantMisconfig {
+				t.Errorf("CheckIndexes() = %v, want ErrIndexMisconfigured %v", err, tt.wantMisconfig)
+			}
+			if tt.wantInMessage != "" && !strings.Contains(err.Error(), tt.wantInMessage) {
+				t.Errorf("CheckIndexes() = %v, want it to name %s", err, tt.wantInMessage)
+			}
+			if call := fake.received()[0]; call.op != "DescribeTable" || call.body["TableName"] != "transactions" {
+				t.Errorf("call = %s %v, want DescribeTable of transactions", call.op, call.body)
+			}
+		})
+	}
+}
+
+func TestQueryMissingIndex(t *testing.T) {
+	tests := []struct {
+		name          string
+		errorType     string
+		message       string
+		wantMisconfig bool
+	}{
+		{"missing index", "ValidationException", "The table does not have the specified index: status-index", true},
+		{"other validation error", "ValidationException", "Invalid KeyConditionExpression", false},
+		{"throttled", "ProvisionedThroughputExceededException", "Rate of requests exceeds the allowed throughput", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := newTestRepo(t, func(call dynamoCall) dynamoResponse {
+				return dynamoResponse{errorType: tt.errorType, message: tt.message}
+			})
+
+			_, _, err := repo.ListTransactionsByStatus(context.Background(), domain.StatusCompleted,
+				time.Now().Add(-time.Hour), time.Now(), 10, "")
+			if err == nil {
+				t.Fatal("ListTransactionsByStatus() = nil, want an error")
+			}
+			if errors.Is(err, ErrIndexMisconfigured) != tt.wantMisconfig {
+				t.Errorf("ListTransactionsByStatus() = %v, want ErrIndexMisconfigured %v", err, tt.wantMisconfig)
+			}
+		})
+	}
+}
+
+func TestGetMissingItem(t *testing.T) {
+	repo, _ := newTestRepo(t, func(call dynamoCall) dynamoResponse {
+		return dynamoResponse{body: map[string]interface{}{}}
+	})
+
+	tests := []struct {
+		name string
+		get  func(ctx context.Context) error
+	}{
+		{"transaction", func(ctx context.Context) error {
+			_, err := repo.GetTransaction(ctx, "TXN-404")
+			return err
+		}},
+		{"payment", func(ctx context.Context) error {
+			_, err := repo.GetPayment(ctx, "PAY-TXN-404")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.get(context.Background()); !errors.Is(err, ErrNotFound) {
+				t.Errorf("get = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestBatchGetTransactions(t *testing.T) {
+	// keysOf returns the IDs a BatchGetItem call asked for
+	keysOf := func(call dynamoCall) []string {
+		request, _ := call.body["RequestItems"].(map[string]interface{})
+		table, _ := request["transactions"].(map[string]interface{})
+		keys, _ := table["Keys"].([]interface{})
+		ids := make([]string, 0, len(keys))
+		for _, key := range keys {
+			ids = append(ids, str(key.(map[string]interface{}), "transaction_id", "S"))
+		}
+		return ids
+	}
+	itemFor := func(id string) map[string]interface{} {
+		tx := domain.NewTransaction("user-1", 10000, "INR", "CAD", &domain.RecipientDetails{})
+		tx.ID = id
+		return wireOf(t, tx)
+	}
+
+	tests := []struct {
+		name        string
+		ids         int
+		unprocessed int // keys left unprocessed by the first call
+		wantCalls   []int
+	}{
+		{"one call", 3, 0, []int{3}},
+		{"chunked", 150, 0, []int{100, 50}},
+		{"unprocessed keys retried", 3, 2, []int{3, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var first sync.Once
+			repo, fake := newTestRepo(t, func(call dynamoCall) dynamoResponse {
+				ids := keysOf(call)
+				var left []interface{}
+				first.Do(func() {
+					for _, id := range ids[len(ids)-tt.unprocessed:] {
+						left = append(left, map[string]interface{}{"transaction_id": map[string]interface{}{"S": id}})
+					}
+					ids = ids[:len(ids)-tt.unprocessed]
+				})
+				items := make([]interface{}, 0, len(ids))
+				for _, id := range ids {
+					items = append(items, itemFor(id))
+				}
+				body := map[string]interface{}{"Responses": map[string]interface{}{"transactions": items}}
+				if len(left) > 0 {
+					body["UnprocessedKeys"] = map[string]interface{}{"transactions": map[string]interface{}{"Keys": left}}
+				}
+				return dynamoResponse{body: body}
+			})
+
+			ids := make([]string, tt.ids)
+			for i := range ids {
+				ids[i] = fmt.Sprintf("TXN-%d", i)
+			}
+			txns, err := repo.BatchGetTransactions(context.Background(), ids)
+			if err != nil {
+				t.Fatalf("BatchGetTransactions() = %v", err)
+			}
+			if len(txns) != tt.ids {
+				t.Errorf("transactions = %d, want %d", len(txns), tt.ids)
+			}
+
+			calls := fake.received()
+			got := make([]int, len(calls))
+			for i, call := range calls {
+				got[i] = len(keysOf(call))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.wantCalls) {
+				t.Errorf("keys per call = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// sparseIndex answers queries over total items of user-1 the way DynamoDB
+// stops short: each call evaluates at most chunk items, returns those keep
+// accepts, and reports a LastEvaluatedKey whenever it stopped before
+// running past the end, even if nothing is left
+func sparseIndex(t *testing.T, total, chunk int, keep func(i int) bool) func(call dynamoCall) dynamoResponse {
+	keyOf := func(i int) map[string]interface{} {
+		return map[string]interface{}{
+			"transaction_id": map[string]interface{}{"S": fmt.Sprintf("TXN-%d", i)},
+			"user_id":        map[string]interface{}{"S": "user-1"},
+			"created_at":     map[string]interface{}{"S": "2026-03-01T10:00:00Z"},
+		}
+	}
+	return func(call dynamoCall) dynamoResponse {
+		start := 0
+		if key, ok := call.body["ExclusiveStartKey"].(map[string]interface{}); ok {
+			fmt.Sscanf(str(key, "transaction_id", "S"), "TXN-%d", &start)
+			start++
+		}
+		limit := int(call.body["Limit"].(float64))
+
+		items := []interface{}{}
+		i := start
+		for ; i < total && i-start < chunk && len(items) < limit; i++ {
+			if keep(i) {
+				tx := domain.NewTransaction("user-1", 10000, "INR", "CAD", &domain.RecipientDetails{})
+				tx.ID = fmt.Sprintf("TXN-%d", i)
+				items = append(items, wireOf(t, tx))
+			}
+		}
+		body := map[string]interface{}{"Items": items}
+		if i < total || i-start == chunk || len(items) == limit {
+			body["LastEvaluatedKey"] = keyOf(i - 1)
+		}
+		return dynamoResponse{body: body}
+	}
+}
+
+func TestListTransactionsFillsPages(t *testing.T) {
+	all := func(i int) bool { return true }
+
+	tests := []struct {
+		name        string
+		total       int
+		chunk       int
+		keep        func(i int) bool
+		limit       int
+		wantPages   []int // transactions per page until the cursor runs out
+		wantQueries int
+	}{
+		{"short results filled", 5, 2, all, 4, []int{4, 1}, 4},
+		{"exact end needs no further page", 4, 2, all, 4, []int{4}, 3},
+		{"full single query", 3, 10, all, 3, []int{3}, 2},
+		{"query budget spent", 30, 2, func(i int) bool { return i >= 25 }, 4, []int{0, 4, 1}, 10 + 6 + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, fake := newTestRepo(t, sparseIndex(t, tt.total, tt.chunk, tt.keep))
+
+			var pages []int
+			cursor := ""
+			for {
+				txns, next, err := repo.ListTransactionsByUser(context.Background(), "user-1", tt.limit, cursor, SortDescending)
+				if err != nil {
+					t.Fatalf("ListTransactionsByUser() = %v", err)
+				}
+				pages = append(pages, len(txns))
+				if next == "" || len(pages) > 10 {
+					break
+				}
+				cursor = next
+			}
+
+			if fmt.Sprint(pages) != fmt.Sprint(tt.wantPages) {
+				t.Errorf("pages = %v, want %v", pages, tt.wantPages)
+			}
+			if n := len(fake.received()); n != tt.wantQueries {
+				t.Errorf("queries = %d, want %d", n, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestUpdateRequireFields(t *testing.T) {
+	repo, fake := newTestRepo(t, nil)
+	startedAt := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	err := repo.UpdateTransactionStatus(context.Background(), "TXN-1", domain.StatusProcessing, domain.StatusFailed,
+		RequireAbsent("transfer_id"), RequireEqual("processing_started_at", startedAt), Set("failure_reason", "stuck"))
+	if err != nil {
+		t.Fatalf("UpdateTransactionStatus() = %v", err)
+	}
+
+	update := fake.received()[0].body
+	expr, _ := update["UpdateExpression"].(string)
+	cond, _ := update["ConditionExpression"].(string)
+	names, _ := update["ExpressionAttributeNames"].(map[string]interface{})
+	values, _ := update["ExpressionAttributeValues"].(map[string]interface{})
+	if cond != "#status = :from AND attribute_not_exists(#f0) AND #f1 = :f1" || names["#f0"] != "transfer_id" || names["#f1"] != "processing_started_at" {
+		t.Errorf("ConditionExpression = %q, names %v; want transfer_id absent and processing_started_at unchanged", cond, names)
+	}
+	if str(values, ":f1", "S") != "2026-03-02T12:00:00Z" {
+		t.Errorf(":f1 = %v, want the claim time", values[":f1"])
+	}
+	if strings.Contains(expr, "#f0") || strings.Contains(expr, "#f1") || !strings.Contains(expr, "#f2 = :f2") {
+		t.Errorf("UpdateExpression = %q, want only failure_reason written", expr)
+	}
+}
+
+func TestListTransactionsByUserCursorOwner(t *testing.T) {
+	cursor, err := encodeCursor(map[string]types.AttributeValue{
+		"transaction_id": &types.AttributeValueMemberS{Value: "TXN-1"},
+		"user_id":        &types.AttributeValueMemberS{Value: "user-1"},
+		"created_at":     &types.AttributeValueMemberS{Value: "2026-03-01T10:00:00Z"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		userID      string
+		wantErr     error
+		wantQueried bool
+	}{
+		{"own cursor", "user-1", nil, true},
+		{"another user's cursor", "user-2", ErrInvalidInput, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, fake := newTestRepo(t, sparseIndex(t, 4, 10, func(i int) bool { return true }))
+
+			_, _, err := repo.ListTransactionsByUser(context.Background(), tt.userID, 2, cursor, SortDescending)
+			if err != tt.wantErr {
+				t.Errorf("ListTransactionsByUser() = %v, want %v", err, tt.wantErr)
+			}
+			if queried := len(fake.received()) > 0; queried != tt.wantQueried {
+				t.Errorf("queried = %v, want %v", queried, tt.wantQueried)
+			}
+		})
+	}
+}
+
+func TestListTransactionsByRecipient(t *testing.T) {
+	ctx := context.Background()
+	hash := domain.RecipientHash("user-1", "12345678")
+	tx := domain.NewTransaction("user-1", 10000, "INR", "CAD", &domain.RecipientDetails{BankAccount: "12345678"})
+	tx.ID = "TXN-1"
+	repo, fake := newTestRepo(t, func(call dynamoCall) dynamoResponse {
+		return dynamoResponse{body: map[string]interface{}{
+			"Items": []interface{}{wireOf(t, tx)},
+			"LastEvaluatedKey": map[string]interface{}{
+				"transaction_id": map[string]interface{}{"S": "TXN-1"},
+				"recipient_hash": map[string]interface{}{"S": hash},
+				"created_at":     map[string]interface{}{"S": "2026-03-01T10:00:00Z"},
+			},
+		}}
+	})
+
+	txns, cursor, err := repo.ListTransactionsByRecipient(ctx, hash, 1, "", SortDescending)
+	if err != nil {
+		t.Fatalf("ListTransactionsByRecipient() = %v", err)
+	}
+	if len(txns) != 1 || txns[0].ID != "TXN-1" || cursor == "" {
+		t.Fatalf("ListTransactionsByRecipient() = %d transactions, cursor %q; want TXN-1 and a cursor", len(txns), cursor)
+	}
+	query := fake.received()[0].body
+	values := query["ExpressionAttributeValues"].(map[string]interface{})
+	if query["IndexName"] != RecipientIndexName || str(values, ":hash", "S") != hash || query["ScanIndexForward"] != false {
+		t.Errorf("query index = %v, :hash = %q, forward = %v; want %s by the hash, latest first",
+			query["IndexName"], str(values, ":hash", "S"), query["ScanIndexForward"], RecipientIndexName)
+	}
+	if item := wireOf(t, tx); str(item, "recipient_hash", "S") != hash {
+		t.Errorf("stored recipient_hash = %q, want %q", str(item, "recipient_hash", "S"), hash)
+	}
+
+	// The cursor resumes this listing only
+	if _, _, err := repo.ListTransactionsByRecipient(ctx, hash, 1, cursor, SortDescending); err != nil {
+		t.Fatalf("ListTransactionsByRecipient(cursor) = %v", err)
+	}
+	queries := len(fake.received())
+	other := domain.RecipientHash("user-1", "87654321")
+	if _, _, err := repo.ListTransactionsByRecipient(ctx, other, 1, cursor, SortDescending); err != ErrInvalidInput {
+		t.Errorf("other recipient's cursor: error = %v, want ErrInvalidInput", err)
+	}
+	if n := len(fake.received()); n != queries {
+		t.Errorf("queries = %d, want no more than the %d before the refused cursor", n, queries)
+	}
+}
+
+func TestUpdateRemoveField(t *testing.T) {
+	repo, fake := newTestRepo(t, nil)
+
+	err := repo.UpdateTransactionStatus(context.Background(), "TXN-1", domain.StatusCompleted, domain.StatusCompleted,
+		Set("failure_reason", "redacted"), Remove("recipient_hash"))
+	if err != nil {
+		t.Fatalf("UpdateTransactionStatus() = %v", err)
+	}
+
+	update := fake.received()[0].body
+	expr, _ := update["UpdateExpression"].(string)
+	names, _ := update["ExpressionAttributeNames"].(map[string]interface{})
+	if !strings.HasSuffix(expr, " REMOVE #f1") || names["#f1"] != "recipient_hash" || !strings.Contains(expr, "#f0 = :f0") {
+		t.Errorf("UpdateExpression = %q, names %v; want failure_reason set and recipient_hash removed", expr, names)
+	}
+	if values, _ := update["ExpressionAttributeValues"].(map[string]interface{}); values[":f1"] != nil {
+		t.Errorf("removed field has a value: %v", values[":f1"])
+	}
+}
+
+func TestListTransactionsByReference(t *testing.T) {
+	all := func(i int) bool { return true }
+
+	tests := []struct {
+		name     string
+		total    int
+		limit    int
+		wantLen  int
+		wantMore bool
+	}{
+		{"no matches", 0, 10, 0, false},
+		{"few matches", 3, 10, 3, false},
+		{"more than a page", 150, 100, 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, fake := newTestRepo(t, sparseIndex(t, tt.total, 1000, all))
+
+			txns, cursor, err := repo.ListTransactionsByReference(context.Background(), "invoice-42", tt.limit, "")
+			if err != nil {
+				t.Fatalf("ListTransactionsByReference() = %v", err)
+			}
+			if len(txns) != tt.wantLen || (cursor != "") != tt.wantMore {
+				t.Errorf("ListTransactionsByReference() = %d transactions, cursor %q; want %d, more %v", len(txns), cursor, tt.wantLen, tt.wantMore)
+			}
+
+			query := fake.received()[0].body
+			if query["IndexName"] != ReferenceIndexName || query["KeyConditionExpression"] != "reference = :ref" {
+				t.Errorf("query = %v %v, want %s by reference", query["IndexName"], query["KeyConditionExpression"], ReferenceIndexName)
+			}
+			values := query["ExpressionAttributeValues"].(map[string]interface{})
+			if got := str(values, ":ref", "S"); got != "invoice-42" {
+				t.Errorf(":ref = %q, want invoice-42", got)
+			}
+		})
+	}
+}
+
+func TestListTransactionsByReferenceCursor(t *testing.T) {
+	ctx := context.Background()
+	tx := domain.NewTransaction("user-1", 10000, "INR", "CAD", &domain.RecipientDetails{})
+	tx.ID = "TXN-1"
+	repo, fake := newTestRepo(t, func(call dynamoCall) dynamoResponse {
+		return dynamoResponse{body: map[string]interface{}{
+			"Items": []interface{}{wireOf(t, tx)},
+			"LastEvaluatedKey": map[string]interface{}{
+				"transaction_id": map[string]interface{}{"S": "TXN-1"},
+				"reference":      map[string]interface{}{"S": "invoice-42"},
+			},
+		}}
+	})
+
+	_, cursor, err := repo.ListTransactionsByReference(ctx, "invoice-42", 1, "")
+	if err != nil || cursor == "" {
+		t.Fatalf("ListTransactionsByReference() cursor = %q, error = %v; want a cursor", cursor, err)
+	}
+
+	// The cursor resumes this search only
+	if _, _, err := repo.ListTransactionsByReference(ctx, "invoice-42", 1, cursor); err != nil {
+		t.Fatalf("ListTransactionsByReference(cursor) = %v", err)
+	}
+	start := fake.received()[len(fake.received())-1].body["ExclusiveStartKey"].(map[string]interface{})
+	if str(start, "transaction_id", "S") != "TXN-1" {
+		t.Errorf("ExclusiveStartKey = %v, want TXN-1", start)
+	}
+	if _, _, err := repo.ListTransactionsByReference(ctx, "invoice-43", 1, cursor); err != ErrInvalidInput {
+		t.Errorf("other reference's cursor: error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestListTransactionsByUserOrder(t *testing.T) {
+	tests := []struct {
+		order       SortOrder
+		wantForward bool
+	}{
+		{SortDescending, false},
+		{SortAscending, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			repo, fake := newTestRepo(t, nil)
+
+			if _, _, err := repo.ListTransactionsByUser(context.Background(), "user-1", 10, "", tt.order); err != nil {
+				t.Fatalf("ListTransactionsByUser() = %v", err)
+			}
+			query := fake.received()[0].body
+			if query["IndexName"] != UserIndexName || query["ScanIndexForward"] != tt.wantForward {
+				t.Errorf("query index = %v, forward = %v; want %s, forward %v", query["IndexName"], query["ScanIndexForward"], UserIndexName, tt.wantForward)
+			}
+		})
+	}
+}
+
+// unmarshalable is a field type whose DynamoDB encoding always fails
+type unmarshalable struct{}
+
+func (unmarshalable) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return nil, errors.New("no DynamoDB encoding")
+}
+
+func TestMarshalFailure(t *testing.T) {
+	// The SDK silently drops channel and function fields, so failures come
+	// from map keys it cannot encode and from custom encoders
+	type structKey struct{ Currency string }
+	type withMoney struct {
+		ID     string        `dynamodbav:"id"`
+		Amount unmarshalable `dynamodbav:"amount"`
+	}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr bool
+	}{
+		{"supported", domain.NewTransaction("user-1", 10000, "INR", "CAD", &domain.RecipientDetails{}), false},
+		{"struct map keys", map[structKey]float64{{"INR"}: 10000}, true},
+		{"failing field encoder", &withMoney{ID: "x"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := marshalMap("test value", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("marshalMap() = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (!errors.Is(err, ErrSerialization) || !strings.Contains(err.Error(), "test value")) {
+				t.Errorf("marshalMap() = %v, want ErrSerialization naming the value", err)
+			}
+		})
+	}
+}
+
+func TestUpdateFieldMarshalFailure(t *testing.T) {
+	repo, fake := newTestRepo(t, nil)
+
+	err := repo.UpdateTransactionStatus(context.Background(), "TXN-1", domain.StatusProcessing, domain.StatusProcessing,
+		Set("metadata", unmarshalable{}))
+	if !errors.Is(err, ErrSerialization) || !strings.Contains(err.Error(), "metadata") {
+		t.Errorf("UpdateTransactionStatus() = %v, want ErrSerialization naming metadata", err)
+	}
+	if n := len(fake.received()); n != 0 {
+		t.Errorf("DynamoDB calls = %d, want none", n)
+	}
+}
+
+func TestItemSize(t *testing.T) {
+	tests := []struct {
+		name string
+		item map[string]types.AttributeValue
+		want int
+	}{
+		{"string", map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "TXN-1"}}, 7},
+		{"number", map[string]types.AttributeValue{"n": &types.AttributeValueMemberN{Value: "12.5"}}, 5},
+		{"bool", map[string]types.AttributeValue{"ok": &types.AttributeValueMemberBOOL{Value: true}}, 3},
+		{"map", map[string]types.AttributeValue{"m": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"k": &types.AttributeValueMemberS{Value: "v"},
+		}}}, 7},
+		{"list", map[string]types.AttributeValue{"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "ab"},
+			&types.AttributeValueMemberNULL{Value: true},
+		}}}, 9},
+		{"string set", map[string]types.AttributeValue{"ss": &types.AttributeValueMemberSS{Value: []string{"ab", "cde"}}}, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := itemSize(tt.item); got != tt.want {
+				t.Errorf("itemSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTransactionTooLarge(t *testing.T) {
+	tests := []struct {
+		name     string
+		nameSize int // of the recipient name
+		wantErr  bool
+	}{
+		{"within the limit", 1024, false},
+		{"over the limit", maxItemSize, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, fake := newTestRepo(t, nil)
+			tx := domain.NewTransaction("user-1", 10000, "INR", "CAD", &domain.RecipientDetails{
+				Name: strings.Repeat("x", tt.nameSize), BankAccount: "12345678", BankCode: "TD001",
+			})
+
+			err := repo.CreateTransaction(context.Background(), tx)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("CreateTransaction() = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrItemTooLarge) {
+				t.Fatalf("CreateTransaction() = %v, want ErrItemTooLarge", err)
+			}
+			if n := len(fake.received()); n != 0 {
+				t.Errorf("DynamoDB calls = %d, want none", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxItemSize is DynamoDB's limit on the size of one item
+const maxItemSize = 400 * 1024
+
+// marshalMap marshals v into a DynamoDB item. A failure is a schema mistake,
+// such as a field of a type DynamoDB cannot store, rather than a storage
+// fault: it is logged with the offending type and returned as
+// ErrSerialization. An item over maxItemSize returns ErrItemTooLarge, so
+// callers get a clear error rather than DynamoDB's ValidationException.
+func marshalMap(what string, v interface{}) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(v)
+	if err != nil {
+		return nil, serializationError(what, v, err)
+	}
+	if size := itemSize(item); size > maxItemSize {
+		return nil, fmt.Errorf("%s of about %d bytes: %w", what, size, ErrItemTooLarge)
+	}
+	return item, nil
+}
+
+// itemSize estimates the stored size of an item following DynamoDB's rules:
+// attribute names plus values, with a few bytes of overhead per list and map.
+// Numbers are counted by their digits, which overestimates slightly.
+func itemSize(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, value := range item {
+		size += len(name) + valueSize(value)
+	}
+	return size
+}
+
+func valueSize(value types.AttributeValue) int {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return len(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += len(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, elem := range v.Value {
+			size += 1 + valueSize(elem)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := 3
+		for name, elem := range v.Value {
+			size += 1 + len(name) + valueSize(elem)
+		}
+		return size
+	default: // BOOL, NULL
+		return 1
+	}
+}
+
+// marshal marshals a single attribute value, failing like marshalMap
+func marshal(what string, v interface{}) (types.AttributeValue, error) {
+	value, err := attributevalue.Marshal(v)
+	if err != nil {
+		return nil, serializationError(what, v, err)
+	}
+	return value, nil
+}
+
+func serializationError(what string, v interface{}, err error) error {
+	log.Printf("serialization error, check the struct tags and field types: what=%s type=%T error=%v", what, v, err)
+	return fmt.Errorf("failed to marshal %s: %w: %w", what, ErrSerialization, err)
+}
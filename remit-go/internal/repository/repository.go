@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"github.com/remit-demo/remit-go/internal/domain"
 )
 
@@ -11,12 +13,95 @@ type Repository interface {
 	CreateTransaction(ctx context.Context, tx *domain.Transaction) error
 	GetTransaction(ctx context.Context, id string) (*domain.Transaction, error)
 	UpdateTransaction(ctx context.Context, tx *domain.Transaction) error
-	ListTransactionsByUser(ctx context.Context, userID string, limit int, lastKey string) ([]*domain.Transaction, string, error)
-	
+	UpdateTransactionStatus(ctx context.Context, id string, from, to domain.TransactionStatus, fields ...Field) error
+	BatchGetTransactions(ctx context.Context, ids []string) ([]*domain.Transaction, error)
+	ListTransactionsByUser(ctx context.Context, userID string, limit int, lastKey string, order SortOrder) ([]*domain.Transaction, string, error)
+	ListTransactionsByReference(ctx context.Context, reference string, limit int, lastKey string) ([]*domain.Transaction, string, error)
+	ListTransactionsByRecipient(ctx context.Context, recipientHash string, limit int, lastKey string, order SortOrder) ([]*domain.Transaction, string, error)
+	ListTransactionsByStatus(ctx context.Context, status domain.TransactionStatus, from, to time.Time, limit int, cursor string) ([]*domain.Transaction, string, error)
+
 	// Payment operations
 	CreatePayment(ctx context.Context, txID string, payment *domain.PaymentDetails) error
 	UpdatePayment(ctx context.Context, txID string, payment *domain.PaymentDetails) error
 	GetPayment(ctx context.Context, paymentID string) (*domain.PaymentDetails, error)
+
+	// Exchange rate operations
+	SaveRate(ctx context.Context, rate *domain.ExchangeRate) error
+	GetLastRate(ctx context.Context, sourceCurrency, targetCurrency string) (*domain.ExchangeRate, error)
+
+	// Idempotency operations
+	PutIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error
+	GetIdempotencyRecord(ctx context.Context, key string) (*domain.IdempotencyRecord, error)
+	CompleteIdempotencyRecord(ctx context.Context, key, txID string) error
+	DeleteIdempotencyRecord(ctx context.Context, key string) error
+
+	// Dead letter operations
+	PutDeadLetter(ctx context.Context, dl *domain.DeadLetter) error
+	GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error)
+	ListDeadLetters(ctx context.Context, limit int, cursor string) ([]*domain.DeadLetter, string, error)
+	DeleteDeadLetter(ctx context.Context, id string) error
+
+	// Ping checks the store is reachable
+	Ping(ctx context.Context) error
+}
+
+// Field is a transaction attribute to set alongside a status change
+type Field struct {
+	Name  string // DynamoDB attribute name
+	Value interface{}
+
+	// IfAbsent fails the whole update with ErrStatusMismatch when the
+	// attribute is already set
+	IfAbsent bool
+
+	// Remove deletes the attribute instead of setting it
+	Remove bool
+
+	// Require writes nothing; it fails the whole update with
+	// ErrStatusMismatch unless the attribute equals Value, or is absent
+	// when Value is nil
+	Require bool
+}
+
+// Set returns a Field setting the named attribute to value
+func Set(name string, value interface{}) Field {
+	return Field{Name: name, Value: value}
+}
+
+// SetIfAbsent returns a Field setting the named attribute to value only if
+// it has no value yet, so concurrent writers can claim it once
+func SetIfAbsent(name string, value interface{}) Field {
+	return Field{Name: name, Value: value, IfAbsent: true}
+}
+
+// Remove returns a Field deleting the named attribute
+func Remove(name string) Field {
+	return Field{Name: name, Remove: true}
+}
+
+// RequireAbsent returns a Field conditioning the update on the named
+// attribute having no value
+func RequireAbsent(name string) Field {
+	return Field{Name: name, Require: true}
+}
+
+// RequireEqual returns a Field conditioning the update on the named
+// attribute still holding value
+func RequireEqual(name string, value interface{}) Field {
+	return Field{Name: name, Value: value, Require: true}
+}
+
+// SortOrder is the creation time order of a listing
+type SortOrder string
+
+const (
+	SortDescending SortOrder = "desc" // latest first
+	SortAscending  SortOrder = "asc"  // oldest first
+)
+
+// Valid reports whether o is a known sort order
+func (o SortOrder) Valid() bool {
+	return o == SortDescending || o == SortAscending
 }
 
 // Error types for repository operations
@@ -26,8 +111,24 @@ const (
 	ErrNotFound      Error = "not_found"
 	ErrAlreadyExists Error = "already_exists"
 	ErrInvalidInput  Error = "invalid_input"
+
+	// ErrStatusMismatch means the item was not in the expected status, an
+	// attribute set with SetIfAbsent already had a value, or a condition
+	// of RequireAbsent or RequireEqual did not hold
+	ErrStatusMismatch Error = "status_mismatch"
+
+	// ErrIndexMisconfigured means a required GSI is missing from the table
+	ErrIndexMisconfigured Error = "index_misconfigured"
+
+	// ErrSerialization means a value could not be marshaled for DynamoDB, a
+	// schema mistake rather than a storage fault
+	ErrSerialization Error = "serialization"
+
+	// ErrItemTooLarge means an item would exceed DynamoDB's item size limit
+	// and was not written
+	ErrItemTooLarge Error = "item_too_large"
 )
 
 func (e Error) Error() string {
 	return string(e)
-} 
\ No newline at end of file
+}
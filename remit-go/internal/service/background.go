@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/metrics"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// Background task names, used as metric labels
+const (
+	taskTransfer        = "transfer"
+	taskPaymentReminder = "payment_reminder"
+)
+
+type taskIDKey struct{}
+
+// TaskID returns the background task ID carried by ctx, if any
+func TaskID(ctx context.Context) string {
+	id, _ := ctx.Value(taskIDKey{}).(string)
+	return id
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying the ID of the API request it serves
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the API request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// detachedContext returns a root context for work that outlives the
+// request behind parent, keeping only its request ID so the work's log
+// lines can be tied back to the request
+func detachedContext(parent context.Context) context.Context {
+	ctx := context.Background()
+	if id := RequestID(parent); id != "" {
+		ctx = WithRequestID(ctx, id)
+	}
+	return ctx
+}
+
+// runInBackground runs fn in its own goroutine, detached from the request
+// in ctx that triggered it. Each run gets a fresh root context tagged with a
+// task ID and the request's ID, both logged so its log lines can be tied to
+// each other and to the request, and is counted in the background task
+// metrics. A failed run is dead-lettered under its task ID with payload and
+// the request ID, so it can be inspected and replayed. A run that finds the
+// transaction already moved on by another writer reports ErrInvalidStatus;
+// that is expected and only logged.
+func (s *RemittanceService) runInBackground(
+	ctx context.Context,
+	task, txID string,
+	payload map[string]string,
+	fn func(ctx context.Context) error,
+) {
+	taskID := newTaskID()
+	requestID := RequestID(ctx)
+	ctx = context.WithValue(detachedContext(ctx), taskIDKey{}, taskID)
+
+	go func() {
+		start := time.Now()
+		metrics.BackgroundTasksStarted.WithLabelValues(task).Inc()
+		log.Printf("background %s started: task_id=%s request_id=%s transaction_id=%s", task, taskID, requestID, txID)
+
+		err := fn(ctx)
+		if errors.Is(err, ErrInvalidStatus) {
+			log.Printf("background %s skipped: task_id=%s request_id=%s transaction_id=%s reason=status changed",
+				task, taskID, requestID, txID)
+			return
+		}
+		if err != nil {
+			metrics.BackgroundTasksFailed.WithLabelValues(task).Inc()
+			log.Printf("background %s failed: task_id=%s request_id=%s transaction_id=%s duration=%s error=%v",
+				task, taskID, requestID, txID, time.Since(start), err)
+			if requestID != "" {
+				payload = maps.Clone(payload)
+				if payload == nil {
+					payload = make(map[string]string, 1)
+				}
+				payload["request_id"] = requestID
+			}
+			s.deadLetter(ctx, domain.NewDeadLetter(taskID, task, txID, payload, err))
+			return
+		}
+
+		log.Printf("background %s completed: task_id=%s request_id=%s transaction_id=%s duration=%s",
+			task, taskID, requestID, txID, time.Since(start))
+	}()
+}
+
+// deadLetter stores a failed operation, logging if even that fails
+func (s *RemittanceService) deadLetter(ctx context.Context, dl *domain.DeadLetter) {
+	if err := s.repo.PutDeadLetter(ctx, dl); err != nil {
+		log.Printf("failed to dead-letter %s: id=%s transaction_id=%s error=%v", dl.Operation, dl.ID, dl.TransactionID, err)
+	}
+}
+
+// ListDeadLetters pages through failed background operations
+func (s *RemittanceService) ListDeadLetters(ctx context.Context, limit int, cursor string) ([]*domain.DeadLetter, string, error) {
+	return s.repo.ListDeadLetters(ctx, limit, cursor)
+}
+
+// ReplayDeadLetter runs a dead-lettered operation again, in the caller's
+// context. The dead letter is removed once the operation succeeds and kept
+// with the new error otherwise.
+func (s *RemittanceService) ReplayDeadLetter(ctx context.Context, id string) error {
+	dl, err := s.repo.GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var runErr error
+	switch dl.Operation {
+	case taskTransfer:
+		runErr = s.replayTransfer(ctx, dl.TransactionID)
+	case taskPaymentReminder:
+		runErr = s.replayPaymentReminder(ctx, dl.TransactionID)
+	default:
+		return ErrUnknownOperation
+	}
+	if runErr != nil {
+		dl.Error = runErr.Error()
+		s.deadLetter(ctx, dl)
+		return runErr
+	}
+
+	if err := s.repo.DeleteDeadLetter(ctx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+// replayTransfer retries a dead-lettered transfer. The failed run left the
+// transaction FAILED, so it is reset to PAYMENT_RECEIVED first, unless it
+// failed for a reason no transfer can fix.
+func (s *RemittanceService) replayTransfer(ctx context.Context, txID string) error {
+	tx, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if tx.Status == domain.StatusFailed {
+		switch tx.FailureReason {
+		case domain.FailureReasonPaymentFailed, domain.FailureReasonReviewRejected:
+			return fmt.Errorf("transaction failed with %s: %w", tx.FailureReason, ErrInvalidStatus)
+		}
+		err := s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusFailed, domain.StatusPaymentReceived,
+			repository.Remove("failure_reason"))
+		if errors.Is(err, repository.ErrStatusMismatch) {
+			return ErrInvalidStatus
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update transaction: %w", err)
+		}
+		tx.UpdateStatus(domain.StatusPaymentReceived)
+		tx.FailureReason = ""
+	}
+	return s.retryTransfer(ctx, tx)
+}
+
+func newTaskID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
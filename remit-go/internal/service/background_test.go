@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/integration"
+	"github.com/remit-demo/remit-go/internal/metrics"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// deadLetters returns the stored dead letters
+func (e *testEnv) deadLetters(t *testing.T) []*domain.DeadLetter {
+	t.Helper()
+	dls, _, err := e.svc.ListDeadLetters(context.Background(), 0, "")
+	if err != nil {
+		t.Fatalf("ListDeadLetters() = %v", err)
+	}
+	return dls
+}
+
+func TestRunInBackgroundDeadLetters(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantDeadLetter bool
+	}{
+		{"success", nil, false},
+		{"failure", errors.New("boom"), true},
+		{"status changed meanwhile", ErrInvalidStatus, false},
+		{"wrapped status change", fmt.Errorf("retry: %w", ErrInvalidStatus), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			done := make(chan struct{})
+			env.svc.runInBackground(context.Background(), taskTransfer, "tx-1", nil, func(ctx context.Context) error {
+				defer close(done)
+				if TaskID(ctx) == "" {
+					t.Error("background context has no task ID")
+				}
+				return tt.err
+			})
+			<-done
+
+			if tt.wantDeadLetter {
+				waitFor(t, "the dead letter", func() bool { return len(env.deadLetters(t)) == 1 })
+				if dl := env.deadLetters(t)[0]; dl.Operation != taskTransfer || dl.TransactionID != "tx-1" {
+					t.Errorf("dead letter = %+v, want the transfer of tx-1", dl)
+				}
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+			if dls := env.deadLetters(t); len(dls) != 0 {
+				t.Fatalf("dead letters = %d, want none", len(dls))
+			}
+		})
+	}
+}
+
+func TestReplayDeadLetterTransfer(t *testing.T) {
+	tests := []struct {
+		name          string
+		reason        string // failure reason set before the replay, if any
+		wantErr       error
+		wantStatus    domain.TransactionStatus
+		wantRemaining int
+	}{
+		{"transfer failure is retried", "", nil, domain.StatusProcessing, 0},
+		{"rejected in review meanwhile", domain.FailureReasonReviewRejected, ErrInvalidStatus, domain.StatusFailed, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t)
+			env.wise.errs = []error{&integration.TransferError{Code: "insufficient_funds"}}
+			tx := env.awaitingPayment(t, "user-1", 10000)
+
+			err := env.svc.HandlePaymentCallback(ctx, &PaymentCallback{
+				PaymentID: domain.PaymentID(tx.ID),
+				Status:    PaymentCallbackSuccess,
+			})
+			if err != nil {
+				t.Fatalf("HandlePaymentCallback() = %v", err)
+			}
+			waitFor(t, "the dead letter", func() bool { return len(env.deadLetters(t)) == 1 })
+			if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusFailed || got.FailureReason != "insufficient_funds" {
+				t.Fatalf("after the failed transfer: status = %s, reason = %q", got.Status, got.FailureReason)
+			}
+			if tt.reason != "" {
+				env.move(t, tx.ID, domain.StatusFailed, repository.Set("failure_reason", tt.reason))
+			}
+
+			dl := env.deadLetters(t)[0]
+			err = env.svc.ReplayDeadLetter(ctx, dl.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReplayDeadLetter() = %v, want %v", err, tt.wantErr)
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if tt.wantErr == nil && (got.TransferID == "" || got.FailureReason != "") {
+				t.Errorf("transfer = %q, reason = %q; want a transfer and no failure reason", got.TransferID, got.FailureReason)
+			}
+			if n := len(env.deadLetters(t)); n != tt.wantRemaining {
+				t.Errorf("dead letters left = %d, want %d", n, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestReplayDeadLetterPaymentReminder(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        domain.TransactionStatus // moved to before the replay, if set
+		wantErr       error
+		wantSent      int
+		wantRemaining int
+	}{
+		{"reminder is sent", "", nil, 1, 0},
+		{"paid meanwhile", domain.StatusPaymentReceived, ErrInvalidStatus, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.PaymentLinkValidity = 10 * time.Minute
+				cfg.PaymentReminderLead = 15 * time.Minute
+			})
+			tx := env.awaitingPayment(t, "user-1", 10000)
+
+			env.notifier.err = errors.New("smtp down")
+			if err := env.svc.SendPaymentReminders(ctx); err != nil {
+				t.Fatalf("SendPaymentReminders() = %v", err)
+			}
+			dls := env.deadLetters(t)
+			if len(dls) != 1 || dls[0].Operation != taskPaymentReminder || dls[0].TransactionID != tx.ID {
+				t.Fatalf("dead letters = %+v, want the reminder for %s", dls, tx.ID)
+			}
+			env.notifier.err = nil
+			if tt.status != "" {
+				env.move(t, tx.ID, tt.status)
+			}
+
+			err := env.svc.ReplayDeadLetter(ctx, dls[0].ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReplayDeadLetter() = %v, want %v", err, tt.wantErr)
+			}
+			if n := len(env.notifier.sent); n != tt.wantSent {
+				t.Errorf("reminders sent = %d, want %d", n, tt.wantSent)
+			}
+			if n := len(env.deadLetters(t)); n != tt.wantRemaining {
+				t.Errorf("dead letters left = %d, want %d", n, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestRunInBackgroundCarriesRequestID(t *testing.T) {
+	env := newTestEnv(t)
+	parent, cancel := context.WithCancel(WithRequestID(context.Background(), "req-1"))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	env.svc.runInBackground(parent, taskTransfer, "tx-1", map[string]string{"transaction_id": "tx-1"}, func(ctx context.Context) error {
+		close(started)
+		<-release
+		if got := RequestID(ctx); got != "req-1" {
+			t.Errorf("RequestID() = %q, want req-1", got)
+		}
+		if err := ctx.Err(); err != nil {
+			t.Errorf("background context ended with its request: %v", err)
+		}
+		return errors.New("boom")
+	})
+	<-started
+	cancel() // the request finishes first
+	close(release)
+
+	waitFor(t, "the dead letter", func() bool { return len(env.deadLetters(t)) == 1 })
+	payload := env.deadLetters(t)[0].Payload
+	if payload["request_id"] != "req-1" || payload["transaction_id"] != "tx-1" {
+		t.Errorf("dead letter payload = %v, want the request ID added", payload)
+	}
+}
+
+func TestRunInBackgroundMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantFailed float64
+	}{
+		{"success", nil, 0},
+		{"failure", errors.New("boom"), 1},
+		{"status changed meanwhile", ErrInvalidStatus, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			task := "test-" + t.Name() // a label of its own, so runs do not add up
+			done := make(chan struct{})
+			env.svc.runInBackground(context.Background(), task, "tx-1", nil, func(ctx context.Context) error {
+				defer close(done)
+				return tt.err
+			})
+			<-done
+
+			time.Sleep(20 * time.Millisecond) // let the run record its outcome
+			if got := testutil.ToFloat64(metrics.BackgroundTasksStarted.WithLabelValues(task)); got != 1 {
+				t.Errorf("started = %v, want 1", got)
+			}
+			if got := testutil.ToFloat64(metrics.BackgroundTasksFailed.WithLabelValues(task)); got != tt.wantFailed {
+				t.Errorf("failed = %v, want %v", got, tt.wantFailed)
+			}
+		})
+	}
+}
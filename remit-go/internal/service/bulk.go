@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// bulkActionConcurrency bounds how many transactions a bulk action works on
+// at once
+const bulkActionConcurrency = 8
+
+// maxBulkActionTransactions is the most transactions one bulk action may
+// affect; a wider filter is refused before anything is changed
+const maxBulkActionTransactions = 1000
+
+// BulkAction is what a bulk action does to each matching transaction
+type BulkAction string
+
+const (
+	BulkActionFail  BulkAction = "fail"  // fail with the given reason
+	BulkActionRetry BulkAction = "retry" // send the Wise transfer again
+)
+
+// BulkActionRequest selects the transactions in a status created within
+// [From, To] and the action to apply to them. ConfirmCount must equal the
+// number of matching transactions, so the caller has seen what is affected.
+type BulkActionRequest struct {
+	Status       domain.TransactionStatus
+	From         time.Time
+	To           time.Time
+	Action       BulkAction
+	Reason       string // failure reason recorded by BulkActionFail
+	ConfirmCount int
+}
+
+// BulkActionResult is the outcome of a bulk action on one transaction
+type BulkActionResult struct {
+	TransactionID string                   `json:"transaction_id"`
+	Status        domain.TransactionStatus `json:"status,omitempty"` // after the action
+	Error         string                   `json:"error,omitempty"`
+}
+
+// BulkConfirmationError means ConfirmCount did not match the number of
+// transactions the bulk action would affect. Nothing was changed.
+type BulkConfirmationError struct {
+	Affected int
+}
+
+func (e *BulkConfirmationError) Error() string {
+	return fmt.Sprintf("bulk action would affect %d transactions", e.Affected)
+}
+
+// ApplyBulkAction applies an action to every transaction matching the
+// request's filter, at most bulkActionConcurrency at a time, and reports
+// the outcome per transaction. A transaction that fails the action does not
+// stop the others. A filter matching more than maxBulkActionTransactions is
+// refused.
+func (s *RemittanceService) ApplyBulkAction(ctx context.Context, req *BulkActionRequest) ([]BulkActionResult, error) {
+	if err := validateBulkAction(req); err != nil {
+		return nil, err
+	}
+
+	tooMany := &ValidationError{
+		Err:     ErrInvalidBulkAction,
+		Message: fmt.Sprintf("more than %d transactions match; narrow the date range", maxBulkActionTransactions),
+	}
+	var txns []*domain.Transaction
+	err := s.forEachTransactionByStatus(ctx, req.Status, req.From, req.To, func(tx *domain.Transaction) error {
+		if len(txns) == maxBulkActionTransactions {
+			return tooMany
+		}
+		txns = append(txns, tx)
+		return nil
+	})
+	if errors.Is(err, tooMany) {
+		return nil, tooMany
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) != req.ConfirmCount {
+		return nil, &BulkConfirmationError{Affected: len(txns)}
+	}
+
+	results := make([]BulkActionResult, len(txns))
+	sem := make(chan struct{}, bulkActionConcurrency)
+	var wg sync.WaitGroup
+	for i, tx := range txns {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = s.applyBulkAction(ctx, req, tx)
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func validateBulkAction(req *BulkActionRequest) error {
+	if !slices.Contains(domain.Statuses, req.Status) {
+		return &ValidationError{Err: ErrInvalidBulkAction, Message: "unknown status"}
+	}
+	if req.Status == domain.StatusCompleted || req.Status == domain.StatusFailed {
+		return &ValidationError{Err: ErrInvalidBulkAction, Message: "finished transactions cannot be changed"}
+	}
+	if !req.From.Before(req.To) {
+		return ErrInvalidDateRange
+	}
+
+	switch req.Action {
+	case BulkActionFail:
+		if req.Reason == "" {
+			return &ValidationError{Err: ErrInvalidBulkAction, Message: "fail requires a reason"}
+		}
+	case BulkActionRetry:
+	default:
+		return &ValidationError{Err: ErrInvalidBulkAction, Message: "action must be fail or retry"}
+	}
+	return nil
+}
+
+func (s *RemittanceService) applyBulkAction(ctx context.Context, req *BulkActionRequest, tx *domain.Transaction) BulkActionResult {
+	result := BulkActionResult{TransactionID: tx.ID}
+
+	var err error
+	switch req.Action {
+	case BulkActionFail:
+		err = s.bulkFail(ctx, tx, req.Reason)
+		result.Status = domain.StatusFailed
+	case BulkActionRetry:
+		err = s.retryTransfer(ctx, tx)
+		result.Status = domain.StatusProcessing
+	}
+
+	if err != nil {
+		result.Status = ""
+		result.Error = err.Error()
+		if errors.Is(err, repository.ErrStatusMismatch) {
+			result.Error = "status changed since the transactions were selected"
+		}
+	}
+	return result
+}
+
+// bulkFail fails one transaction. A PROCESSING transaction with a Wise
+// transfer is refused, as the transfer may still pay out; one without is
+// failed only while still unclaimed, see unclaimed.
+func (s *RemittanceService) bulkFail(ctx context.Context, tx *domain.Transaction, reason string) error {
+	fields := []repository.Field{repository.Set("failure_reason", reason)}
+	if tx.Status == domain.StatusProcessing {
+		if tx.TransferID != "" {
+			return fmt.Errorf("transaction has Wise transfer %s: %w", tx.TransferID, ErrInvalidStatus)
+		}
+		fields = append(fields, unclaimed(tx)...)
+	}
+	return s.repo.UpdateTransactionStatus(ctx, tx.ID, tx.Status, domain.StatusFailed, fields...)
+}
+
+// retryTransfer sends the Wise transfer of a paid transaction again: one
+// whose transfer was never started, or one left PROCESSING without a
+// transfer ID. The latter may duplicate a transfer Wise did create. Putting
+// it back is conditional on the transaction being as read, see unclaimed,
+// and fails with ErrStatusMismatch otherwise.
+func (s *RemittanceService) retryTransfer(ctx context.Context, tx *domain.Transaction) error {
+	switch {
+	case tx.Status == domain.StatusPaymentReceived:
+	case tx.Status == domain.StatusProcessing && tx.TransferID == "":
+		err := s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusProcessing, domain.StatusPaymentReceived, unclaimed(tx)...)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%s transaction cannot be retried: %w", tx.Status, ErrInvalidStatus)
+	}
+	return s.InitiateTransfer(ctx, tx.ID)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+func TestApplyBulkActionFail(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	var matching []*domain.Transaction
+	for range 3 {
+		matching = append(matching, env.seed("user-1", 10000, domain.StatusProcessing, now.Add(-2*time.Hour)))
+	}
+	earlier := env.seed("user-1", 10000, domain.StatusProcessing, now.Add(-48*time.Hour))
+	otherStatus := env.seed("user-1", 10000, domain.StatusPaymentReceived, now.Add(-2*time.Hour))
+
+	req := &BulkActionRequest{
+		Status:       domain.StatusProcessing,
+		From:         now.Add(-24 * time.Hour),
+		To:           now,
+		Action:       BulkActionFail,
+		Reason:       "corridor_outage",
+		ConfirmCount: 3,
+	}
+	results, err := env.svc.ApplyBulkAction(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ApplyBulkAction() = %v", err)
+	}
+
+	if len(results) != len(matching) {
+		t.Fatalf("results = %+v, want one per matching transaction", results)
+	}
+	for _, r := range results {
+		if r.Status != domain.StatusFailed || r.Error != "" {
+			t.Errorf("result = %+v, want FAILED", r)
+		}
+	}
+	for _, tx := range matching {
+		if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusFailed || got.FailureReason != "corridor_outage" {
+			t.Errorf("matching %s: status %s, reason %q; want FAILED for corridor_outage", tx.ID, got.Status, got.FailureReason)
+		}
+	}
+	for _, tx := range []*domain.Transaction{earlier, otherStatus} {
+		if got := env.repo.tx(t, tx.ID); got.Status != tx.Status {
+			t.Errorf("unmatched %s: status %s, want %s", tx.ID, got.Status, tx.Status)
+		}
+	}
+}
+
+func TestApplyBulkActionConfirmCount(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	tx := env.seed("user-1", 10000, domain.StatusProcessing, now.Add(-time.Hour))
+	env.seed("user-1", 10000, domain.StatusProcessing, now.Add(-time.Hour))
+
+	_, err := env.svc.ApplyBulkAction(context.Background(), &BulkActionRequest{
+		Status:       domain.StatusProcessing,
+		From:         now.Add(-24 * time.Hour),
+		To:           now,
+		Action:       BulkActionFail,
+		Reason:       "corridor_outage",
+		ConfirmCount: 1,
+	})
+	var cerr *BulkConfirmationError
+	if !errors.As(err, &cerr) || cerr.Affected != 2 {
+		t.Fatalf("ApplyBulkAction() = %v, want a confirmation error for 2", err)
+	}
+	if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusProcessing {
+		t.Errorf("status = %s, want nothing changed", got.Status)
+	}
+}
+
+func TestApplyBulkActionRetry(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	env.wise.delay = 10 * time.Millisecond
+	var txns []*domain.Transaction
+	for range 3 * bulkActionConcurrency {
+		txns = append(txns, env.seed("user-1", 10000, domain.StatusPaymentReceived, now.Add(-time.Hour)))
+	}
+
+	results, err := env.svc.ApplyBulkAction(context.Background(), &BulkActionRequest{
+		Status:       domain.StatusPaymentReceived,
+		From:         now.Add(-24 * time.Hour),
+		To:           now,
+		Action:       BulkActionRetry,
+		ConfirmCount: len(txns),
+	})
+	if err != nil {
+		t.Fatalf("ApplyBulkAction() = %v", err)
+	}
+
+	for _, r := range results {
+		if r.Status != domain.StatusProcessing || r.Error != "" {
+			t.Errorf("result = %+v, want PROCESSING", r)
+		}
+	}
+	for _, tx := range txns {
+		if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusProcessing || got.TransferID == "" {
+			t.Errorf("%s: status %s, transfer %q; want PROCESSING with a transfer", tx.ID, got.Status, got.TransferID)
+		}
+	}
+	if env.wise.maxFlight > bulkActionConcurrency {
+		t.Errorf("Wise calls in flight = %d, want at most %d", env.wise.maxFlight, bulkActionConcurrency)
+	}
+}
+
+func TestApplyBulkActionTransferRecorded(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    BulkAction
+		recorded  bool // the transfer ID was stored before the action read the transaction
+		wantError string
+	}{
+		{"fail with a transfer", BulkActionFail, true, "transaction has Wise transfer TR-9"},
+		{"fail as the transfer is recorded", BulkActionFail, false, "status changed since the transactions were selected"},
+		{"retry as the transfer is recorded", BulkActionRetry, false, "status changed since the transactions were selected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			now := time.Now()
+			tx := env.seed("user-1", 10000, domain.StatusProcessing, now.Add(-time.Hour))
+			tx.ProcessingStartedAt = ptr(now.Add(-30 * time.Minute))
+			record := func(domain.TransactionStatus) {
+				stored := env.repo.tx(t, tx.ID)
+				stored.TransferID = "TR-9"
+				env.repo.put(stored)
+			}
+			if tt.recorded {
+				record(tx.Status)
+			} else {
+				env.repo.put(tx)
+				env.repo.afterStatusList = record
+			}
+
+			results, err := env.svc.ApplyBulkAction(context.Background(), &BulkActionRequest{
+				Status:       domain.StatusProcessing,
+				From:         now.Add(-24 * time.Hour),
+				To:           now,
+				Action:       tt.action,
+				Reason:       "corridor_outage",
+				ConfirmCount: 1,
+			})
+			if err != nil {
+				t.Fatalf("ApplyBulkAction() = %v", err)
+			}
+
+			if len(results) != 1 || results[0].Status != "" || !strings.HasPrefix(results[0].Error, tt.wantError) {
+				t.Errorf("results = %+v, want the error %q", results, tt.wantError)
+			}
+			if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusProcessing || got.TransferID != "TR-9" {
+				t.Errorf("status %s, transfer %q; want PROCESSING with TR-9", got.Status, got.TransferID)
+			}
+			if n := env.wise.calls(); n != 0 {
+				t.Errorf("transfers created = %d, want none", n)
+			}
+		})
+	}
+}
+
+func TestApplyBulkActionTooMany(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	for range maxBulkActionTransactions + 1 {
+		env.seed("user-1", 10000, domain.StatusPaymentReceived, now.Add(-time.Hour))
+	}
+
+	_, err := env.svc.ApplyBulkAction(context.Background(), &BulkActionRequest{
+		Status:       domain.StatusPaymentReceived,
+		From:         now.Add(-24 * time.Hour),
+		To:           now,
+		Action:       BulkActionRetry,
+		ConfirmCount: maxBulkActionTransactions + 1,
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrInvalidBulkAction) {
+		t.Fatalf("ApplyBulkAction() = %v, want a validation error", err)
+	}
+	if n := env.wise.calls(); n != 0 {
+		t.Errorf("transfers created = %d, want none", n)
+	}
+}
+
+func TestValidateBulkAction(t *testing.T) {
+	now := time.Now()
+	valid := func() *BulkActionRequest {
+		return &BulkActionRequest{
+			Status: domain.StatusProcessing,
+			From:   now.Add(-time.Hour),
+			To:     now,
+			Action: BulkActionFail,
+			Reason: "corridor_outage",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		change  func(req *BulkActionRequest)
+		wantErr error
+	}{
+		{"valid", func(req *BulkActionRequest) {}, nil},
+		{"retry needs no reason", func(req *BulkActionRequest) { req.Action, req.Reason = BulkActionRetry, "" }, nil},
+		{"unknown status", func(req *BulkActionRequest) { req.Status = "STALLED" }, ErrInvalidBulkAction},
+		{"finished status", func(req *BulkActionRequest) { req.Status = domain.StatusCompleted }, ErrInvalidBulkAction},
+		{"fail without a reason", func(req *BulkActionRequest) { req.Reason = "" }, ErrInvalidBulkAction},
+		{"unknown action", func(req *BulkActionRequest) { req.Action = "cancel" }, ErrInvalidBulkAction},
+		{"empty range", func(req *BulkActionRequest) { req.From = req.To }, ErrInvalidDateRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.change(req)
+			if err := validateBulkAction(req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateBulkAction() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
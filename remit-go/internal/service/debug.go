@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/remit-demo/remit-go/internal/integration"
+)
+
+// ProviderDebug holds the provider records behind a transaction as the
+// providers returned them, with sensitive fields redacted. A lookup that
+// failed or is not supported is reported in place of its record.
+type ProviderDebug struct {
+	TransactionID string                 `json:"transaction_id"`
+	Payment       map[string]interface{} `json:"payment,omitempty"`
+	PaymentError  string                 `json:"payment_error,omitempty"`
+	Transfer      map[string]interface{} `json:"transfer,omitempty"`
+	TransferError string                 `json:"transfer_error,omitempty"`
+}
+
+// GetProviderDebug fetches the raw UPI payment and Wise transfer records of
+// a transaction, for support diagnosing a failure
+func (s *RemittanceService) GetProviderDebug(ctx context.Context, txID string) (*ProviderDebug, error) {
+	tx, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	debug := &ProviderDebug{TransactionID: tx.ID}
+
+	if tx.PaymentDetails != nil && tx.PaymentDetails.PaymentID != "" {
+		if inspector, ok := s.upiClient.(integration.PaymentInspector); ok {
+			raw, err := inspector.InspectPayment(ctx, tx.PaymentDetails.PaymentID)
+			if err != nil {
+				debug.PaymentError = err.Error()
+			} else {
+				debug.Payment = integration.Redact(raw)
+			}
+		} else {
+			debug.PaymentError = "payment provider does not expose raw responses"
+		}
+	}
+
+	if tx.TransferID != "" {
+		if inspector, ok := s.wiseClient.(integration.TransferInspector); ok {
+			raw, err := inspector.InspectTransfer(ctx, tx.TransferID)
+			if err != nil {
+				debug.TransferError = err.Error()
+			} else {
+				debug.Transfer = integration.Redact(raw)
+			}
+		} else {
+			debug.TransferError = "transfer provider does not expose raw responses"
+		}
+	}
+
+	return debug, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// inspectingUPI is a fakeUPI that also returns a canned raw payment record
+type inspectingUPI struct {
+	*fakeUPI
+	raw map[string]interface{}
+	err error
+}
+
+func (u *inspectingUPI) InspectPayment(ctx context.Context, paymentID string) (map[string]interface{}, error) {
+	return u.raw, u.err
+}
+
+// inspectingWise is a fakeWise that also returns a canned raw transfer record
+type inspectingWise struct {
+	*fakeWise
+	raw map[string]interface{}
+	err error
+}
+
+func (w *inspectingWise) InspectTransfer(ctx context.Context, transferID string) (map[string]interface{}, error) {
+	return w.raw, w.err
+}
+
+func TestGetProviderDebug(t *testing.T) {
+	payment := map[string]interface{}{
+		"payment_id": "PAY-1",
+		"status":     "FAILURE",
+		"payer": map[string]interface{}{
+			"vpa":  "jane@okbank",
+			"name": "Jane Doe",
+		},
+	}
+	transfer := map[string]interface{}{
+		"id":     "TR-9",
+		"status": "bounced_back",
+		"details": map[string]interface{}{
+			"accountNumber": "12345678",
+			"reference":     "TXN-1",
+		},
+	}
+
+	tests := []struct {
+		name             string
+		paymentID        string
+		transferID       string
+		inspect          bool
+		upiErr           error
+		wantPayment      bool
+		wantPaymentError string
+		wantTransfer     bool
+		wantTransferErr  string
+	}{
+		{"payment and transfer", "PAY-1", "TR-9", true, nil, true, "", true, ""},
+		{"not yet paid", "", "", true, nil, false, "", false, ""},
+		{"paid, not yet transferred", "PAY-1", "", true, nil, true, "", false, ""},
+		{"provider call failed", "PAY-1", "", true, errors.New("gateway timeout"), false, "gateway timeout", false, ""},
+		{"providers without raw records", "PAY-1", "TR-9", false, nil, false,
+			"payment provider does not expose raw responses", false, "transfer provider does not expose raw responses"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			if tt.inspect {
+				env.svc.upiClient = &inspectingUPI{fakeUPI: env.upi, raw: payment, err: tt.upiErr}
+				env.svc.wiseClient = &inspectingWise{fakeWise: env.wise, raw: transfer}
+			}
+			tx := env.seed("user-1", 10000, domain.StatusFailed, time.Now())
+			if tt.paymentID != "" {
+				tx.PaymentDetails = &domain.PaymentDetails{PaymentID: tt.paymentID}
+			}
+			tx.TransferID = tt.transferID
+			env.repo.put(tx)
+
+			debug, err := env.svc.GetProviderDebug(context.Background(), tx.ID)
+			if err != nil {
+				t.Fatalf("GetProviderDebug() = %v", err)
+			}
+			if debug.TransactionID != tx.ID {
+				t.Errorf("transaction = %q, want %s", debug.TransactionID, tx.ID)
+			}
+			if (debug.Payment != nil) != tt.wantPayment || debug.PaymentError != tt.wantPaymentError {
+				t.Errorf("payment = %v, error %q; want record %v, error %q", debug.Payment, debug.PaymentError, tt.wantPayment, tt.wantPaymentError)
+			}
+			if (debug.Transfer != nil) != tt.wantTransfer || debug.TransferError != tt.wantTransferErr {
+				t.Errorf("transfer = %v, error %q; want record %v, error %q", debug.Transfer, debug.TransferError, tt.wantTransfer, tt.wantTransferErr)
+			}
+
+			if tt.wantPayment {
+				payer := debug.Payment["payer"].(map[string]interface{})
+				if debug.Payment["status"] != "FAILURE" || payer["vpa"] != "[REDACTED]" || payer["name"] != "[REDACTED]" {
+					t.Errorf("payment = %v, want the record with the payer redacted", debug.Payment)
+				}
+			}
+			if tt.wantTransfer {
+				details := debug.Transfer["details"].(map[string]interface{})
+				if debug.Transfer["status"] != "bounced_back" || details["accountNumber"] != "[REDACTED]" || details["reference"] != "TXN-1" {
+					t.Errorf("transfer = %v, want the record with the account redacted", debug.Transfer)
+				}
+			}
+		})
+	}
+
+	// The canned records themselves are left as they were
+	if payment["payer"].(map[string]interface{})["vpa"] != "jane@okbank" {
+		t.Error("Redact changed the provider's record")
+	}
+}
+
+func TestGetProviderDebugMissingTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.svc.GetProviderDebug(context.Background(), "TXN-missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetProviderDebug() = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// Export defaults
+const (
+	defaultExportTTL        = time.Hour
+	defaultMaxActiveExports = 2
+	defaultMaxExportRows    = 100_000
+	exportPageSize          = 100
+	exportFormatCSV         = "csv"
+)
+
+// exportHeader is the first line of an exported file
+var exportHeader = []string{
+	"transaction_id", "created_at", "status",
+	"source_amount", "source_currency", "target_amount", "target_currency",
+	"exchange_rate", "total_fee", "recipient_name", "reference",
+}
+
+// exportJobs keeps export jobs and their files in memory until they expire.
+// Jobs do not survive a restart. Each user may have maxActive unfinished
+// jobs, each of at most maxRows transactions, which bounds the memory held.
+type exportJobs struct {
+	mu        sync.Mutex
+	jobs      map[string]*exportJob
+	ttl       time.Duration
+	maxActive int
+	maxRows   int
+}
+
+type exportJob struct {
+	job    domain.ExportJob
+	data   []byte
+	cancel context.CancelFunc
+}
+
+func newExportJobs(ttl time.Duration, maxActive, maxRows int) *exportJobs {
+	if ttl <= 0 {
+		ttl = defaultExportTTL
+	}
+	if maxActive <= 0 {
+		maxActive = defaultMaxActiveExports
+	}
+	if maxRows <= 0 {
+		maxRows = defaultMaxExportRows
+	}
+	return &exportJobs{jobs: make(map[string]*exportJob), ttl: ttl, maxActive: maxActive, maxRows: maxRows}
+}
+
+// active counts the user's unfinished jobs. Callers hold mu.
+func (e *exportJobs) active(userID string) int {
+	var n int
+	for _, j := range e.jobs {
+		if j.job.UserID == userID && !j.job.IsFinished() {
+			n++
+		}
+	}
+	return n
+}
+
+// get returns the user's job, or nil if it does not exist, has expired or
+// belongs to someone else. Expired jobs are dropped on the way.
+func (e *exportJobs) get(userID, id string, now time.Time) *exportJob {
+	e.expire(now)
+	j, ok := e.jobs[id]
+	if !ok || j.job.UserID != userID {
+		return nil
+	}
+	return j
+}
+
+// expire drops finished jobs past their expiry. Callers hold mu.
+func (e *exportJobs) expire(now time.Time) {
+	for id, j := range e.jobs {
+		if j.job.IsFinished() && now.After(j.job.ExpiresAt) {
+			delete(e.jobs, id)
+		}
+	}
+}
+
+// StartExport starts exporting all of a user's transactions to CSV in the
+// background and returns the PENDING job to poll. A user with as many
+// unfinished exports as allowed gets ErrTooManyExports.
+func (s *RemittanceService) StartExport(ctx context.Context, userID string) (*domain.ExportJob, error) {
+	now := domain.Now()
+
+	s.exports.mu.Lock()
+	defer s.exports.mu.Unlock()
+	s.exports.expire(now)
+	if s.exports.active(userID) >= s.exports.maxActive {
+		return nil, ErrTooManyExports
+	}
+
+	runCtx, cancel := context.WithCancel(detachedContext(ctx))
+	j := &exportJob{
+		job: domain.ExportJob{
+			ID:        "EXP-" + newTaskID(),
+			UserID:    userID,
+			Status:    domain.ExportPending,
+			Format:    exportFormatCSV,
+			CreatedAt: now,
+			ExpiresAt: now.Add(s.exports.ttl),
+		},
+		cancel: cancel,
+	}
+
+	s.exports.jobs[j.job.ID] = j
+	job := j.job
+
+	go func() {
+		defer cancel()
+		s.runExport(runCtx, j)
+	}()
+
+	return &job, nil
+}
+
+// GetExport returns one of the user's export jobs
+func (s *RemittanceService) GetExport(ctx context.Context, userID, id string) (*domain.ExportJob, error) {
+	s.exports.mu.Lock()
+	defer s.exports.mu.Unlock()
+
+	j := s.exports.get(userID, id, domain.Now())
+	if j == nil {
+		return nil, ErrExportNotFound
+	}
+	job := j.job
+	return &job, nil
+}
+
+// ExportFile returns the file of a READY export job
+func (s *RemittanceService) ExportFile(ctx context.Context, userID, id string) ([]byte, *domain.ExportJob, error) {
+	s.exports.mu.Lock()
+	defer s.exports.mu.Unlock()
+
+	j := s.exports.get(userID, id, domain.Now())
+	if j == nil {
+		return nil, nil, ErrExportNotFound
+	}
+	job := j.job
+	if job.Status != domain.ExportReady {
+		return nil, &job, ErrExportNotReady
+	}
+	return j.data, &job, nil
+}
+
+// CancelExport stops an export job that has not finished. Cancelling a
+// job already cancelled returns it unchanged; other finished jobs return
+// ErrInvalidStatus.
+func (s *RemittanceService) CancelExport(ctx context.Context, userID, id string) (*domain.ExportJob, error) {
+	s.exports.mu.Lock()
+	defer s.exports.mu.Unlock()
+
+	now := domain.Now()
+	j := s.exports.get(userID, id, now)
+	if j == nil {
+		return nil, ErrExportNotFound
+	}
+	switch {
+	case j.job.Status == domain.ExportCancelled:
+	case j.job.IsFinished():
+		return nil, ErrInvalidStatus
+	default:
+		j.cancel()
+		j.job.Status = domain.ExportCancelled
+		j.job.CompletedAt = &now
+		j.job.ExpiresAt = now.Add(s.exports.ttl)
+		j.data = nil
+	}
+	job := j.job
+	return &job, nil
+}
+
+// runExport pages through the user's transactions into a CSV file, then
+// marks the job READY or FAILED unless it was cancelled meanwhile. An export
+// of more than maxRows transactions fails.
+func (s *RemittanceService) runExport(ctx context.Context, j *exportJob) {
+	if !s.updateExport(j, func(job *domain.ExportJob) { job.Status = domain.ExportRunning }) {
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err := w.Write(exportHeader)
+
+	var rows int
+	cursor := ""
+	for err == nil {
+		var txns []*domain.Transaction
+		txns, cursor, err = s.repo.ListTransactionsByUser(ctx, j.job.UserID, exportPageSize, cursor, repository.SortAscending)
+		if err != nil {
+			break
+		}
+		for _, tx := range txns {
+			if rows == s.exports.maxRows {
+				err = ErrExportTooLarge
+				break
+			}
+			if err = w.Write(exportRow(tx)); err != nil {
+				break
+			}
+			rows++
+		}
+		s.updateExport(j, func(job *domain.ExportJob) { job.Rows = rows })
+		if cursor == "" {
+			break
+		}
+	}
+	if err == nil {
+		w.Flush()
+		err = w.Error()
+	}
+
+	s.updateExport(j, func(job *domain.ExportJob) {
+		now := domain.Now()
+		job.CompletedAt = &now
+		job.ExpiresAt = now.Add(s.exports.ttl)
+		if err != nil {
+			job.Status = domain.ExportFailed
+			job.Error = "export failed"
+			if errors.Is(err, ErrExportTooLarge) {
+				job.Error = fmt.Sprintf("export exceeds the limit of %d transactions", s.exports.maxRows)
+				return
+			}
+			if !errors.Is(err, context.Canceled) {
+				log.Printf("export failed: export_id=%s user_id=%s request_id=%s error=%v", job.ID, job.UserID, RequestID(ctx), err)
+			}
+			return
+		}
+		job.Status = domain.ExportReady
+		j.data = buf.Bytes()
+	})
+}
+
+// updateExport applies fn to a job still in progress, reporting false if it
+// has been cancelled
+func (s *RemittanceService) updateExport(j *exportJob, fn func(job *domain.ExportJob)) bool {
+	s.exports.mu.Lock()
+	defer s.exports.mu.Unlock()
+
+	if j.job.Status == domain.ExportCancelled {
+		return false
+	}
+	fn(&j.job)
+	return true
+}
+
+func exportRow(tx *domain.Transaction) []string {
+	var fee float64
+	if tx.Fees != nil {
+		fee = tx.Fees.TotalFee
+	}
+	var name string
+	if tx.RecipientDetails != nil {
+		name = tx.RecipientDetails.Name
+	}
+
+	return []string{
+		tx.ID,
+		tx.CreatedAt.UTC().Format(time.RFC3339),
+		string(tx.Status),
+		strconv.FormatFloat(tx.SourceAmount, 'f', domain.CurrencyPrecision(tx.SourceCurrency), 64),
+		tx.SourceCurrency,
+		strconv.FormatFloat(tx.TargetAmount, 'f', domain.CurrencyPrecision(tx.TargetCurrency), 64),
+		tx.TargetCurrency,
+		strconv.FormatFloat(tx.ExchangeRate, 'f', -1, 64),
+		strconv.FormatFloat(fee, 'f', domain.CurrencyPrecision(tx.SourceCurrency), 64),
+		exportText(name),
+		exportText(tx.Reference),
+	}
+}
+
+// exportText neutralises customer text a spreadsheet would run as a formula
+func exportText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// exportStatus returns the status of one of the user's export jobs
+func (e *testEnv) exportStatus(t *testing.T, userID, id string) *domain.ExportJob {
+	t.Helper()
+	job, err := e.svc.GetExport(context.Background(), userID, id)
+	if err != nil {
+		t.Fatalf("GetExport() = %v", err)
+	}
+	return job
+}
+
+func TestExportCompletes(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRows    int
+		txns       int
+		wantStatus domain.ExportStatus
+		wantError  string
+	}{
+		{"within the row limit", 3, 3, domain.ExportReady, ""},
+		{"no transactions", 3, 0, domain.ExportReady, ""},
+		{"beyond the row limit", 3, 4, domain.ExportFailed, "export exceeds the limit of 3 transactions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t, func(cfg *Config) { cfg.MaxExportRows = tt.maxRows })
+			for i := range tt.txns {
+				env.seed("user-1", 1000, domain.StatusCompleted, time.Now().Add(-time.Duration(i)*time.Minute))
+			}
+
+			job, err := env.svc.StartExport(ctx, "user-1")
+			if err != nil {
+				t.Fatalf("StartExport() = %v", err)
+			}
+			if job.Status != domain.ExportPending {
+				t.Errorf("started job status = %s, want %s", job.Status, domain.ExportPending)
+			}
+
+			waitFor(t, "the export to finish", func() bool { return env.exportStatus(t, "user-1", job.ID).IsFinished() })
+			got := env.exportStatus(t, "user-1", job.ID)
+			if got.Status != tt.wantStatus || got.Error != tt.wantError {
+				t.Fatalf("job = %s %q, want %s %q", got.Status, got.Error, tt.wantStatus, tt.wantError)
+			}
+
+			data, _, err := env.svc.ExportFile(ctx, "user-1", job.ID)
+			if tt.wantStatus != domain.ExportReady {
+				if !errors.Is(err, ErrExportNotReady) {
+					t.Errorf("ExportFile() = %v, want ErrExportNotReady", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExportFile() = %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(lines) != tt.txns+1 || got.Rows != tt.txns {
+				t.Errorf("file has %d lines, job %d rows; want a header and %d rows", len(lines), got.Rows, tt.txns)
+			}
+		})
+	}
+}
+
+func TestCancelExport(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.seed("user-1", 1000, domain.StatusCompleted, time.Now())
+	env.repo.userListGate = make(chan struct{}) // never opened
+
+	job, err := env.svc.StartExport(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("StartExport() = %v", err)
+	}
+	waitFor(t, "the export to run", func() bool { return env.exportStatus(t, "user-1", job.ID).Status == domain.ExportRunning })
+
+	if _, err := env.svc.CancelExport(ctx, "intruder", job.ID); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("CancelExport() by another user = %v, want ErrExportNotFound", err)
+	}
+	cancelled, err := env.svc.CancelExport(ctx, "user-1", job.ID)
+	if err != nil {
+		t.Fatalf("CancelExport() = %v", err)
+	}
+	if cancelled.Status != domain.ExportCancelled {
+		t.Errorf("status = %s, want %s", cancelled.Status, domain.ExportCancelled)
+	}
+
+	// The run stops without overwriting the cancellation
+	time.Sleep(20 * time.Millisecond)
+	if got := env.exportStatus(t, "user-1", job.ID); got.Status != domain.ExportCancelled {
+		t.Errorf("status after the run stopped = %s, want %s", got.Status, domain.ExportCancelled)
+	}
+	if again, err := env.svc.CancelExport(ctx, "user-1", job.ID); err != nil || again.Status != domain.ExportCancelled {
+		t.Errorf("second CancelExport() = %v, %v; want the cancelled job", again, err)
+	}
+	if _, _, err := env.svc.ExportFile(ctx, "user-1", job.ID); !errors.Is(err, ErrExportNotReady) {
+		t.Errorf("ExportFile() = %v, want ErrExportNotReady", err)
+	}
+}
+
+func TestStartExportLimitsActiveJobs(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, func(cfg *Config) { cfg.MaxActiveExports = 2 })
+	env.repo.userListGate = make(chan struct{})
+
+	var ids []string
+	for range 2 {
+		job, err := env.svc.StartExport(ctx, "user-1")
+		if err != nil {
+			t.Fatalf("StartExport() = %v", err)
+		}
+		ids = append(ids, job.ID)
+	}
+
+	if _, err := env.svc.StartExport(ctx, "user-1"); !errors.Is(err, ErrTooManyExports) {
+		t.Fatalf("third StartExport() = %v, want ErrTooManyExports", err)
+	}
+	if _, err := env.svc.StartExport(ctx, "user-2"); err != nil {
+		t.Fatalf("StartExport() for another user = %v", err)
+	}
+
+	// A finished job frees its slot
+	if _, err := env.svc.CancelExport(ctx, "user-1", ids[0]); err != nil {
+		t.Fatalf("CancelExport() = %v", err)
+	}
+	if _, err := env.svc.StartExport(ctx, "user-1"); err != nil {
+		t.Fatalf("StartExport() after a cancellation = %v", err)
+	}
+
+	close(env.repo.userListGate)
+}
@@ -0,0 +1,752 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/remit-demo/remit-go/internal/config"
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/integration"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// fakeRepo is an in-memory repository.Repository with the conditional
+// semantics of the DynamoDB implementation. Transactions are kept as
+// DynamoDB items, so reads return copies and UpdateTransactionStatus sets
+// fields by their attribute names, as the real update expression does.
+type fakeRepo struct {
+	mu          sync.Mutex
+	txns        map[string]map[string]types.AttributeValue
+	payments    map[string]*domain.PaymentDetails
+	rates       map[string]*domain.ExchangeRate
+	idempotency map[string]*domain.IdempotencyRecord
+	deadLetters map[string]*domain.DeadLetter
+
+	// fail makes the named method return the error
+	fail map[string]error
+
+	// fullWrites counts UpdateTransaction calls, which overwrite the item
+	fullWrites int
+
+	// collisions is how many CreateTransaction calls find their ID taken
+	// before one is stored; createdIDs records the IDs tried
+	collisions int
+	createdIDs []string
+
+	// userListGate, when set, holds ListTransactionsByUser until it is
+	// closed or the caller's context ends
+	userListGate chan struct{}
+
+	// afterStatusList, when set, runs after ListTransactionsByStatus has
+	// read a page of status, for writes the index has not caught up with
+	afterStatusList func(status domain.TransactionStatus)
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		txns:        make(map[string]map[string]types.AttributeValue),
+		payments:    make(map[string]*domain.PaymentDetails),
+		rates:       make(map[string]*domain.ExchangeRate),
+		idempotency: make(map[string]*domain.IdempotencyRecord),
+		deadLetters: make(map[string]*domain.DeadLetter),
+		fail:        make(map[string]error),
+	}
+}
+
+// clone copies v through its DynamoDB form
+func clone[T any](v *T) *T {
+	item, err := attributevalue.MarshalMap(v)
+	if err != nil {
+		panic(err)
+	}
+	var out T
+	if err := attributevalue.UnmarshalMap(item, &out); err != nil {
+		panic(err)
+	}
+	return &out
+}
+
+func (r *fakeRepo) failure(method string) error {
+	return r.fail[method]
+}
+
+// put stores tx as is, replacing any transaction with its ID
+func (r *fakeRepo) put(tx *domain.Transaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, err := attributevalue.MarshalMap(tx)
+	if err != nil {
+		panic(err)
+	}
+	r.txns[tx.ID] = item
+}
+
+// tx returns the stored transaction, failing the test when it is missing
+func (r *fakeRepo) tx(t *testing.T, id string) *domain.Transaction {
+	t.Helper()
+	tx, err := r.GetTransaction(context.Background(), id)
+	if err != nil {
+		t.Fatalf("transaction %s: %v", id, err)
+	}
+	return tx
+}
+
+func (r *fakeRepo) decode(item map[string]types.AttributeValue) *domain.Transaction {
+	var tx domain.Transaction
+	if err := attributevalue.UnmarshalMap(item, &tx); err != nil {
+		panic(err)
+	}
+	return &tx
+}
+
+func (r *fakeRepo) CreateTransaction(ctx context.Context, tx *domain.Transaction) error {
+	if err := r.failure("CreateTransaction"); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.createdIDs = append(r.createdIDs, tx.ID)
+	if r.collisions > 0 {
+		r.collisions--
+		return repository.ErrAlreadyExists
+	}
+	if _, ok := r.txns[tx.ID]; ok {
+		return repository.ErrAlreadyExists
+	}
+	item, err := attributevalue.MarshalMap(tx)
+	if err != nil {
+		return err
+	}
+	r.txns[tx.ID] = item
+	return nil
+}
+
+func (r *fakeRepo) GetTransaction(ctx context.Context, id string) (*domain.Transaction, error) {
+	if err := r.failure("GetTransaction"); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.txns[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return r.decode(item), nil
+}
+
+func (r *fakeRepo) ListTransactionsByReference(ctx context.Context, reference string, limit int, lastKey string) ([]*domain.Transaction, string, error) {
+	return page(r.list(func(tx *domain.Transaction) bool { return tx.Reference == reference }), limit, lastKey)
+}
+
+func (r *fakeRepo) PutDeadLetter(ctx context.Context, dl *domain.DeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deadLetters[dl.ID] = clone(dl)
+	return nil
+}
+
+func (r *fakeRepo) GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dl, ok := r.deadLetters[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return clone(dl), nil
+}
+
+func (r *fakeRepo) ListDeadLetters(ctx context.Context, limit int, cursor string) ([]*domain.DeadLetter, string, error) {
+	r.mu.Lock()
+	dls := make([]*domain.DeadLetter, 0, len(r.deadLetters))
+	for _, dl := range r.deadLetters {
+		dls = append(dls, clone(dl))
+	}
+	r.mu.Unlock()
+	sort.Slice(dls, func(i, j int) bool { return dls[i].ID < dls[j].ID })
+	return page(dls, limit, cursor)
+}
+
+func (r *fakeRepo) DeleteDeadLetter(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.deadLetters, id)
+	return nil
+}
+
+func (r *fakeRepo) Ping(ctx context.Context) error {
+	return r.failure("Ping")
+}
+
+func (r *fakeRepo) BatchGetTransactions(ctx context.Context, ids []string) ([]*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var txns []*domain.Transaction
+	for _, id := range ids {
+		if item, ok := r.txns[id]; ok {
+			txns = append(txns, r.decode(item))
+		}
+	}
+	return txns, nil
+}
+
+func (r *fakeRepo) UpdateTransaction(ctx context.Context, tx *domain.Transaction) error {
+	if err := r.failure("UpdateTransaction"); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.txns[tx.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	item, err := attributevalue.MarshalMap(tx)
+	if err != nil {
+		return err
+	}
+	r.txns[tx.ID] = item
+	r.fullWrites++
+	return nil
+}
+
+func (r *fakeRepo) UpdateTransactionStatus(ctx context.Context, id string, from, to domain.TransactionStatus, fields ...repository.Field) error {
+	if err := r.failure("UpdateTransactionStatus"); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.txns[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	tx := r.decode(item)
+	if tx.Status != from {
+		return repository.ErrStatusMismatch
+	}
+	for _, f := range fields {
+		stored, set := item[f.Name]
+		if f.IfAbsent && set {
+			return repository.ErrStatusMismatch
+		}
+		if !f.Require {
+			continue
+		}
+		if f.Value == nil {
+			if set {
+				return repository.ErrStatusMismatch
+			}
+			continue
+		}
+		want, err := attributevalue.Marshal(f.Value)
+		if err != nil {
+			return err
+		}
+		if !set || !reflect.DeepEqual(stored, want) {
+			return repository.ErrStatusMismatch
+		}
+	}
+
+	now := domain.Now()
+	if from != to {
+		tx.Status = to
+		tx.StatusHistory = append(tx.StatusHistory, domain.StatusChange{Status: to, At: now})
+		if to == domain.StatusCompleted {
+			tx.CompletedAt = &now
+		}
+	}
+	tx.UpdatedAt = now
+
+	updated, err := attributevalue.MarshalMap(tx)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.Require {
+			continue
+		}
+		if f.Remove {
+			delete(updated, f.Name)
+			continue
+		}
+		value, err := attributevalue.Marshal(f.Value)
+		if err != nil {
+			return err
+		}
+		updated[f.Name] = value
+	}
+	r.txns[id] = updated
+	return nil
+}
+
+// list returns the transactions matching keep, oldest first
+func (r *fakeRepo) list(keep func(tx *domain.Transaction) bool) []*domain.Transaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var txns []*domain.Transaction
+	for _, item := range r.txns {
+		if tx := r.decode(item); keep(tx) {
+			txns = append(txns, tx)
+		}
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		if txns[i].CreatedAt.Equal(txns[j].CreatedAt) {
+			return txns[i].ID < txns[j].ID
+		}
+		return txns[i].CreatedAt.Before(txns[j].CreatedAt)
+	})
+	return txns
+}
+
+// page returns up to limit items from the offset encoded in cursor, and the
+// cursor of the next page
+func page[T any](items []T, limit int, cursor string) ([]T, string, error) {
+	start := 0
+	if cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 || n > len(items) {
+			return nil, "", repository.ErrInvalidInput
+		}
+		start = n
+	}
+	end := len(items)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	next := ""
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	return items[start:end], next, nil
+}
+
+func (r *fakeRepo) ListTransactionsByUser(ctx context.Context, userID string, limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+	if err := r.failure("ListTransactionsByUser"); err != nil {
+		return nil, "", err
+	}
+	if r.userListGate != nil {
+		select {
+		case <-r.userListGate:
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		}
+	}
+	txns := r.list(func(tx *domain.Transaction) bool { return tx.UserID == userID })
+	return page(ordered(txns, order), limit, lastKey)
+}
+
+func ordered(txns []*domain.Transaction, order repository.SortOrder) []*domain.Transaction {
+	if order == repository.SortDescending {
+		for i, j := 0, len(txns)-1; i < j; i, j = i+1, j-1 {
+			txns[i], txns[j] = txns[j], txns[i]
+		}
+	}
+	return txns
+}
+
+func (r *fakeRepo) ListTransactionsByRecipient(ctx context.Context, recipientHash string, limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error) {
+	txns := r.list(func(tx *domain.Transaction) bool { return tx.RecipientHash == recipientHash })
+	return page(ordered(txns, order), limit, lastKey)
+}
+
+func (r *fakeRepo) ListTransactionsByStatus(ctx context.Context, status domain.TransactionStatus, from, to time.Time, limit int, cursor string) ([]*domain.Transaction, string, error) {
+	if err := r.failure("ListTransactionsByStatus"); err != nil {
+		return nil, "", err
+	}
+	txns := r.list(func(tx *domain.Transaction) bool {
+		return tx.Status == status && !tx.CreatedAt.Before(from) && !tx.CreatedAt.After(to)
+	})
+	if r.afterStatusList != nil {
+		r.afterStatusList(status)
+	}
+	return page(txns, limit, cursor)
+}
+
+func (r *fakeRepo) CreatePayment(ctx context.Context, txID string, payment *domain.PaymentDetails) error {
+	if err := r.failure("CreatePayment"); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.payments[payment.PaymentID]; ok {
+		return repository.ErrAlreadyExists
+	}
+	r.payments[payment.PaymentID] = clone(payment)
+	return nil
+}
+
+func (r *fakeRepo) UpdatePayment(ctx context.Context, txID string, payment *domain.PaymentDetails) error {
+	if err := r.failure("UpdatePayment"); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.payments[payment.PaymentID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.payments[payment.PaymentID] = clone(payment)
+	return nil
+}
+
+func (r *fakeRepo) GetPayment(ctx context.Context, paymentID string) (*domain.PaymentDetails, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	payment, ok := r.payments[paymentID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return clone(payment), nil
+}
+
+func (r *fakeRepo) SaveRate(ctx context.Context, rate *domain.ExchangeRate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates[rate.Pair] = clone(rate)
+	return nil
+}
+
+func (r *fakeRepo) GetLastRate(ctx context.Context, sourceCurrency, targetCurrency string) (*domain.ExchangeRate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rate, ok := r.rates[domain.RatePair(sourceCurrency, targetCurrency)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return clone(rate), nil
+}
+
+func (r *fakeRepo) PutIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.idempotency[record.Key]; ok && !existing.IsExpired(domain.Now()) {
+		return repository.ErrAlreadyExists
+	}
+	r.idempotency[record.Key] = clone(record)
+	return nil
+}
+
+func (r *fakeRepo) GetIdempotencyRecord(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, ok := r.idempotency[key]
+	if !ok || record.IsExpired(domain.Now()) {
+		return nil, repository.ErrNotFound
+	}
+	return clone(record), nil
+}
+
+func (r *fakeRepo) CompleteIdempotencyRecord(ctx context.Context, key, txID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, ok := r.idempotency[key]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	record.TransactionID = txID
+	return nil
+}
+
+func (r *fakeRepo) DeleteIdempotencyRecord(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.idempotency, key)
+	return nil
+}
+
+// fakeUPI is a UPI client that records the amounts links are requested for
+type fakeUPI struct {
+	mu      sync.Mutex
+	amounts []float64
+	err     error
+	pingErr error
+}
+
+func (u *fakeUPI) GeneratePaymentLink(ctx context.Context, txID string, amount float64) (string, error) {
+	if u.err != nil {
+		return "", u.err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.amounts = append(u.amounts, amount)
+	return fmt.Sprintf("upi://pay?tr=%s&n=%d", txID, len(u.amounts)), nil
+}
+
+func (u *fakeUPI) VerifyPayment(ctx context.Context, paymentID string) (string, error) {
+	return "SUCCESS", nil
+}
+
+func (u *fakeUPI) Ping(ctx context.Context) error {
+	return u.pingErr
+}
+
+// fakeADBank quotes a fixed rate and accepts every account unless told not to
+type fakeADBank struct {
+	mu       sync.Mutex
+	rate     float64
+	rateErr  error
+	invalid  bool
+	currency string // account currency reported by ValidateAccount
+	bankName string
+	bankErr  error
+	pingErr  error
+
+	// pingFailures is how many pings fail before they answer pingErr
+	pingFailures int
+}
+
+func (b *fakeADBank) GetExchangeRate(ctx context.Context, sourceCurrency, targetCurrency string) (float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate, b.rateErr
+}
+
+// setRate changes the quoted rate and failure
+func (b *fakeADBank) setRate(rate float64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate, b.rateErr = rate, err
+}
+
+func (b *fakeADBank) LookupBank(ctx context.Context, bankCode string) (string, error) {
+	return b.bankName, b.bankErr
+}
+
+func (b *fakeADBank) Ping(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pingFailures > 0 {
+		b.pingFailures--
+		return errors.New("connection refused")
+	}
+	return b.pingErr
+}
+
+func (b *fakeADBank) ValidateAccount(ctx context.Context, bankCode, accountNumber string) (*integration.AccountValidation, error) {
+	return &integration.AccountValidation{Valid: !b.invalid, Currency: b.currency}, nil
+}
+
+// fakeWise records transfer requests. Errors in errs are returned by the
+// first calls, one each; later calls succeed. Every call takes delay, and
+// maxFlight records the most calls in flight at once.
+type fakeWise struct {
+	mu        sync.Mutex
+	requests  []*integration.WiseTransferRequest
+	errs      []error
+	statuses  map[string]string
+	inFlight  int
+	maxFlight int
+	delay     time.Duration
+	pingErr   error
+}
+
+func (w *fakeWise) CreateTransfer(ctx context.Context, req *integration.WiseTransferRequest) (string, error) {
+	w.mu.Lock()
+	w.requests = append(w.requests, req)
+	n := len(w.requests)
+	var err error
+	if len(w.errs) > 0 {
+		err, w.errs = w.errs[0], w.errs[1:]
+	}
+	w.mu.Unlock()
+
+	w.busy()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("TR-%d", n), nil
+}
+
+// calls returns how many transfers were requested
+func (w *fakeWise) calls() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.requests)
+}
+
+// busy keeps a call in flight for delay
+func (w *fakeWise) busy() {
+	w.mu.Lock()
+	w.inFlight++
+	w.maxFlight = max(w.maxFlight, w.inFlight)
+	delay := w.delay
+	w.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	w.mu.Lock()
+	w.inFlight--
+	w.mu.Unlock()
+}
+
+func (w *fakeWise) Ping(ctx context.Context) error {
+	return w.pingErr
+}
+
+func (w *fakeWise) GetTransferStatus(ctx context.Context, transferID string) (string, error) {
+	w.busy()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status, ok := w.statuses[transferID]
+	if !ok {
+		return "PROCESSING", nil
+	}
+	return status, nil
+}
+
+// fakeCompliance blocks the listed accounts
+type fakeCompliance struct {
+	blocked map[string]bool
+}
+
+func (c *fakeCompliance) IsBlocked(ctx context.Context, recipient *domain.RecipientDetails) (bool, error) {
+	return c.blocked[recipient.BankAccount], nil
+}
+
+// fakeNotifier records the notifications sent
+type fakeNotifier struct {
+	mu   sync.Mutex
+	sent []*integration.Notification
+	err  error // returned instead of sending, when set
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, notification *integration.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// testEnv is a RemittanceService wired to fakes, taking card payments
+// through card as well as UPI
+type testEnv struct {
+	svc        *RemittanceService
+	repo       *fakeRepo
+	upi        *fakeUPI
+	card       *fakeUPI
+	adBank     *fakeADBank
+	wise       *fakeWise
+	compliance *fakeCompliance
+	notifier   *fakeNotifier
+}
+
+// testRate is the mid-market INR/CAD rate the fake AD Bank quotes
+const testRate = 0.016
+
+// testConfig is the INR/CAD service configuration the tests start from
+func testConfig() *Config {
+	return &Config{
+		MinAmount:   100,
+		MaxAmount:   1_000_000,
+		DailyLimit:  2_000_000,
+		BaseFee:     50,
+		VariableFee: 0.01,
+		CurrencyPairs: []config.CurrencyPairConfig{{
+			Source:          "INR",
+			Target:          "CAD",
+			Enabled:         true,
+			MinRateValidity: 15 * time.Minute,
+			DeliveryWindow:  config.DeliveryWindowConfig{Min: time.Hour, Max: 24 * time.Hour},
+		}},
+	}
+}
+
+// newTestEnv returns a service on testConfig, changed by configure
+func newTestEnv(t *testing.T, configure ...func(cfg *Config)) *testEnv {
+	t.Helper()
+	cfg := testConfig()
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	env := &testEnv{
+		repo:       newFakeRepo(),
+		upi:        &fakeUPI{},
+		card:       &fakeUPI{},
+		adBank:     &fakeADBank{rate: testRate, bankName: "Test Bank"},
+		wise:       &fakeWise{statuses: make(map[string]string)},
+		compliance: &fakeCompliance{blocked: make(map[string]bool)},
+		notifier:   &fakeNotifier{},
+	}
+	cards := map[domain.PaymentMethod]integration.PaymentProvider{domain.PaymentMethodCard: env.card}
+	env.svc = NewRemittanceService(env.repo, env.upi, cards, env.adBank, env.wise,
+		env.compliance, env.notifier, cfg)
+	return env
+}
+
+// testRecipient returns a valid recipient
+func testRecipient() *domain.RecipientDetails {
+	return &domain.RecipientDetails{BankAccount: "12345678", BankCode: "TD001", Name: "Jane Doe"}
+}
+
+// initiate creates a transaction for userID through the service
+func (e *testEnv) initiate(t *testing.T, userID string, amount float64) *domain.Transaction {
+	t.Helper()
+	tx, err := e.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+		UserID:    userID,
+		Amount:    amount,
+		Recipient: testRecipient(),
+	})
+	if err != nil {
+		t.Fatalf("InitiateTransaction() = %v", err)
+	}
+	return tx
+}
+
+// seed stores a transaction for userID in status, created at createdAt
+func (e *testEnv) seed(userID string, amount float64, status domain.TransactionStatus, createdAt time.Time) *domain.Transaction {
+	tx := domain.NewTransaction(userID, amount, "INR", "CAD", testRecipient())
+	tx.SetFees(&domain.Fees{BaseFee: 50, TotalFee: 50})
+	tx.SetExchangeRate(testRate, e.svc.config.FeeModel)
+	tx.CreatedAt = createdAt
+	tx.Status = status
+	e.repo.put(tx)
+	return tx
+}
+
+// awaitingPayment creates a transaction for userID with a payment link
+func (e *testEnv) awaitingPayment(t *testing.T, userID string, amount float64) *domain.Transaction {
+	t.Helper()
+	tx := e.initiate(t, userID, amount)
+	if _, err := e.svc.GeneratePaymentLink(context.Background(), userID, tx.ID); err != nil {
+		t.Fatalf("GeneratePaymentLink() = %v", err)
+	}
+	return e.repo.tx(t, tx.ID)
+}
+
+// move forces a stored transaction into status
+func (e *testEnv) move(t *testing.T, txID string, status domain.TransactionStatus, fields ...repository.Field) {
+	t.Helper()
+	tx := e.repo.tx(t, txID)
+	if err := e.repo.UpdateTransactionStatus(context.Background(), txID, tx.Status, status, fields...); err != nil {
+		t.Fatalf("move %s to %s: %v", txID, status, err)
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after two seconds.
+// For work the service runs in the background.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// ptr returns a pointer to v, for optional request fields
+func ptr[T any](v T) *T {
+	return &v
+}
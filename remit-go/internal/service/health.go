@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/integration"
+)
+
+// CheckDependencies pings every integration concurrently and returns the
+// outcome per dependency; a nil error means healthy
+func (s *RemittanceService) CheckDependencies(ctx context.Context) map[string]error {
+	deps := map[string]integration.Pinger{
+		"upi":     s.upiClient,
+		"ad_bank": s.adBankClient,
+		"wise":    s.wiseClient,
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]error, len(deps))
+	for name, dep := range deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := dep.Ping(ctx)
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// startupProbeInterval is how often WaitForDependencies retries
+const startupProbeInterval = 2 * time.Second
+
+// WaitForDependencies blocks until DynamoDB and the rate provider respond,
+// the minimum needed to serve, probing every interval. It gives up with the
+// last failure when ctx is done.
+func (s *RemittanceService) WaitForDependencies(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = startupProbeInterval
+	}
+	deps := map[string]integration.Pinger{
+		"dynamodb": s.repo,
+		"ad_bank":  s.adBankClient,
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for attempt := 1; ; attempt++ {
+		var lastErr error
+		for name, dep := range deps {
+			if err := dep.Ping(ctx); err != nil {
+				lastErr = fmt.Errorf("%s: %w", name, err)
+				log.Printf("waiting for dependencies: attempt=%d dependency=%s error=%v", attempt, name, err)
+				continue
+			}
+			delete(deps, name)
+		}
+		if len(deps) == 0 {
+			log.Printf("dependencies ready after %d attempt(s)", attempt)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("dependencies not ready: %w", lastErr)
+		case <-ticker.C:
+		}
+	}
+}
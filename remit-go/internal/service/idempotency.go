@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// idempotencyTTL is how long an idempotency key keeps returning the
+// transaction it created
+const idempotencyTTL = 24 * time.Hour
+
+// initiateIdempotent runs create at most once per user and idempotency key,
+// across restarts and instances. The key is claimed with a conditional write
+// before create runs; a repeated key returns the transaction the first
+// request created, marked as replayed, or ErrIdempotencyInProgress while
+// that request is still running.
+func (s *RemittanceService) initiateIdempotent(
+	ctx context.Context,
+	req *InitiateRequest,
+	create func() (*domain.Transaction, error),
+) (*domain.Transaction, error) {
+	key := req.UserID + ":" + req.IdempotencyKey
+
+	err := s.repo.PutIdempotencyRecord(ctx, domain.NewIdempotencyRecord(key, idempotencyTTL))
+	switch {
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return s.replay(ctx, key)
+	case err != nil:
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	tx, err := create()
+	if err != nil {
+		if derr := s.repo.DeleteIdempotencyRecord(ctx, key); derr != nil {
+			log.Printf("failed to release idempotency key %s: %v", key, derr)
+		}
+		return nil, err
+	}
+
+	if err := s.repo.CompleteIdempotencyRecord(ctx, key, tx.ID); err != nil {
+		log.Printf("failed to complete idempotency key %s for transaction %s: %v", key, tx.ID, err)
+	}
+	return tx, nil
+}
+
+// replay returns the transaction created under a claimed key
+func (s *RemittanceService) replay(ctx context.Context, key string) (*domain.Transaction, error) {
+	record, err := s.repo.GetIdempotencyRecord(ctx, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Released or expired since the claim failed; let the client retry
+			return nil, ErrIdempotencyInProgress
+		}
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	if record.TransactionID == "" {
+		return nil, ErrIdempotencyInProgress
+	}
+
+	tx, err := s.repo.GetTransaction(ctx, record.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replayed transaction: %w", err)
+	}
+	tx.Replayed = true
+	return tx, nil
+}
+
+// duplicateScanLimit bounds how many of the user's latest transactions are
+// compared against a new request
+const duplicateScanLimit = 10
+
+// recentDuplicate returns a transaction the user created within the
+// duplicate window for the same amount and recipient, or nil. It catches
+// double submits from clients that send no idempotency key; two requests
+// racing each other can still both create a transaction.
+func (s *RemittanceService) recentDuplicate(ctx context.Context, req *InitiateRequest) (*domain.Transaction, error) {
+	window := s.config.DuplicateWindow
+	if window <= 0 || req.Recipient == nil {
+		return nil, nil
+	}
+
+	txns, _, err := s.repo.ListTransactionsByUser(ctx, req.UserID, duplicateScanLimit, "", repository.SortDescending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	since := domain.Now().Add(-window)
+	for _, tx := range txns {
+		if tx.CreatedAt.Before(since) {
+			break // latest first; the rest are older
+		}
+		if tx.IsFailed() || tx.SourceAmount != req.Amount || tx.RecipientDetails == nil {
+			continue
+		}
+		if tx.RecipientDetails.BankAccount == req.Recipient.BankAccount &&
+			tx.RecipientDetails.BankCode == req.Recipient.BankCode {
+			return tx, nil
+		}
+	}
+	return nil, nil
+}
+
+// recentRecipientWarning returns a warning when the user sent money to the
+// recipient within the recent recipient window. It never blocks the
+// transaction: a failed lookup is logged and yields no warning.
+func (s *RemittanceService) recentRecipientWarning(ctx context.Context, userID string, recipient *domain.RecipientDetails) *domain.Warning {
+	window := s.config.RecentRecipientWindow
+	if window <= 0 || recipient == nil {
+		return nil
+	}
+
+	txns, _, err := s.repo.ListTransactionsByUser(ctx, userID, duplicateScanLimit, "", repository.SortDescending)
+	if err != nil {
+		log.Printf("recent recipient lookup failed: user_id=%s error=%v", userID, err)
+		return nil
+	}
+
+	since := domain.Now().Add(-window)
+	for _, tx := range txns {
+		if tx.CreatedAt.Before(since) {
+			break // latest first; the rest are older
+		}
+		if tx.IsFailed() || tx.RecipientDetails == nil {
+			continue
+		}
+		if tx.RecipientDetails.BankAccount == recipient.BankAccount &&
+			tx.RecipientDetails.BankCode == recipient.BankCode {
+			return &domain.Warning{
+				Code:    domain.WarningRecentRecipient,
+				Message: fmt.Sprintf("you already sent money to this recipient at %s (transaction %s)", tx.CreatedAt.UTC().Format(time.RFC3339), tx.ID),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+func TestInitiateIdempotencyKey(t *testing.T) {
+	type call struct {
+		userID string
+		key    string
+		amount float64
+	}
+	tests := []struct {
+		name      string
+		calls     []call
+		wantErrs  []bool // whether each call fails
+		wantSame  []bool // whether each call returns the first call's transaction
+		wantTotal int
+	}{
+		{"repeated key replays", []call{{"user-1", "k1", 10000}, {"user-1", "k1", 10000}}, []bool{false, false}, []bool{true, true}, 1},
+		{"other key creates", []call{{"user-1", "k1", 10000}, {"user-1", "k2", 10000}}, []bool{false, false}, []bool{true, false}, 2},
+		{"key is per user", []call{{"user-1", "k1", 10000}, {"user-2", "k1", 10000}}, []bool{false, false}, []bool{true, false}, 2},
+		{"failed request releases the key", []call{{"user-1", "k1", 1}, {"user-1", "k1", 10000}}, []bool{true, false}, []bool{false, false}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			var first string
+			for i, c := range tt.calls {
+				tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+					UserID:         c.userID,
+					Amount:         c.amount,
+					Recipient:      testRecipient(),
+					IdempotencyKey: c.key,
+				})
+				if (err != nil) != tt.wantErrs[i] {
+					t.Fatalf("call %d: InitiateTransaction() = %v, want error %v", i, err, tt.wantErrs[i])
+				}
+				if err != nil {
+					continue
+				}
+				if i == 0 {
+					first = tx.ID
+				}
+				if same := tx.ID == first; same != tt.wantSame[i] {
+					t.Errorf("call %d: transaction %s, first %s; want same %v", i, tx.ID, first, tt.wantSame[i])
+				}
+				if tx.Replayed != (i > 0 && tt.wantSame[i]) {
+					t.Errorf("call %d: replayed = %v", i, tx.Replayed)
+				}
+			}
+			if n := len(env.repo.txns); n != tt.wantTotal {
+				t.Errorf("transactions created = %d, want %d", n, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestInitiateIdempotencyKeyConcurrentWriters(t *testing.T) {
+	const writers = 8
+	env := newTestEnv(t)
+	// Hold the request that claims the key inside its daily limit check,
+	// so the others all arrive while it is in flight
+	env.repo.userListGate = make(chan struct{})
+
+	req := func() *InitiateRequest {
+		return &InitiateRequest{UserID: "user-1", Amount: 10000, Recipient: testRecipient(), IdempotencyKey: "k1"}
+	}
+	type result struct {
+		id  string
+		err error
+	}
+	results := make(chan result, writers)
+	for range writers {
+		go func() {
+			tx, err := env.svc.InitiateTransaction(context.Background(), req())
+			if err != nil {
+				results <- result{err: err}
+				return
+			}
+			results <- result{id: tx.ID}
+		}()
+	}
+
+	for range writers - 1 {
+		if r := <-results; !errors.Is(r.err, ErrIdempotencyInProgress) {
+			t.Fatalf("concurrent writer: transaction %q, error %v; want ErrIdempotencyInProgress", r.id, r.err)
+		}
+	}
+	close(env.repo.userListGate)
+	created := <-results
+	if created.err != nil {
+		t.Fatalf("claiming writer: %v", created.err)
+	}
+
+	tx, err := env.svc.InitiateTransaction(context.Background(), req())
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if tx.ID != created.id || !tx.Replayed {
+		t.Errorf("retry: transaction %s, replayed %v; want %s replayed", tx.ID, tx.Replayed, created.id)
+	}
+	if n := len(env.repo.txns); n != 1 {
+		t.Errorf("transactions created = %d, want 1", n)
+	}
+}
+
+func TestInitiateNearDuplicate(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  time.Duration
+		age     time.Duration // of the earlier transaction
+		status  domain.TransactionStatus
+		userID  string
+		amount  float64
+		account string
+		key     string
+		wantDup bool
+	}{
+		{"within the window", time.Minute, 10 * time.Second, domain.StatusPaymentPending, "user-1", 10000, "12345678", "", true},
+		{"outside the window", time.Minute, 2 * time.Minute, domain.StatusPaymentPending, "user-1", 10000, "12345678", "", false},
+		{"detection off", 0, 10 * time.Second, domain.StatusPaymentPending, "user-1", 10000, "12345678", "", false},
+		{"other amount", time.Minute, 10 * time.Second, domain.StatusPaymentPending, "user-1", 20000, "12345678", "", false},
+		{"other recipient", time.Minute, 10 * time.Second, domain.StatusPaymentPending, "user-1", 10000, "87654321", "", false},
+		{"other user", time.Minute, 10 * time.Second, domain.StatusPaymentPending, "user-2", 10000, "12345678", "", false},
+		{"earlier one failed", time.Minute, 10 * time.Second, domain.StatusFailed, "user-1", 10000, "12345678", "", false},
+		{"idempotency key given", time.Minute, 10 * time.Second, domain.StatusPaymentPending, "user-1", 10000, "12345678", "k1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.DuplicateWindow = tt.window })
+			earlier := env.seed("user-1", 10000, tt.status, time.Now().Add(-tt.age))
+
+			recipient := testRecipient()
+			recipient.BankAccount = tt.account
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:         tt.userID,
+				Amount:         tt.amount,
+				Recipient:      recipient,
+				IdempotencyKey: tt.key,
+			})
+			if err != nil {
+				t.Fatalf("InitiateTransaction() = %v", err)
+			}
+
+			if dup := tx.ID == earlier.ID; dup != tt.wantDup || tx.Replayed != tt.wantDup {
+				t.Errorf("transaction %s replayed %v, earlier %s; want duplicate %v", tx.ID, tx.Replayed, earlier.ID, tt.wantDup)
+			}
+			wantTotal := 2
+			if tt.wantDup {
+				wantTotal = 1
+			}
+			if n := len(env.repo.txns); n != wantTotal {
+				t.Errorf("transactions stored = %d, want %d", n, wantTotal)
+			}
+		})
+	}
+}
+
+func TestRecentRecipientWarning(t *testing.T) {
+	tests := []struct {
+		name        string
+		window      time.Duration
+		age         time.Duration // of the earlier transaction
+		status      domain.TransactionStatus
+		userID      string
+		account     string
+		wantWarning bool
+	}{
+		{"within the window", time.Hour, 10 * time.Minute, domain.StatusCompleted, "user-1", "12345678", true},
+		{"outside the window", time.Hour, 2 * time.Hour, domain.StatusCompleted, "user-1", "12345678", false},
+		{"warning off", 0, 10 * time.Minute, domain.StatusCompleted, "user-1", "12345678", false},
+		{"other recipient", time.Hour, 10 * time.Minute, domain.StatusCompleted, "user-1", "87654321", false},
+		{"other user", time.Hour, 10 * time.Minute, domain.StatusCompleted, "user-2", "12345678", false},
+		{"earlier one failed", time.Hour, 10 * time.Minute, domain.StatusFailed, "user-1", "12345678", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.RecentRecipientWindow = tt.window })
+			earlier := env.seed("user-1", 20000, tt.status, time.Now().Add(-tt.age))
+
+			recipient := testRecipient()
+			recipient.BankAccount = tt.account
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    tt.userID,
+				Amount:    10000,
+				Recipient: recipient,
+			})
+			if err != nil {
+				t.Fatalf("InitiateTransaction() = %v", err)
+			}
+			if tx.ID == earlier.ID {
+				t.Fatal("InitiateTransaction() returned the earlier transaction")
+			}
+
+			if !tt.wantWarning {
+				if len(tx.Warnings) != 0 {
+					t.Errorf("warnings = %+v, want none", tx.Warnings)
+				}
+				return
+			}
+			if len(tx.Warnings) != 1 || tx.Warnings[0].Code != domain.WarningRecentRecipient ||
+				!strings.Contains(tx.Warnings[0].Message, earlier.ID) {
+				t.Errorf("warnings = %+v, want one recent_recipient warning naming %s", tx.Warnings, earlier.ID)
+			}
+		})
+	}
+}
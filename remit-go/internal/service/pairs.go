@@ -0,0 +1,59 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/remit-demo/remit-go/internal/config"
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// pairSwitches holds whether each corridor is enabled. It starts from the
+// configuration and lets operators pause a corridor without a redeploy.
+type pairSwitches struct {
+	mu      sync.RWMutex
+	enabled map[string]bool
+}
+
+func newPairSwitches(pairs []config.CurrencyPairConfig) *pairSwitches {
+	enabled := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		enabled[pair.Key()] = pair.Enabled
+	}
+	return &pairSwitches{enabled: enabled}
+}
+
+func (p *pairSwitches) isEnabled(key string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.enabled[key]
+}
+
+func (p *pairSwitches) set(key string, enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled[key] = enabled
+}
+
+// ListCurrencyPairs returns the configured corridors with their current state
+func (s *RemittanceService) ListCurrencyPairs() []domain.CurrencyPair {
+	pairs := make([]domain.CurrencyPair, 0, len(s.config.CurrencyPairs))
+	for _, pair := range s.config.CurrencyPairs {
+		pairs = append(pairs, domain.CurrencyPair{
+			Source:  pair.Source,
+			Target:  pair.Target,
+			Enabled: s.pairs.isEnabled(pair.Key()),
+		})
+	}
+	return pairs
+}
+
+// SetCurrencyPairEnabled pauses or resumes a corridor. New transactions on a
+// paused corridor are refused; transactions already under way continue.
+func (s *RemittanceService) SetCurrencyPairEnabled(source, target string, enabled bool) (*domain.CurrencyPair, error) {
+	if _, err := s.currencyPair(source, target); err != nil {
+		return nil, err
+	}
+
+	s.pairs.set(domain.RatePair(source, target), enabled)
+	return &domain.CurrencyPair{Source: source, Target: target, Enabled: enabled}, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// Defaults for the transfer poller
+const (
+	defaultPollConcurrency = 4
+	defaultPollLookback    = 7 * 24 * time.Hour
+)
+
+// RunTransferPoller polls Wise for the status of PROCESSING transfers every
+// interval until ctx is cancelled. It backs up the transfer callback, which
+// can be lost or delayed. Each round also reaps stuck transfers.
+func (s *RemittanceService) RunTransferPoller(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.PollTransfers(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("transfer poll failed: %v", err)
+			}
+			if err := s.ReapStuckTransfers(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("transfer reap failed: %v", err)
+			}
+		}
+	}
+}
+
+// PollTransfers checks every PROCESSING transaction created within the
+// lookback window against Wise and applies final statuses. At most
+// TransferPollConcurrency Wise calls are in flight at once.
+func (s *RemittanceService) PollTransfers(ctx context.Context) error {
+	concurrency := s.config.TransferPollConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultPollConcurrency
+	}
+	lookback := s.config.TransferPollLookback
+	if lookback <= 0 {
+		lookback = defaultPollLookback
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	now := time.Now()
+	return s.forEachTransactionByStatus(ctx, domain.StatusProcessing, now.Add(-lookback), now, func(tx *domain.Transaction) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := s.pollTransfer(ctx, tx); err != nil {
+				log.Printf("transfer poll failed: transaction_id=%s transfer_id=%s error=%v", tx.ID, tx.TransferID, err)
+			}
+		}()
+		return nil
+	})
+}
+
+// pollTransfer fetches the Wise status of one transfer and applies it when
+// it is final
+func (s *RemittanceService) pollTransfer(ctx context.Context, tx *domain.Transaction) error {
+	if tx.TransferID == "" {
+		return nil
+	}
+
+	status, err := s.wiseClient.GetTransferStatus(ctx, tx.TransferID)
+	if err != nil {
+		return err
+	}
+
+	switch TransferCallbackStatus(status) {
+	case TransferCallbackCompleted, TransferCallbackFailed:
+		return s.HandleTransferCallback(ctx, &TransferCallback{TransactionID: tx.ID, Status: TransferCallbackStatus(status)})
+	default:
+		return nil
+	}
+}
+
+// ReapStuckTransfers recovers transactions that have been PROCESSING without
+// a transfer ID for longer than ProcessingTimeout, which happens when the
+// process dies while creating the Wise transfer. They are retried when
+// RetryStuckTransfers is set and failed otherwise.
+func (s *RemittanceService) ReapStuckTransfers(ctx context.Context) error {
+	timeout := s.config.ProcessingTimeout
+	if timeout <= 0 {
+		return nil
+	}
+	lookback := s.config.TransferPollLookback
+	if lookback <= 0 {
+		lookback = defaultPollLookback
+	}
+
+	now := time.Now()
+	return s.forEachTransactionByStatus(ctx, domain.StatusProcessing, now.Add(-lookback), now, func(tx *domain.Transaction) error {
+		if tx.TransferID != "" || tx.ProcessingStartedAt == nil || now.Sub(*tx.ProcessingStartedAt) < timeout {
+			return nil
+		}
+		if err := s.recoverStuckTransfer(ctx, tx); err != nil {
+			log.Printf("stuck transfer recovery failed: transaction_id=%s error=%v", tx.ID, err)
+		}
+		return nil
+	})
+}
+
+// recoverStuckTransfer retries or fails one stuck transaction. The status
+// change is conditional on the transaction being as read, see unclaimed, so
+// one whose transfer was recorded or that was claimed again meanwhile is
+// left alone.
+func (s *RemittanceService) recoverStuckTransfer(ctx context.Context, tx *domain.Transaction) error {
+	var err error
+	if s.config.RetryStuckTransfers {
+		log.Printf("retrying stuck transfer: transaction_id=%s processing_started_at=%s", tx.ID, tx.ProcessingStartedAt)
+		err = s.retryTransfer(ctx, tx)
+	} else {
+		log.Printf("failing stuck transfer: transaction_id=%s processing_started_at=%s", tx.ID, tx.ProcessingStartedAt)
+		fields := append(unclaimed(tx), repository.Set("failure_reason", domain.FailureReasonTransferStuck))
+		err = s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusProcessing, domain.StatusFailed, fields...)
+	}
+
+	if errors.Is(err, repository.ErrStatusMismatch) {
+		log.Printf("stuck transfer moved on, left alone: transaction_id=%s", tx.ID)
+		return nil
+	}
+	return err
+}
+
+// unclaimed conditions a change to a PROCESSING transaction on it still
+// having no transfer ID and the claim it was read with. tx may be a stale
+// copy from the status index, and a slow Wise call may yet record the
+// transfer it created.
+func unclaimed(tx *domain.Transaction) []repository.Field {
+	claim := repository.RequireAbsent("processing_started_at")
+	if tx.ProcessingStartedAt != nil {
+		claim = repository.RequireEqual("processing_started_at", *tx.ProcessingStartedAt)
+	}
+	return []repository.Field{repository.RequireAbsent("transfer_id"), claim}
+}
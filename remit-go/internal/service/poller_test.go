@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// processing stores a PROCESSING transaction for the Wise transfer
+func (e *testEnv) processing(transferID string, createdAt time.Time) *domain.Transaction {
+	tx := e.seed("user-1", 10000, domain.StatusProcessing, createdAt)
+	tx.TransferID = transferID
+	e.repo.put(tx)
+	return tx
+}
+
+func TestPollTransfers(t *testing.T) {
+	tests := []struct {
+		name       string
+		transferID string
+		age        time.Duration
+		wiseStatus string // empty while Wise reports it processing
+		want       domain.TransactionStatus
+	}{
+		{"completed", "TR-1", time.Hour, "COMPLETED", domain.StatusCompleted},
+		{"failed", "TR-1", time.Hour, "FAILED", domain.StatusFailed},
+		{"still processing", "TR-1", time.Hour, "", domain.StatusProcessing},
+		{"not yet a Wise status", "TR-1", time.Hour, "PENDING", domain.StatusProcessing},
+		{"no transfer yet", "", time.Hour, "COMPLETED", domain.StatusProcessing},
+		{"outside the lookback", "TR-1", 48 * time.Hour, "COMPLETED", domain.StatusProcessing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.TransferPollLookback = 24 * time.Hour })
+			tx := env.processing(tt.transferID, time.Now().Add(-tt.age))
+			if tt.wiseStatus != "" {
+				env.wise.statuses["TR-1"] = tt.wiseStatus
+			}
+
+			if err := env.svc.PollTransfers(context.Background()); err != nil {
+				t.Fatalf("PollTransfers() = %v", err)
+			}
+			if got := env.repo.tx(t, tx.ID).Status; got != tt.want {
+				t.Errorf("status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPollTransfersConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		want        int
+	}{
+		{"configured", 2, 2},
+		{"default", 0, defaultPollConcurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.TransferPollConcurrency = tt.concurrency })
+			env.wise.delay = 20 * time.Millisecond
+			var txns []*domain.Transaction
+			for i := range 12 {
+				transferID := fmt.Sprintf("TR-%d", i)
+				txns = append(txns, env.processing(transferID, time.Now().Add(-time.Hour)))
+				env.wise.statuses[transferID] = "COMPLETED"
+			}
+
+			if err := env.svc.PollTransfers(context.Background()); err != nil {
+				t.Fatalf("PollTransfers() = %v", err)
+			}
+			if env.wise.maxFlight != tt.want {
+				t.Errorf("Wise calls in flight = %d, want %d", env.wise.maxFlight, tt.want)
+			}
+			// Every poll has finished by the time PollTransfers returns
+			for _, tx := range txns {
+				if got := env.repo.tx(t, tx.ID).Status; got != domain.StatusCompleted {
+					t.Errorf("%s status = %s, want COMPLETED", tx.ID, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReapStuckTransfers(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		retry      bool
+		transferID string
+		started    time.Duration // how long ago it was claimed; zero for never
+		wantStatus domain.TransactionStatus
+		wantReason string
+		wantWise   int
+	}{
+		{"failed", 10 * time.Minute, false, "", 20 * time.Minute, domain.StatusFailed, domain.FailureReasonTransferStuck, 0},
+		{"retried", 10 * time.Minute, true, "", 20 * time.Minute, domain.StatusProcessing, "", 1},
+		{"within the timeout", 10 * time.Minute, false, "", 5 * time.Minute, domain.StatusProcessing, "", 0},
+		{"transfer created", 10 * time.Minute, false, "TR-9", 20 * time.Minute, domain.StatusProcessing, "", 0},
+		{"never claimed", 10 * time.Minute, false, "", 0, domain.StatusProcessing, "", 0},
+		{"reaper disabled", 0, false, "", 20 * time.Minute, domain.StatusProcessing, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.ProcessingTimeout = tt.timeout
+				cfg.RetryStuckTransfers = tt.retry
+			})
+			tx := env.processing(tt.transferID, time.Now().Add(-time.Hour))
+			if tt.started > 0 {
+				tx.ProcessingStartedAt = ptr(time.Now().Add(-tt.started))
+			}
+			env.repo.put(tx)
+
+			if err := env.svc.ReapStuckTransfers(context.Background()); err != nil {
+				t.Fatalf("ReapStuckTransfers() = %v", err)
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus || got.FailureReason != tt.wantReason {
+				t.Errorf("status = %s, reason = %q; want %s, %q", got.Status, got.FailureReason, tt.wantStatus, tt.wantReason)
+			}
+			if n := env.wise.calls(); n != tt.wantWise {
+				t.Errorf("transfers created = %d, want %d", n, tt.wantWise)
+			}
+			if tt.retry && (got.TransferID != "TR-1" || !got.ProcessingStartedAt.After(time.Now().Add(-time.Minute))) {
+				t.Errorf("transfer = %q, claimed at %v; want TR-1 claimed anew", got.TransferID, got.ProcessingStartedAt)
+			}
+		})
+	}
+}
+
+func TestReapStuckTransfersMovedOn(t *testing.T) {
+	tests := []struct {
+		name       string
+		retry      bool
+		change     func(tx *domain.Transaction) // after the reaper's read, before its update
+		wantStatus domain.TransactionStatus
+		wantWise   int
+	}{
+		{"transfer recorded, failed", false, func(tx *domain.Transaction) { tx.TransferID = "TR-9" }, domain.StatusProcessing, 0},
+		{"claimed again, failed", false, func(tx *domain.Transaction) { tx.ProcessingStartedAt = ptr(time.Now()) }, domain.StatusProcessing, 0},
+		{"transfer recorded, retried", true, func(tx *domain.Transaction) { tx.TransferID = "TR-9" }, domain.StatusProcessing, 0},
+		{"claimed again, retried", true, func(tx *domain.Transaction) { tx.ProcessingStartedAt = ptr(time.Now()) }, domain.StatusProcessing, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.ProcessingTimeout = 10 * time.Minute
+				cfg.RetryStuckTransfers = tt.retry
+			})
+			tx := env.processing("", time.Now().Add(-time.Hour))
+			tx.ProcessingStartedAt = ptr(time.Now().Add(-20 * time.Minute))
+			env.repo.put(tx)
+
+			// The slow Wise call finishes after the status index was read
+			env.repo.afterStatusList = func(domain.TransactionStatus) {
+				stored := env.repo.tx(t, tx.ID)
+				tt.change(stored)
+				env.repo.put(stored)
+			}
+			if err := env.svc.ReapStuckTransfers(context.Background()); err != nil {
+				t.Fatalf("ReapStuckTransfers() = %v", err)
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus || got.FailureReason != "" {
+				t.Errorf("status = %s, reason = %q; want %s left alone", got.Status, got.FailureReason, tt.wantStatus)
+			}
+			if n := env.wise.calls(); n != tt.wantWise {
+				t.Errorf("transfers created = %d, want %d", n, tt.wantWise)
+			}
+		})
+	}
+}
+
+func TestInitiateTransferClaimsBeforeWise(t *testing.T) {
+	env := newTestEnv(t)
+	tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+	env.wise.delay = 100 * time.Millisecond
+
+	done := make(chan error, 1)
+	go func() { done <- env.svc.InitiateTransfer(context.Background(), tx.ID) }()
+
+	// While Wise is creating the transfer the transaction is already claimed
+	waitFor(t, "the Wise call", func() bool { return env.wise.calls() == 1 })
+	claimed := env.repo.tx(t, tx.ID)
+	if claimed.Status != domain.StatusProcessing || claimed.ProcessingStartedAt == nil || claimed.TransferID != "" {
+		t.Errorf("during the Wise call: status %s, claimed at %v, transfer %q; want PROCESSING, claimed, no transfer",
+			claimed.Status, claimed.ProcessingStartedAt, claimed.TransferID)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("InitiateTransfer() = %v", err)
+	}
+	if got := env.repo.tx(t, tx.ID); got.TransferID != "TR-1" {
+		t.Errorf("transfer = %q, want TR-1", got.TransferID)
+	}
+}
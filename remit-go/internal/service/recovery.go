@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// ResumeInterruptedTransactions drives forward, once, the transactions a
+// previous process left mid-flight: paid transactions whose transfer was
+// never started get it started, PROCESSING transactions with a transfer ID
+// are reconciled against Wise, and those without one are recovered like the
+// reaper would, retried or failed. Only transactions created within the
+// poller lookback and claimed before the call are touched.
+//
+// Every step moves the transaction with a conditional status update, so a
+// transaction another instance or a late callback has moved on is left
+// alone. Failures are logged per transaction and do not stop the others.
+func (s *RemittanceService) ResumeInterruptedTransactions(ctx context.Context) error {
+	lookback := s.config.TransferPollLookback
+	if lookback <= 0 {
+		lookback = defaultPollLookback
+	}
+	now := time.Now()
+	from := now.Add(-lookback)
+
+	var transfers, reconciled, recovered int
+	err := s.forEachTransactionByStatus(ctx, domain.StatusPaymentReceived, from, now, func(tx *domain.Transaction) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.InitiateTransfer(ctx, tx.ID); err != nil {
+			log.Printf("resume transfer failed: transaction_id=%s error=%v", tx.ID, err)
+			return nil
+		}
+		transfers++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.forEachTransactionByStatus(ctx, domain.StatusProcessing, from, now, func(tx *domain.Transaction) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if tx.TransferID != "" {
+			if err := s.pollTransfer(ctx, tx); err != nil {
+				log.Printf("resume reconcile failed: transaction_id=%s transfer_id=%s error=%v", tx.ID, tx.TransferID, err)
+				return nil
+			}
+			reconciled++
+			return nil
+		}
+
+		// Claimed after we started: another instance is creating the transfer
+		if tx.ProcessingStartedAt == nil || tx.ProcessingStartedAt.After(now) {
+			return nil
+		}
+		if err := s.recoverStuckTransfer(ctx, tx); err != nil {
+			log.Printf("resume recovery failed: transaction_id=%s error=%v", tx.ID, err)
+			return nil
+		}
+		recovered++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("resumed interrupted transactions: transfers_started=%d reconciled=%d recovered=%d",
+		transfers, reconciled, recovered)
+	return nil
+}
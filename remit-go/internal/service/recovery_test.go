@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+func TestResumeInterruptedTransactionsMovedOn(t *testing.T) {
+	tests := []struct {
+		name   string
+		retry  bool
+		change func(tx *domain.Transaction) // after the scan, before the recovery
+	}{
+		{"transfer recorded, failed", false, func(tx *domain.Transaction) { tx.TransferID = "TR-9" }},
+		{"transfer recorded, retried", true, func(tx *domain.Transaction) { tx.TransferID = "TR-9" }},
+		{"claimed again, failed", false, func(tx *domain.Transaction) { tx.ProcessingStartedAt = ptr(time.Now().Add(-time.Second)) }},
+		{"claimed again, retried", true, func(tx *domain.Transaction) { tx.ProcessingStartedAt = ptr(time.Now().Add(-time.Second)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.RetryStuckTransfers = tt.retry })
+			tx := env.seed("user-1", 10000, domain.StatusProcessing, time.Now().Add(-time.Hour))
+			tx.ProcessingStartedAt = ptr(time.Now().Add(-20 * time.Minute))
+			env.repo.put(tx)
+
+			// Another instance, still inside its Wise call at startup,
+			// finishes after the scan
+			env.repo.afterStatusList = func(status domain.TransactionStatus) {
+				if status != domain.StatusProcessing {
+					return
+				}
+				stored := env.repo.tx(t, tx.ID)
+				tt.change(stored)
+				env.repo.put(stored)
+			}
+			if err := env.svc.ResumeInterruptedTransactions(context.Background()); err != nil {
+				t.Fatalf("ResumeInterruptedTransactions() = %v", err)
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != domain.StatusProcessing || got.FailureReason != "" {
+				t.Errorf("status = %s, reason = %q; want PROCESSING left alone", got.Status, got.FailureReason)
+			}
+			if n := env.wise.calls(); n != 0 {
+				t.Errorf("transfers created = %d, want none", n)
+			}
+		})
+	}
+}
+
+func TestResumeInterruptedTransactions(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     domain.TransactionStatus
+		transferID string
+		wiseStatus string
+		started    time.Duration // how long ago it was claimed; zero for never
+		age        time.Duration
+		retry      bool
+		wantStatus domain.TransactionStatus
+		wantReason string
+		wantWise   int
+	}{
+		{"paid without a transfer", domain.StatusPaymentReceived, "", "", 0, time.Hour, false, domain.StatusProcessing, "", 1},
+		{"transfer completed meanwhile", domain.StatusProcessing, "TR-9", "COMPLETED", 20 * time.Minute, time.Hour, false, domain.StatusCompleted, "", 0},
+		{"transfer still processing", domain.StatusProcessing, "TR-9", "", 20 * time.Minute, time.Hour, false, domain.StatusProcessing, "", 0},
+		{"claimed without a transfer, failed", domain.StatusProcessing, "", "", 20 * time.Minute, time.Hour, false, domain.StatusFailed, domain.FailureReasonTransferStuck, 0},
+		{"claimed without a transfer, retried", domain.StatusProcessing, "", "", 20 * time.Minute, time.Hour, true, domain.StatusProcessing, "", 1},
+		{"never claimed", domain.StatusProcessing, "", "", 0, time.Hour, false, domain.StatusProcessing, "", 0},
+		{"outside the lookback", domain.StatusPaymentReceived, "", "", 0, 48 * time.Hour, false, domain.StatusPaymentReceived, "", 0},
+		{"finished", domain.StatusCompleted, "TR-9", "COMPLETED", 20 * time.Minute, time.Hour, false, domain.StatusCompleted, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.TransferPollLookback = 24 * time.Hour
+				cfg.RetryStuckTransfers = tt.retry
+			})
+			tx := env.seed("user-1", 10000, tt.status, time.Now().Add(-tt.age))
+			tx.TransferID = tt.transferID
+			if tt.started > 0 {
+				tx.ProcessingStartedAt = ptr(time.Now().Add(-tt.started))
+			}
+			env.repo.put(tx)
+			if tt.wiseStatus != "" {
+				env.wise.statuses[tt.transferID] = tt.wiseStatus
+			}
+
+			// A second pass, as after another restart, changes nothing more
+			for range 2 {
+				if err := env.svc.ResumeInterruptedTransactions(context.Background()); err != nil {
+					t.Fatalf("ResumeInterruptedTransactions() = %v", err)
+				}
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus || got.FailureReason != tt.wantReason {
+				t.Errorf("status = %s, reason = %q; want %s, %q", got.Status, got.FailureReason, tt.wantStatus, tt.wantReason)
+			}
+			if n := env.wise.calls(); n != tt.wantWise {
+				t.Errorf("transfers created = %d, want %d", n, tt.wantWise)
+			}
+		})
+	}
+}
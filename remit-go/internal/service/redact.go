@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// RedactionResult summarizes the redaction of a user's transactions
+type RedactionResult struct {
+	UserID   string   `json:"user_id"`
+	Redacted int      `json:"redacted"`
+	Skipped  []string `json:"skipped,omitempty"` // still in flight, not redacted
+}
+
+// RedactTransaction irreversibly erases the recipient's personal data from a
+// completed or failed transaction, keeping amounts, fees, statuses and audit
+// fields. The redaction is recorded with who performed it. A transaction
+// already redacted is returned unchanged.
+func (s *RemittanceService) RedactTransaction(ctx context.Context, txID, by string) (*domain.Transaction, error) {
+	tx, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if err := s.redact(ctx, tx, by); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// RedactUserData redacts every completed or failed transaction of a user.
+// Transactions still in flight are skipped and listed so they can be
+// redacted once they finish.
+func (s *RemittanceService) RedactUserData(ctx context.Context, userID, by string) (*RedactionResult, error) {
+	result := &RedactionResult{UserID: userID}
+
+	cursor := ""
+	for {
+		txns, next, err := s.repo.ListTransactionsByUser(ctx, userID, 100, cursor, repository.SortDescending)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transactions: %w", err)
+		}
+
+		for _, tx := range txns {
+			if tx.RedactedAt != nil {
+				continue
+			}
+			if !tx.IsTerminal() {
+				result.Skipped = append(result.Skipped, tx.ID)
+				continue
+			}
+			if err := s.redact(ctx, tx, by); err != nil {
+				return nil, err
+			}
+			result.Redacted++
+		}
+
+		if next == "" {
+			return result, nil
+		}
+		cursor = next
+	}
+}
+
+// redact clears the transaction's personal data and stores only the changed
+// attributes, conditional on the status so a transaction cannot be redacted
+// while it moves
+func (s *RemittanceService) redact(ctx context.Context, tx *domain.Transaction, by string) error {
+	if tx.RedactedAt != nil {
+		return nil
+	}
+	if !tx.IsTerminal() {
+		return ErrInvalidStatus
+	}
+
+	tx.Redact(by, domain.Now())
+	err := s.repo.UpdateTransactionStatus(ctx, tx.ID, tx.Status, tx.Status,
+		repository.Set("recipient_details", tx.RecipientDetails),
+		repository.Remove("recipient_hash"),
+		repository.Set("redacted_at", tx.RedactedAt),
+		repository.Set("redacted_by", tx.RedactedBy),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	log.Printf("transaction redacted: transaction_id=%s user_id=%s redacted_by=%s", tx.ID, tx.UserID, by)
+	return nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+func TestRedactTransaction(t *testing.T) {
+	tests := []struct {
+		status  domain.TransactionStatus
+		wantErr error
+	}{
+		{domain.StatusCompleted, nil},
+		{domain.StatusFailed, nil},
+		{domain.StatusPaymentPending, ErrInvalidStatus},
+		{domain.StatusPaymentReceived, ErrInvalidStatus},
+		{domain.StatusProcessing, ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			env := newTestEnv(t)
+			tx := env.seed("user-1", 10000, tt.status, time.Now())
+			tx.TransferID = "TR-9"
+			tx.RecipientHash = "hash-1"
+			tx.RecipientDetails.BankName = "Test Bank"
+			env.repo.put(tx)
+
+			_, err := env.svc.RedactTransaction(context.Background(), tx.ID, "admin-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RedactTransaction() = %v, want %v", err, tt.wantErr)
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if tt.wantErr != nil {
+				if got.RecipientDetails.Name != "Jane Doe" || got.RedactedAt != nil {
+					t.Errorf("recipient = %+v, redacted at %v; want it untouched", got.RecipientDetails, got.RedactedAt)
+				}
+				return
+			}
+
+			recipient := got.RecipientDetails
+			if recipient.Name != "" || recipient.BankAccount != "" || got.RecipientHash != "" {
+				t.Errorf("recipient = %+v, hash %q; want the name, account and hash erased", recipient, got.RecipientHash)
+			}
+			if recipient.BankCode != "TD001" || recipient.BankName != "Test Bank" {
+				t.Errorf("bank = %q %q, want TD001 Test Bank kept", recipient.BankCode, recipient.BankName)
+			}
+			if got.Status != tt.status || got.SourceAmount != 10000 || got.Fees.TotalFee != 50 ||
+				got.ExchangeRate != testRate || got.TransferID != "TR-9" || got.UserID != "user-1" {
+				t.Errorf("transaction = %+v, want financial and audit fields kept", got)
+			}
+			if got.RedactedAt == nil || got.RedactedBy != "admin-1" {
+				t.Errorf("redacted at %v by %q, want now by admin-1", got.RedactedAt, got.RedactedBy)
+			}
+		})
+	}
+}
+
+func TestRedactTransactionOnce(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	tx := env.seed("user-1", 10000, domain.StatusCompleted, time.Now())
+
+	if _, err := env.svc.RedactTransaction(ctx, tx.ID, "admin-1"); err != nil {
+		t.Fatalf("RedactTransaction() = %v", err)
+	}
+	first := env.repo.tx(t, tx.ID).RedactedAt
+
+	again, err := env.svc.RedactTransaction(ctx, tx.ID, "admin-2")
+	if err != nil {
+		t.Fatalf("second RedactTransaction() = %v", err)
+	}
+	got := env.repo.tx(t, tx.ID)
+	if !got.RedactedAt.Equal(*first) || got.RedactedBy != "admin-1" || again.RedactedBy != "admin-1" {
+		t.Errorf("redacted at %v by %q, want the first redaction by admin-1 kept", got.RedactedAt, got.RedactedBy)
+	}
+}
+
+func TestRedactUserData(t *testing.T) {
+	env := newTestEnv(t)
+	completed := env.seed("user-1", 10000, domain.StatusCompleted, time.Now())
+	failed := env.seed("user-1", 20000, domain.StatusFailed, time.Now())
+	inFlight := env.seed("user-1", 30000, domain.StatusProcessing, time.Now())
+	other := env.seed("user-2", 10000, domain.StatusCompleted, time.Now())
+
+	result, err := env.svc.RedactUserData(context.Background(), "user-1", "admin-1")
+	if err != nil {
+		t.Fatalf("RedactUserData() = %v", err)
+	}
+	if result.UserID != "user-1" || result.Redacted != 2 || fmt.Sprint(result.Skipped) != fmt.Sprint([]string{inFlight.ID}) {
+		t.Errorf("result = %+v, want 2 redacted and %s skipped", result, inFlight.ID)
+	}
+
+	for _, tt := range []struct {
+		tx           *domain.Transaction
+		wantRedacted bool
+	}{
+		{completed, true},
+		{failed, true},
+		{inFlight, false},
+		{other, false},
+	} {
+		got := env.repo.tx(t, tt.tx.ID)
+		if redacted := got.RedactedAt != nil && got.RecipientDetails.Name == ""; redacted != tt.wantRedacted {
+			t.Errorf("%s of %s: redacted %v, want %v", got.Status, got.UserID, redacted, tt.wantRedacted)
+		}
+	}
+
+	// Running again redacts nothing more
+	again, err := env.svc.RedactUserData(context.Background(), "user-1", "admin-1")
+	if err != nil {
+		t.Fatalf("second RedactUserData() = %v", err)
+	}
+	if again.Redacted != 0 || len(again.Skipped) != 1 {
+		t.Errorf("second result = %+v, want nothing redacted and the in-flight one skipped", again)
+	}
+}
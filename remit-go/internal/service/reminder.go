@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/integration"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+// notificationPaymentReminder is the kind of the reminder sent before a
+// payment link expires
+const notificationPaymentReminder = "payment_reminder"
+
+// reminderLookback bounds how old a transaction awaiting payment may be to
+// still get a reminder
+const reminderLookback = 7 * 24 * time.Hour
+
+// RunPaymentReminders sends payment reminders every interval until ctx is
+// cancelled
+func (s *RemittanceService) RunPaymentReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SendPaymentReminders(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("payment reminders failed: %v", err)
+			}
+		}
+	}
+}
+
+// SendPaymentReminders notifies the sender of every PAYMENT_PENDING
+// transaction whose payment link expires within PaymentReminderLead. Each
+// link gets at most one reminder, even with several instances sweeping.
+func (s *RemittanceService) SendPaymentReminders(ctx context.Context) error {
+	lead := s.config.PaymentReminderLead
+	if lead <= 0 {
+		return nil
+	}
+
+	now := domain.Now()
+	return s.forEachTransactionByStatus(ctx, domain.StatusPaymentPending, now.Add(-reminderLookback), now, func(tx *domain.Transaction) error {
+		if !reminderDue(tx, now, lead) {
+			return nil
+		}
+		if err := s.sendPaymentReminder(ctx, tx, now); err != nil {
+			log.Printf("payment reminder failed: transaction_id=%s error=%v", tx.ID, err)
+		}
+		return nil
+	})
+}
+
+// reminderDue reports whether the transaction's pending link expires within
+// lead of now and no reminder was sent for it yet
+func reminderDue(tx *domain.Transaction, now time.Time, lead time.Duration) bool {
+	p := tx.PaymentDetails
+	if tx.ReminderSentAt != nil || p == nil || p.Status != "PENDING" || p.ExpiresAt == nil {
+		return false
+	}
+	return now.Before(*p.ExpiresAt) && !now.Before(p.ExpiresAt.Add(-lead))
+}
+
+// sendPaymentReminder records the reminder before sending it, so a sweep
+// racing this one or a later one never sends it twice. A failed send is
+// dead-lettered rather than retried by the next sweep.
+func (s *RemittanceService) sendPaymentReminder(ctx context.Context, tx *domain.Transaction, now time.Time) error {
+	err := s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusPaymentPending, domain.StatusPaymentPending,
+		repository.SetIfAbsent("reminder_sent_at", now))
+	if errors.Is(err, repository.ErrStatusMismatch) {
+		return nil // paid meanwhile or already reminded
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record reminder: %w", err)
+	}
+
+	if err := s.notifyPaymentReminder(ctx, tx); err != nil {
+		log.Printf("payment reminder not sent: transaction_id=%s error=%v", tx.ID, err)
+		s.deadLetter(ctx, domain.NewDeadLetter(newTaskID(), taskPaymentReminder, tx.ID,
+			map[string]string{"transaction_id": tx.ID}, err))
+	}
+	return nil
+}
+
+// replayPaymentReminder sends a dead-lettered reminder again, as long as
+// the transaction still awaits payment on an unexpired link
+func (s *RemittanceService) replayPaymentReminder(ctx context.Context, txID string) error {
+	tx, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	p := tx.PaymentDetails
+	if tx.Status != domain.StatusPaymentPending || p == nil || p.ExpiresAt == nil || !domain.Now().Before(*p.ExpiresAt) {
+		return fmt.Errorf("transaction no longer awaits payment: %w", ErrInvalidStatus)
+	}
+	return s.notifyPaymentReminder(ctx, tx)
+}
+
+// notifyPaymentReminder sends the sender the reminder to pay before the
+// link expires
+func (s *RemittanceService) notifyPaymentReminder(ctx context.Context, tx *domain.Transaction) error {
+	amount := domain.FormatAmount(tx.CollectibleAmount(s.config.FeeModel), tx.SourceCurrency)
+	return s.notifier.Notify(ctx, &integration.Notification{
+		UserID:        tx.UserID,
+		TransactionID: tx.ID,
+		Kind:          notificationPaymentReminder,
+		Message: fmt.Sprintf("Your payment of %s for transaction %s expires at %s. Pay before then to send your transfer.",
+			amount, tx.ID, tx.PaymentDetails.ExpiresAt.UTC().Format(time.RFC3339)),
+	})
+}
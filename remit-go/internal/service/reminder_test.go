@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+func TestSendPaymentReminders(t *testing.T) {
+	tests := []struct {
+		name     string
+		validity time.Duration
+		lead     time.Duration
+		status   domain.TransactionStatus // moved to after the link, if set
+		sweeps   int
+		wantSent int
+	}{
+		{"due", 10 * time.Minute, 15 * time.Minute, "", 1, 1},
+		{"due over repeated sweeps", 10 * time.Minute, 15 * time.Minute, "", 3, 1},
+		{"not yet due", time.Hour, 15 * time.Minute, "", 1, 0},
+		{"reminders off", 10 * time.Minute, 0, "", 1, 0},
+		{"link without expiry", 0, 15 * time.Minute, "", 1, 0},
+		{"paid meanwhile", 10 * time.Minute, 15 * time.Minute, domain.StatusPaymentReceived, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.PaymentLinkValidity = tt.validity
+				cfg.PaymentReminderLead = tt.lead
+			})
+			tx := env.awaitingPayment(t, "user-1", 10000)
+			if tt.status != "" {
+				env.move(t, tx.ID, tt.status)
+			}
+
+			for range tt.sweeps {
+				if err := env.svc.SendPaymentReminders(context.Background()); err != nil {
+					t.Fatalf("SendPaymentReminders() = %v", err)
+				}
+			}
+
+			if n := len(env.notifier.sent); n != tt.wantSent {
+				t.Fatalf("reminders sent = %d, want %d", n, tt.wantSent)
+			}
+			if tt.wantSent == 0 {
+				return
+			}
+			sent := env.notifier.sent[0]
+			if sent.UserID != "user-1" || sent.TransactionID != tx.ID || sent.Kind != notificationPaymentReminder {
+				t.Errorf("reminder = %+v, want a payment reminder for %s", sent, tx.ID)
+			}
+			if env.repo.tx(t, tx.ID).ReminderSentAt == nil {
+				t.Error("ReminderSentAt not recorded")
+			}
+		})
+	}
+}
+
+func TestSendPaymentRemindersConcurrentSweeps(t *testing.T) {
+	const sweeps = 8
+	env := newTestEnv(t, func(cfg *Config) {
+		cfg.PaymentLinkValidity = 10 * time.Minute
+		cfg.PaymentReminderLead = 15 * time.Minute
+	})
+	env.awaitingPayment(t, "user-1", 10000)
+	env.awaitingPayment(t, "user-2", 10000)
+
+	var wg sync.WaitGroup
+	for range sweeps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := env.svc.SendPaymentReminders(context.Background()); err != nil {
+				t.Errorf("SendPaymentReminders() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(env.notifier.sent); n != 2 {
+		t.Errorf("reminders sent = %d, want one per transaction", n)
+	}
+}
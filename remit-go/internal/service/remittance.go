@@ -2,64 +2,237 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/remit-demo/remit-go/internal/compliance"
+	"github.com/remit-demo/remit-go/internal/config"
 	"github.com/remit-demo/remit-go/internal/domain"
 	"github.com/remit-demo/remit-go/internal/integration"
+	"github.com/remit-demo/remit-go/internal/metrics"
 	"github.com/remit-demo/remit-go/internal/repository"
+	"github.com/remit-demo/remit-go/internal/translit"
 )
 
 // RemittanceService implements the Service interface
 type RemittanceService struct {
 	repo         repository.Repository
 	upiClient    integration.UPIClient
+	payments     map[domain.PaymentMethod]integration.PaymentProvider
 	adBankClient integration.ADBankClient
 	wiseClient   integration.WiseClient
+	compliance   compliance.Checker
+	notifier     integration.Notifier
 	config       *Config
+
+	// transferSlots bounds concurrent Wise transfer creations; nil when
+	// unlimited
+	transferSlots chan struct{}
+
+	// pairs holds the runtime enabled state of each corridor
+	pairs *pairSwitches
+
+	// exports holds the transaction export jobs
+	exports *exportJobs
 }
 
 // Config holds service configuration
 type Config struct {
-	MinAmount    float64
-	MaxAmount    float64
-	DailyLimit   float64
-	BaseFee      float64
-	VariableFee  float64
-	RateValidity time.Duration
+	MinAmount           float64
+	MaxAmount           float64
+	DailyLimit          float64
+	MaxOpenTransactions int
+	AmountPrecision     map[string]int // decimal places accepted per currency, overriding its minor units
+	BaseFee             float64
+	VariableFee         float64
+	FeeTiers            []config.FeeTierConfig // replace VariableFee when set
+	FeeModel            domain.FeeModel        // decides the amount collected; exclusive when empty
+	RateValidity        time.Duration
+	PromoCodes          []config.PromoCodeConfig
+	PaymentLinkValidity time.Duration
+	PayeeVPA            string // UPI address payments are collected into
+
+	// PaymentLinkStatuses are the statuses a payment link may be generated
+	// from. Defaults to INITIATED, and PAYMENT_PENDING for regeneration.
+	PaymentLinkStatuses []domain.TransactionStatus
+
+	// PaymentCurrencies lists the source currencies each payment method can
+	// collect. Methods not listed accept any currency.
+	PaymentCurrencies map[domain.PaymentMethod][]string
+
+	PaymentAmountTolerance float64
+	ReviewThreshold        float64
+
+	// PaymentReminderLead is how long before a payment link expires the
+	// sender is reminded. Zero disables reminders.
+	PaymentReminderLead time.Duration
+
+	// SettlementTolerance is the delivered amount variance accepted without
+	// review when Wise reports a transfer complete
+	SettlementTolerance config.SettlementToleranceConfig
+
+	// DuplicateWindow is how recently a transaction for the same user,
+	// amount and recipient counts as a double submit of a request without an
+	// idempotency key. Zero disables the check.
+	DuplicateWindow time.Duration
+
+	// RecentRecipientWindow is how recently a transaction to the same
+	// recipient earns a new one a warning. Zero disables the warning.
+	RecentRecipientWindow time.Duration
+
+	// ExportTTL is how long a finished export job and its file are kept.
+	// Zero keeps them for an hour.
+	ExportTTL time.Duration
+
+	// MaxActiveExports caps the unfinished exports per user and
+	// MaxExportRows the transactions per export. Zero selects 2 and 100000.
+	MaxActiveExports int
+	MaxExportRows    int
+
+	// TransferRetry controls how retryable Wise failures are retried
+	TransferRetry config.RetryConfig
+
+	// MaxConcurrentTransfers caps Wise transfer creations in flight across
+	// all requests. Zero means no limit.
+	MaxConcurrentTransfers int
+
+	// WiseCorridors are the "SOURCE/TARGET" pairs Wise transfers. Empty
+	// allows every configured pair.
+	WiseCorridors []string
+
+	// TransferPollConcurrency caps parallel Wise calls made by the transfer
+	// poller, which checks transfers created within TransferPollLookback
+	TransferPollConcurrency int
+	TransferPollLookback    time.Duration
+
+	// ProcessingTimeout is how long a transaction may stay PROCESSING
+	// without a transfer ID before the reaper recovers it: retried when
+	// RetryStuckTransfers is set, failed otherwise. Zero disables the reaper.
+	ProcessingTimeout   time.Duration
+	RetryStuckTransfers bool
+
+	// CurrencyPairs holds the per-corridor settings
+	CurrencyPairs []config.CurrencyPairConfig
+
+	// DefaultPair is the "SOURCE/TARGET" pair quoted when a request names
+	// none. Defaults to the first enabled pair.
+	DefaultPair string
+
+	// Tiers maps user tiers to their corridor restrictions
+	Tiers map[string]config.TierConfig
 }
 
-// NewRemittanceService creates a new remittance service instance
+// defaultPaymentLinkStatuses allow a first link for a new transaction and a
+// fresh one once a pending payment's link expires
+var defaultPaymentLinkStatuses = []domain.TransactionStatus{
+	domain.StatusInitiated,
+	domain.StatusPaymentPending,
+}
+
+// maxIDAttempts bounds how many fresh IDs a new transaction is given when
+// its ID collides with an existing one
+const maxIDAttempts = 3
+
+// Bounds of the variable fee, in the source currency
+const (
+	minVariableFee = 50
+	maxVariableFee = 5000
+)
+
+// NewRemittanceService creates a new remittance service instance. UPI is
+// always offered through upiClient; paymentProviders adds other methods.
 func NewRemittanceService(
 	repo repository.Repository,
 	upiClient integration.UPIClient,
+	paymentProviders map[domain.PaymentMethod]integration.PaymentProvider,
 	adBankClient integration.ADBankClient,
 	wiseClient integration.WiseClient,
+	complianceChecker compliance.Checker,
+	notifier integration.Notifier,
 	config *Config,
 ) *RemittanceService {
+	payments := map[domain.PaymentMethod]integration.PaymentProvider{
+		domain.PaymentMethodUPI: upiClient,
+	}
+	for method, provider := range paymentProviders {
+		payments[method] = provider
+	}
+
+	var transferSlots chan struct{}
+	if config.MaxConcurrentTransfers > 0 {
+		transferSlots = make(chan struct{}, config.MaxConcurrentTransfers)
+	}
+
 	return &RemittanceService{
-		repo:         repo,
-		upiClient:    upiClient,
-		adBankClient: adBankClient,
-		wiseClient:   wiseClient,
-		config:       config,
+		transferSlots: transferSlots,
+		pairs:         newPairSwitches(config.CurrencyPairs),
+		exports:       newExportJobs(config.ExportTTL, config.MaxActiveExports, config.MaxExportRows),
+		repo:          repo,
+		upiClient:     upiClient,
+		payments:      payments,
+		adBankClient:  adBankClient,
+		wiseClient:    wiseClient,
+		compliance:    complianceChecker,
+		notifier:      notifier,
+		config:        config,
 	}
 }
 
-// InitiateTransaction starts a new remittance transaction
-func (s *RemittanceService) InitiateTransaction(
-	ctx context.Context,
-	userID string,
-	amount float64,
-	recipient *domain.RecipientDetails,
-) (*domain.Transaction, error) {
+// InitiateTransaction starts a new remittance transaction. With an
+// idempotency key, retries of the same request return the original
+// transaction instead of creating another. Without one, a request matching
+// a transaction created within DuplicateWindow returns that transaction.
+func (s *RemittanceService) InitiateTransaction(ctx context.Context, req *InitiateRequest) (*domain.Transaction, error) {
+	if req.IdempotencyKey != "" {
+		return s.initiateIdempotent(ctx, req, func() (*domain.Transaction, error) {
+			return s.initiateTransaction(ctx, req)
+		})
+	}
+
+	dup, err := s.recentDuplicate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if dup != nil {
+		dup.Replayed = true
+		return dup, nil
+	}
+	return s.initiateTransaction(ctx, req)
+}
+
+func (s *RemittanceService) initiateTransaction(ctx context.Context, req *InitiateRequest) (*domain.Transaction, error) {
+	userID, amount, recipient := req.UserID, req.Amount, req.Recipient
+
 	// Validate amount
-	if err := s.validateAmount(amount); err != nil {
+	if err := s.validateAmount(amount, "INR"); err != nil {
 		return nil, err
 	}
 
 	// Validate recipient
-	if err := s.validateRecipient(recipient); err != nil {
+	if err := s.validateRecipient(ctx, recipient, "INR", "CAD"); err != nil {
+		return nil, err
+	}
+
+	// Check the payment method is offered
+	method := req.PaymentMethod
+	if method == "" {
+		method = domain.DefaultPaymentMethod
+	}
+	if _, ok := s.payments[method]; !ok {
+		return nil, ErrUnsupportedPaymentMethod
+	}
+	if err := s.checkPaymentCurrency(method, "INR"); err != nil {
+		return nil, err
+	}
+
+	// Check the user's tier may use the corridor
+	if err := s.checkCorridor(req.UserTier, "INR", "CAD"); err != nil {
 		return nil, err
 	}
 
@@ -68,249 +241,1481 @@ func (s *RemittanceService) InitiateTransaction(
 		return nil, err
 	}
 
-	// Get current exchange rate
-	rate, err := s.GetExchangeRate(ctx)
+	// Check open transaction cap
+	if err := s.checkOpenTransactions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Resolve promo code
+	promo, err := s.resolvePromoCode(ctx, userID, req.PromoCode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+		return nil, err
+	}
+
+	// Get current exchange rate, falling back to the last-known rate
+	rate, fallback, err := s.quoteRate(ctx, "INR", "CAD")
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate fees
-	fees := s.calculateFees(amount)
+	fees := s.calculateFees("INR", "CAD", amount, promo)
+
+	// The Latin spelling is derived when the transfer is sent, and the bank
+	// name looked up here; neither is taken from the client
+	recipient.NameTransliterated = ""
+	recipient.BankName = s.lookupBankName(ctx, recipient.BankCode)
 
 	// Create transaction
 	tx := domain.NewTransaction(userID, amount, "INR", "CAD", recipient)
-	tx.SetExchangeRate(rate)
 	tx.SetFees(fees)
+	tx.SetRates(rate.Rate, s.customerRate(tx.SourceCurrency, tx.TargetCurrency, rate.Rate), s.margin(tx.SourceCurrency, tx.TargetCurrency), s.config.FeeModel)
+	tx.SetRateSource(rate)
+	tx.FallbackRate = fallback
 	tx.UpdateStatus(domain.StatusInitiated)
+	tx.PaymentMethod = method
+	tx.Reference = req.Reference
+	if tx.Reference == "" {
+		tx.Reference = req.IdempotencyKey
+	}
+	tx.CreatedBy = userID
+	tx.CreatedByIP = req.ClientIP
+
+	// Refuse what Wise would reject once the payment is collected
+	if err := s.checkTransferLimits(tx); err != nil {
+		return nil, err
+	}
+
+	// Hold large amounts for review before they can be paid
+	if s.config.ReviewThreshold > 0 && amount > s.config.ReviewThreshold {
+		tx.RequiresReview = true
+		tx.UpdateStatus(domain.StatusPendingReview)
+	}
+
+	if w := s.recentRecipientWarning(ctx, userID, recipient); w != nil {
+		tx.Warnings = append(tx.Warnings, *w)
+	}
 
 	// Save transaction
-	if err := s.repo.CreateTransaction(ctx, tx); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	if err := s.createWithFreshID(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if pair, err := s.currencyPair(tx.SourceCurrency, tx.TargetCurrency); err == nil {
+		tx.EstimatedDelivery = tx.EstimateDelivery(deliveryWindow(pair), domain.Now())
 	}
 
 	return tx, nil
 }
 
-// GetTransaction retrieves a transaction by ID
-func (s *RemittanceService) GetTransaction(ctx context.Context, id string) (*domain.Transaction, error) {
-	return s.repo.GetTransaction(ctx, id)
+// createWithFreshID stores a new transaction, giving it a fresh ID when the
+// generated one is already taken
+func (s *RemittanceService) createWithFreshID(ctx context.Context, tx *domain.Transaction) error {
+	for attempt := 1; ; attempt++ {
+		err := s.repo.CreateTransaction(ctx, tx)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, repository.ErrAlreadyExists) {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+		if attempt == maxIDAttempts {
+			return ErrIDCollision
+		}
+		tx.ReassignID()
+	}
 }
 
-// ListUserTransactions retrieves transactions for a user
-func (s *RemittanceService) ListUserTransactions(
-	ctx context.Context,
-	userID string,
-	limit int,
-	lastKey string,
-) ([]*domain.Transaction, string, error) {
-	return s.repo.ListTransactionsByUser(ctx, userID, limit, lastKey)
+// Quote previews the fees, rates and delivered amount of a transfer without
+// creating it. The quoted rate is only indicative; the rate is locked when
+// the transaction is initiated.
+func (s *RemittanceService) Quote(ctx context.Context, req *QuoteRequest) (*domain.Quote, error) {
+	return s.quote(ctx, req, func(source string, rate float64, net func(amount float64) float64) (float64, error) {
+		return req.Amount, nil
+	})
 }
 
-// GeneratePaymentLink creates a UPI payment link
-func (s *RemittanceService) GeneratePaymentLink(ctx context.Context, txID string) (*domain.PaymentDetails, error) {
-	// Get transaction
-	tx, err := s.repo.GetTransaction(ctx, txID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get transaction: %w", err)
-	}
+// maxReverseQuoteSteps bounds the search for a reverse quote's source amount
+const maxReverseQuoteSteps = 64
 
-	// Generate UPI link
-	paymentLink, err := s.upiClient.GeneratePaymentLink(ctx, tx.ID, tx.SourceAmount)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate payment link: %w", err)
+// ReverseQuote previews the transaction that delivers at least
+// req.TargetAmount: the source amount to send, rounded up to a whole minor
+// unit, with its fees. When fees are taken out of the source amount it is
+// raised by the shortfall until what is left after fees converts to the
+// target.
+func (s *RemittanceService) ReverseQuote(ctx context.Context, req *QuoteRequest) (*domain.Quote, error) {
+	if req.TargetAmount <= 0 {
+		return nil, ErrInvalidAmount
 	}
 
-	// Create payment record
-	payment := &domain.PaymentDetails{
-		PaymentID:   fmt.Sprintf("PAY-%s", tx.ID),
-		PaymentLink: paymentLink,
-		Status:      "PENDING",
-	}
+	return s.quote(ctx, req, func(source string, rate float64, net func(amount float64) float64) (float64, error) {
+		need := domain.SourceAmountFor(req.TargetAmount, rate, source)
+		amount := need
+		for range maxReverseQuoteSteps {
+			shortfall := domain.RoundAmount(need-net(amount), source)
+			if shortfall <= 0 {
+				return amount, nil
+			}
+			amount = domain.CeilAmount(amount+shortfall, source)
+		}
+		return 0, ErrInvalidAmount
+	})
+}
 
-	if err := s.repo.CreatePayment(ctx, tx.ID, payment); err != nil {
-		return nil, fmt.Errorf("failed to create payment record: %w", err)
+// quote prices the source amount chosen by sourceAmount at the current
+// customer rate. sourceAmount is given net, which returns the part of an
+// amount converted after fees.
+func (s *RemittanceService) quote(
+	ctx context.Context,
+	req *QuoteRequest,
+	sourceAmount func(source string, rate float64, net func(amount float64) float64) (float64, error),
+) (*domain.Quote, error) {
+	source, target := req.SourceCurrency, req.TargetCurrency
+	if source == "" && target == "" {
+		source, target = s.defaultPair()
 	}
-
-	// Update transaction status
-	tx.UpdateStatus(domain.StatusPaymentPending)
-	tx.SetPaymentDetails(payment)
-	if err := s.repo.UpdateTransaction(ctx, tx); err != nil {
-		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	pair, err := s.currencyPair(source, target)
+	if err != nil || !pair.Enabled {
+		return nil, ErrInvalidCurrency
 	}
 
-	return payment, nil
-}
-
-// HandlePaymentCallback processes UPI payment callbacks
-func (s *RemittanceService) HandlePaymentCallback(ctx context.Context, paymentID string, status string) error {
-	// Get payment details
-	payment, err := s.repo.GetPayment(ctx, paymentID)
+	promo, err := s.resolvePromoCode(ctx, req.UserID, req.PromoCode)
 	if err != nil {
-		return fmt.Errorf("failed to get payment: %w", err)
+		return nil, err
 	}
 
-	// Update payment status
-	payment.Status = status
-	if status == "SUCCESS" {
-		now := time.Now()
-		payment.PaidAt = &now
+	mid, fallback, err := s.quoteRate(ctx, source, target)
+	if err != nil {
+		return nil, err
 	}
+	midRate := mid.Rate
+	rate := s.customerRate(source, target, midRate)
 
-	// Get associated transaction
-	tx, err := s.repo.GetTransaction(ctx, payment.PaymentID[4:]) // Remove "PAY-" prefix
+	net := func(amount float64) float64 {
+		return domain.NetSourceAmount(amount, s.calculateFees(source, target, amount, promo), s.config.FeeModel, source)
+	}
+	amount, err := sourceAmount(source, rate, net)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction: %w", err)
+		return nil, err
 	}
-
-	// Update transaction status
-	if status == "SUCCESS" {
-		tx.UpdateStatus(domain.StatusPaymentReceived)
-		// Initiate transfer automatically
-		go s.InitiateTransfer(context.Background(), tx.ID)
-	} else if status == "FAILED" {
-		tx.UpdateStatus(domain.StatusFailed)
+	if err := s.validateAmount(amount, source); err != nil {
+		return nil, err
 	}
 
-	// Save updates
-	if err := s.repo.UpdatePayment(ctx, tx.ID, payment); err != nil {
-		return fmt.Errorf("failed to update payment: %w", err)
-	}
+	fees := s.calculateFees(source, target, amount, promo)
+	converted := domain.NetSourceAmount(amount, fees, s.config.FeeModel, source)
+	fees.FXSpread = domain.FXSpread(converted, midRate, rate)
 
-	if err := s.repo.UpdateTransaction(ctx, tx); err != nil {
-		return fmt.Errorf("failed to update transaction: %w", err)
-	}
+	return &domain.Quote{
+		SourceAmount:   amount,
+		SourceCurrency: source,
+		TargetAmount:   converted * rate,
+		TargetCurrency: target,
+		MidMarketRate:  midRate,
+		ExchangeRate:   rate,
+		FallbackRate:   fallback,
+		RateSource:     mid.Provider,
+		RateFetchedAt:  mid.FetchedAt,
+		Fees:           fees,
+		ExpiresAt:      domain.Now().Add(pair.MinRateValidity),
+	}, nil
+}
 
-	return nil
+// GetTransaction retrieves a transaction of any user by ID. For admin
+// tooling only; user requests go through GetUserTransaction.
+func (s *RemittanceService) GetTransaction(ctx context.Context, id string) (*domain.Transaction, error) {
+	return s.repo.GetTransaction(ctx, id)
 }
 
-// GetExchangeRate retrieves current exchange rate from AD Bank
-func (s *RemittanceService) GetExchangeRate(ctx context.Context) (float64, error) {
-	return s.adBankClient.GetExchangeRate(ctx, "INR", "CAD")
+// GetUserTransaction retrieves one of the user's transactions by ID
+func (s *RemittanceService) GetUserTransaction(ctx context.Context, userID, id string) (*domain.Transaction, error) {
+	return s.userTransaction(ctx, userID, id)
 }
 
-// InitiateTransfer starts the cross-border transfer via Wise
-func (s *RemittanceService) InitiateTransfer(ctx context.Context, txID string) error {
-	// Get transaction
-	tx, err := s.repo.GetTransaction(ctx, txID)
+// userTransaction reads a transaction on behalf of a user. Another user's
+// transaction is reported as not found, so its existence is not revealed.
+func (s *RemittanceService) userTransaction(ctx context.Context, userID, id string) (*domain.Transaction, error) {
+	tx, err := s.repo.GetTransaction(ctx, id)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction: %w", err)
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if tx.UserID != userID {
+		return nil, fmt.Errorf("failed to get transaction: %w", repository.ErrNotFound)
 	}
+	return tx, nil
+}
 
-	// Verify transaction is in correct state
-	if tx.Status != domain.StatusPaymentReceived {
-		return ErrInvalidStatus
+// SearchTransactions pages through the transactions, of any user, carrying
+// a reference. For support tooling only.
+func (s *RemittanceService) SearchTransactions(ctx context.Context, reference string, limit int, cursor string) ([]*domain.Transaction, string, error) {
+	return s.repo.ListTransactionsByReference(ctx, reference, limit, cursor)
+}
+
+// MaxStatusBatchSize caps how many transactions one status query may name
+const MaxStatusBatchSize = 50
+
+// GetTransactionStatuses returns the status of each named transaction the
+// user owns. IDs that do not exist or belong to someone else are returned
+// as not found, so the response never reveals other users' transactions.
+func (s *RemittanceService) GetTransactionStatuses(
+	ctx context.Context,
+	userID string,
+	ids []string,
+) (map[string]domain.TransactionStatus, []string, error) {
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxStatusBatchSize {
+		return nil, nil, ErrTooManyIDs
 	}
 
-	// Initiate transfer via Wise
-	transferID, err := s.wiseClient.CreateTransfer(ctx, &integration.WiseTransferRequest{
-		SourceAmount:   tx.SourceAmount,
-		SourceCurrency: tx.SourceCurrency,
-		TargetCurrency: tx.TargetCurrency,
-		RecipientName:  tx.RecipientDetails.Name,
-		BankAccount:    tx.RecipientDetails.BankAccount,
-		BankCode:       tx.RecipientDetails.BankCode,
-	})
+	txns, err := s.repo.BatchGetTransactions(ctx, unique)
 	if err != nil {
-		tx.UpdateStatus(domain.StatusFailed)
-		if err := s.repo.UpdateTransaction(ctx, tx); err != nil {
-			return fmt.Errorf("failed to update transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	statuses := make(map[string]domain.TransactionStatus, len(txns))
+	for _, tx := range txns {
+		if tx.UserID == userID {
+			statuses[tx.ID] = tx.Status
 		}
-		return fmt.Errorf("failed to create transfer: %w", err)
 	}
 
-	// Update transaction status and store transfer ID
-	tx.UpdateStatus(domain.StatusProcessing)
-	tx.TransferID = transferID
-	if err := s.repo.UpdateTransaction(ctx, tx); err != nil {
-		return fmt.Errorf("failed to update transaction: %w", err)
+	notFound := []string{}
+	for _, id := range unique {
+		if _, ok := statuses[id]; !ok {
+			notFound = append(notFound, id)
+		}
 	}
 
-	return nil
+	return statuses, notFound, nil
 }
 
-// HandleTransferCallback processes Wise transfer status callbacks
-func (s *RemittanceService) HandleTransferCallback(ctx context.Context, txID string, status string) error {
-	// Get transaction
-	tx, err := s.repo.GetTransaction(ctx, txID)
+// GetTransactionTimeline returns the chronological history of one of the
+// user's transactions
+func (s *RemittanceService) GetTransactionTimeline(ctx context.Context, userID, id string) ([]domain.TimelineEntry, error) {
+	tx, err := s.userTransaction(ctx, userID, id)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction: %w", err)
+		return nil, err
 	}
 
-	// Update transaction status based on transfer status
-	switch status {
-	case "COMPLETED":
-		tx.UpdateStatus(domain.StatusCompleted)
-	case "FAILED":
-		tx.UpdateStatus(domain.StatusFailed)
-	default:
-		return ErrInvalidStatus
-	}
+	return tx.Timeline(), nil
+}
 
-	// Save updates
-	if err := s.repo.UpdateTransaction(ctx, tx); err != nil {
-		return fmt.Errorf("failed to update transaction: %w", err)
+// GetRateBreakdown returns the mid-market rate, margin and customer rate
+// locked on one of the user's transactions
+func (s *RemittanceService) GetRateBreakdown(ctx context.Context, userID, id string) (*domain.RateBreakdown, error) {
+	tx, err := s.userTransaction(ctx, userID, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return tx.RateBreakdown(), nil
 }
 
-// Helper functions
+// ListUserTransactions retrieves transactions for a user in the given
+// creation time order
+func (s *RemittanceService) ListUserTransactions(
+	ctx context.Context,
+	userID string,
+	limit int,
+	lastKey string,
+	order repository.SortOrder,
+) ([]*domain.Transaction, string, error) {
+	return s.repo.ListTransactionsByUser(ctx, userID, limit, lastKey, order)
+}
 
-func (s *RemittanceService) validateAmount(amount float64) error {
-	if amount < s.config.MinAmount {
-		return ErrInvalidAmount
+// ListRecipientTransactions pages through a user's transactions to a
+// recipient bank account
+func (s *RemittanceService) ListRecipientTransactions(
+	ctx context.Context,
+	userID string,
+	bankAccount string,
+	limit int,
+	lastKey string,
+	order repository.SortOrder,
+) ([]*domain.Transaction, string, error) {
+	txns, next, err := s.repo.ListTransactionsByRecipient(ctx, domain.RecipientHash(userID, bankAccount), limit, lastKey, order)
+	if err != nil {
+		return nil, "", err
 	}
-	if amount > s.config.MaxAmount {
-		return ErrInvalidAmount
+
+	// The hash is scoped to the user; check anyway rather than trust it
+	mine := txns[:0]
+	for _, tx := range txns {
+		if tx.UserID == userID {
+			mine = append(mine, tx)
+		}
 	}
-	return nil
+	return mine, next, nil
 }
 
-func (s *RemittanceService) validateRecipient(recipient *domain.RecipientDetails) error {
-	if recipient == nil {
-		return ErrInvalidRecipient
+// GeneratePaymentLink creates a payment link for one of the user's
+// transactions through the provider of its payment method
+func (s *RemittanceService) GeneratePaymentLink(ctx context.Context, userID, txID string) (*domain.PaymentDetails, error) {
+	// Get transaction
+	tx, err := s.userTransaction(ctx, userID, txID)
+	if err != nil {
+		return nil, err
 	}
-	if recipient.BankAccount == "" || recipient.BankCode == "" || recipient.Name == "" {
-		return ErrInvalidRecipient
+
+	if tx.Status == domain.StatusPendingReview {
+		return nil, ErrPendingReview
+	}
+	if !s.paymentLinkAllowed(tx.Status) {
+		return nil, ErrInvalidStatus
 	}
-	return nil
-}
 
-func (s *RemittanceService) checkDailyLimit(ctx context.Context, userID string, amount float64) error {
-	// Get today's transactions
-	txns, _, err := s.repo.ListTransactionsByUser(ctx, userID, 100, "")
+	// Reuse an existing payment unless its link has expired
+	paymentID := domain.PaymentID(tx.ID)
+	existing, err := s.repo.GetPayment(ctx, paymentID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if existing != nil && existing.Status != domain.PaymentStatusCancelled && !existing.IsExpired(time.Now()) {
+		// A previous attempt may have stored the payment but not the
+		// transaction update; finish it
+		if err := s.attachPayment(ctx, tx, existing); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+
+	// Generate the link with the transaction's payment method
+	method := tx.PaymentMethod
+	if method == "" {
+		method = domain.DefaultPaymentMethod
+	}
+	provider, ok := s.payments[method]
+	if !ok {
+		return nil, ErrUnsupportedPaymentMethod
+	}
+	if err := s.checkPaymentCurrency(method, tx.SourceCurrency); err != nil {
+		return nil, err
+	}
+	paymentLink, err := provider.GeneratePaymentLink(ctx, tx.ID, tx.CollectibleAmount(s.config.FeeModel))
 	if err != nil {
-		return fmt.Errorf("failed to get user transactions: %w", err)
+		return nil, fmt.Errorf("failed to generate payment link: %w", err)
 	}
 
-	// Calculate daily total
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	var dailyTotal float64
-	for _, tx := range txns {
-		if tx.CreatedAt.After(today) && !tx.IsFailed() {
-			dailyTotal += tx.SourceAmount
+	// Create payment record, replacing an expired one in place
+	payment := &domain.PaymentDetails{
+		PaymentID:   paymentID,
+		Method:      method,
+		PaymentLink: paymentLink,
+		Status:      "PENDING",
+	}
+	if method == domain.PaymentMethodUPI {
+		payment.UPIID = s.config.PayeeVPA
+	}
+	if s.config.PaymentLinkValidity > 0 {
+		expiresAt := domain.Now().Add(s.config.PaymentLinkValidity)
+		payment.ExpiresAt = &expiresAt
+	}
+
+	if existing != nil {
+		err = s.repo.UpdatePayment(ctx, tx.ID, payment)
+	} else {
+		err = s.repo.CreatePayment(ctx, tx.ID, payment)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			// A concurrent request created it first; use theirs
+			payment, err = s.repo.GetPayment(ctx, paymentID)
 		}
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment record: %w", err)
+	}
 
-	if dailyTotal+amount > s.config.DailyLimit {
-		return ErrDailyLimitExceeded
+	if err := s.attachPayment(ctx, tx, payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// attachPayment records the payment on the transaction and moves it to
+// PAYMENT_PENDING. It is a no-op when that is already done, so retries after
+// a partial write are safe.
+func (s *RemittanceService) attachPayment(ctx context.Context, tx *domain.Transaction, payment *domain.PaymentDetails) error {
+	if tx.Status == domain.StatusPaymentPending && tx.PaymentDetails != nil &&
+		tx.PaymentDetails.PaymentLink == payment.PaymentLink {
+		return nil
+	}
+
+	// Conditional on the status the link was allowed in, so a transaction
+	// paid or cancelled meanwhile is not moved back to awaiting payment
+	err := s.repo.UpdateTransactionStatus(ctx, tx.ID, tx.Status, domain.StatusPaymentPending,
+		repository.Set("payment_details", payment),
+		repository.Remove("reminder_sent_at"), // a new link earns its own reminder
+	)
+	if errors.Is(err, repository.ErrStatusMismatch) {
+		return ErrInvalidStatus
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
 	}
 
+	tx.UpdateStatus(domain.StatusPaymentPending)
+	tx.SetPaymentDetails(payment)
+	tx.ReminderSentAt = nil
 	return nil
 }
 
-func (s *RemittanceService) calculateFees(amount float64) *domain.Fees {
-	variableFee := amount * s.config.VariableFee
-	if variableFee < 50 {
-		variableFee = 50
+// CancelPayment voids the payment link of one of the user's transactions
+// awaiting payment and returns it to INITIATED, so a fresh link can be
+// generated. It is refused once the payment has been received.
+func (s *RemittanceService) CancelPayment(ctx context.Context, userID, txID string) (*domain.Transaction, error) {
+	tx, err := s.userTransaction(ctx, userID, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.PaymentDetails != nil && tx.PaymentDetails.PaidAt != nil {
+		return nil, ErrPaymentAlreadyReceived
 	}
-	if variableFee > 5000 {
-		variableFee = 5000
+	if tx.Status != domain.StatusPaymentPending || tx.PaymentDetails == nil {
+		return nil, ErrInvalidStatus
 	}
 
-	return &domain.Fees{
-		BaseFee:     s.config.BaseFee,
-		VariableFee: variableFee,
-		TotalFee:    s.config.BaseFee + variableFee,
+	payment := *tx.PaymentDetails
+	payment.Status = domain.PaymentStatusCancelled
+
+	// Conditional on the status, so a payment arriving meanwhile wins
+	err = s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusPaymentPending, domain.StatusInitiated,
+		repository.Set("payment_details", &payment))
+	if errors.Is(err, repository.ErrStatusMismatch) {
+		return nil, ErrPaymentAlreadyReceived
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	if err := s.repo.UpdatePayment(ctx, tx.ID, &payment); err != nil {
+		return nil, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	tx.UpdateStatus(domain.StatusInitiated)
+	tx.SetPaymentDetails(&payment)
+	return tx, nil
+}
+
+// HandlePaymentCallback processes UPI payment callbacks. A callback only
+// applies to a transaction awaiting payment, or returned to INITIATED by a
+// cancelled link, and moves it with a conditional status update. Repeated
+// and late callbacks for a transaction that has moved on are ignored, so a
+// payment can never start a second transfer.
+func (s *RemittanceService) HandlePaymentCallback(ctx context.Context, cb *PaymentCallback) error {
+	if !cb.Status.Valid() {
+		return ErrUnknownPaymentStatus
+	}
+
+	// Get payment details
+	payment, err := s.repo.GetPayment(ctx, cb.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// The sender voided the link. Money taken through it anyway is held for
+	// manual handling; anything else no longer matters.
+	cancelled := payment.Status == domain.PaymentStatusCancelled
+	if cancelled && cb.Status != PaymentCallbackSuccess {
+		log.Printf("ignoring payment callback for cancelled payment: payment_id=%s status=%s", payment.PaymentID, cb.Status)
+		return nil
+	}
+
+	// Get associated transaction
+	txID, ok := domain.TransactionIDFromPaymentID(payment.PaymentID)
+	if !ok {
+		return fmt.Errorf("payment %s has no transaction", payment.PaymentID)
+	}
+	tx, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	from := domain.StatusPaymentPending
+	if cancelled {
+		from = domain.StatusInitiated
+	}
+	if tx.Status != from {
+		log.Printf("ignoring payment callback: payment_id=%s transaction_id=%s status=%s callback_status=%s",
+			payment.PaymentID, tx.ID, tx.Status, cb.Status)
+		return nil
+	}
+
+	// Update payment status
+	payment.Status = string(cb.Status)
+	if cb.Status == PaymentCallbackSuccess {
+		now := domain.Now()
+		payment.PaidAt = &now
+		payment.PaidAmount = tx.CollectibleAmount(s.config.FeeModel)
+		if cb.PaidAmount != nil {
+			payment.PaidAmount = *cb.PaidAmount
+		}
+		payment.Currency = tx.SourceCurrency
+	}
+
+	// Work out the transaction's new status
+	to := from
+	fields := []repository.Field{repository.Set("payment_details", payment)}
+	var startTransfer bool
+	switch cb.Status {
+	case PaymentCallbackSuccess:
+		if cancelled || cb.PaidAmount != nil && !s.paidAmountMatches(*cb.PaidAmount, tx.CollectibleAmount(s.config.FeeModel)) {
+			// Hold for manual handling rather than transferring the wrong
+			// amount or money paid through a voided link
+			to = domain.StatusPaymentMismatch
+		} else {
+			to = domain.StatusPaymentReceived
+			startTransfer = true
+		}
+	case PaymentCallbackFailed:
+		to = domain.StatusFailed
+		fields = append(fields, repository.Set("failure_reason", domain.FailureReasonPaymentFailed))
+	}
+
+	// Save updates
+	if err := s.repo.UpdatePayment(ctx, tx.ID, payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	err = s.repo.UpdateTransactionStatus(ctx, tx.ID, from, to, fields...)
+	if errors.Is(err, repository.ErrStatusMismatch) {
+		log.Printf("ignoring payment callback, transaction moved meanwhile: payment_id=%s transaction_id=%s callback_status=%s",
+			payment.PaymentID, tx.ID, cb.Status)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	// Initiate transfer automatically once the received payment is saved
+	if startTransfer {
+		txID := tx.ID
+		payload := map[string]string{"transaction_id": txID}
+		s.runInBackground(ctx, taskTransfer, txID, payload, func(ctx context.Context) error {
+			return s.InitiateTransfer(ctx, txID)
+		})
+	}
+
+	return nil
+}
+
+// GetLimits returns the amount limits, the user's remaining daily allowance
+// and the fee schedule
+func (s *RemittanceService) GetLimits(ctx context.Context, userID string) (*domain.Limits, error) {
+	used, err := s.usedToday(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	fees := s.feesFor(s.defaultPair())
+
+	return &domain.Limits{
+		Currency:       "INR",
+		MinAmount:      s.config.MinAmount,
+		MaxAmount:      s.config.MaxAmount,
+		DailyLimit:     s.config.DailyLimit,
+		UsedToday:      used,
+		RemainingToday: max(s.config.DailyLimit-used, 0),
+		Fees: domain.FeeSchedule{
+			BaseFee:        fees.base,
+			VariableRate:   fees.rate,
+			VariableFeeMin: minVariableFee,
+			VariableFeeMax: maxVariableFee,
+			Tiers:          fees.displayTiers(),
+		},
+	}, nil
+}
+
+// GetExchangeRate retrieves the current exchange rate for a pair from AD
+// Bank. An empty source and target select the default pair; any other pair
+// must be configured and enabled.
+func (s *RemittanceService) GetExchangeRate(ctx context.Context, source, target string) (*domain.ExchangeRate, error) {
+	if source == "" && target == "" {
+		source, target = s.defaultPair()
+	}
+
+	pair, err := s.currencyPair(source, target)
+	if err != nil || !pair.Enabled {
+		return nil, ErrInvalidCurrency
+	}
+
+	return s.fetchRate(ctx, source, target)
+}
+
+// defaultPair returns the source and target currencies of the default pair,
+// falling back to the first enabled pair the same way
+// config.DefaultCurrencyPair does
+func (s *RemittanceService) defaultPair() (string, string) {
+	if source, target, ok := strings.Cut(s.config.DefaultPair, "/"); ok {
+		return source, target
+	}
+	for _, pair := range s.config.CurrencyPairs {
+		if pair.Enabled {
+			return pair.Source, pair.Target
+		}
+	}
+	return "INR", "CAD"
+}
+
+// paymentLinkAllowed reports whether a payment link may be generated for a
+// transaction in the given status
+func (s *RemittanceService) paymentLinkAllowed(status domain.TransactionStatus) bool {
+	allowed := s.config.PaymentLinkStatuses
+	if len(allowed) == 0 {
+		allowed = defaultPaymentLinkStatuses
+	}
+	for _, st := range allowed {
+		if st == status {
+			return true
+		}
+	}
+	return false
+}
+
+// rateProviderADBank names AD Bank as the source of a rate
+const rateProviderADBank = "ad_bank"
+
+// fetchRate gets the current rate from AD Bank and remembers it as the
+// last-known rate for the pair
+func (s *RemittanceService) fetchRate(ctx context.Context, source, target string) (*domain.ExchangeRate, error) {
+	value, err := s.adBankClient.GetExchangeRate(ctx, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := domain.NewExchangeRate(rateProviderADBank, source, target, value)
+	if err := s.repo.SaveRate(ctx, rate); err != nil {
+		log.Printf("failed to save last-known rate for %s/%s: %v", source, target, err)
+	}
+
+	return rate, nil
+}
+
+// quoteRate returns the rate to lock on a new transaction. When AD Bank is
+// unavailable, the last-known rate is used if it is within the pair's
+// fallback age; the second return value reports that it was.
+func (s *RemittanceService) quoteRate(ctx context.Context, source, target string) (*domain.ExchangeRate, bool, error) {
+	rate, err := s.fetchRate(ctx, source, target)
+	if err == nil {
+		return rate, false, nil
+	}
+	fetchErr := fmt.Errorf("failed to get exchange rate: %w", err)
+
+	pair, perr := s.currencyPair(source, target)
+	if perr != nil || pair.MaxFallbackRateAge <= 0 {
+		return nil, false, fetchErr
+	}
+
+	last, lerr := s.repo.GetLastRate(ctx, source, target)
+	if lerr != nil || last.Age(time.Now()) > pair.MaxFallbackRateAge {
+		return nil, false, fetchErr
+	}
+
+	log.Printf("rate provider unavailable, using last-known %s/%s rate from %s: %v",
+		source, target, last.FetchedAt.Format(time.RFC3339), err)
+	return last, true, nil
+}
+
+// InitiateTransfer starts the cross-border transfer via Wise
+func (s *RemittanceService) InitiateTransfer(ctx context.Context, txID string) error {
+	// Get transaction
+	tx, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	// Verify transaction is in correct state
+	if tx.Status != domain.StatusPaymentReceived {
+		return ErrInvalidStatus
+	}
+
+	// Re-quote a rate that has aged past the corridor limit. A rate that
+	// moved too far fails the transaction for a refund.
+	requoted, err := s.refreshStaleRate(ctx, tx)
+	if err != nil {
+		if errors.Is(err, ErrRateStale) {
+			if ferr := s.failTransaction(ctx, tx, domain.FailureReasonRateStale); ferr != nil {
+				return ferr
+			}
+		}
+		return err
+	}
+
+	// Fail a transfer Wise is bound to reject without calling it
+	if err := s.checkTransferLimits(tx); err != nil {
+		if ferr := s.failTransaction(ctx, tx, err.Error()); ferr != nil {
+			return ferr
+		}
+		return err
+	}
+
+	// Claim the transaction before calling Wise, storing any new rate with
+	// it. A process that dies during the call leaves it PROCESSING without a
+	// transfer ID, for the reaper.
+	startedAt := domain.Now()
+	fields := []repository.Field{repository.Set("processing_started_at", startedAt)}
+	if requoted {
+		fields = append(fields, rateFields(tx)...)
+	}
+	err = s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusPaymentReceived, domain.StatusProcessing, fields...)
+	if err != nil {
+		if errors.Is(err, repository.ErrStatusMismatch) {
+			return ErrInvalidStatus
+		}
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+	tx.UpdateStatus(domain.StatusProcessing)
+	tx.ProcessingStartedAt = &startedAt
+
+	// Initiate transfer via Wise
+	transferID, err := s.createTransfer(ctx, &integration.WiseTransferRequest{
+		SourceAmount:   tx.NetAmount(s.config.FeeModel),
+		SourceCurrency: tx.SourceCurrency,
+		TargetCurrency: tx.TargetCurrency,
+		RecipientName:  s.transferRecipientName(tx),
+		BankAccount:    tx.RecipientDetails.BankAccount,
+		BankCode:       tx.RecipientDetails.BankCode,
+
+		CustomerReference:     tx.ID,
+		CustomerTransactionID: tx.ID,
+	})
+	if err != nil {
+		if ferr := s.failTransaction(ctx, tx, integration.FailureReason(err)); ferr != nil {
+			return ferr
+		}
+		return fmt.Errorf("failed to create transfer: %w", err)
+	}
+
+	// Store the transfer ID, and the Latin recipient name it was sent with
+	err = s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusProcessing, domain.StatusProcessing,
+		repository.SetIfAbsent("transfer_id", transferID),
+		repository.Set("recipient_details", tx.RecipientDetails),
+	)
+	if err != nil {
+		// The transfer exists at Wise; whoever moved the transaction needs
+		// to know its ID
+		log.Printf("failed to record transfer: transaction_id=%s transfer_id=%s error=%v", tx.ID, transferID, err)
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+	tx.TransferID = transferID
+
+	return nil
+}
+
+// failTransaction moves a transaction from the status it was read in to
+// FAILED, recording why. It reports ErrInvalidStatus when the transaction
+// moved meanwhile.
+func (s *RemittanceService) failTransaction(ctx context.Context, tx *domain.Transaction, reason string) error {
+	err := s.repo.UpdateTransactionStatus(ctx, tx.ID, tx.Status, domain.StatusFailed,
+		repository.Set("failure_reason", reason))
+	if errors.Is(err, repository.ErrStatusMismatch) {
+		return ErrInvalidStatus
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	tx.Fail(reason)
+	return nil
+}
+
+// createTransfer calls Wise, retrying retryable failures with exponential
+// backoff and full jitter up to the configured attempt limit. Terminal
+// failures return at once.
+func (s *RemittanceService) createTransfer(ctx context.Context, req *integration.WiseTransferRequest) (string, error) {
+	retry := s.config.TransferRetry
+	attempts := max(retry.MaxAttempts, 1)
+	interval := retry.InitialInterval
+
+	for attempt := 1; ; attempt++ {
+		transferID, err := s.callCreateTransfer(ctx, req)
+		if err == nil {
+			return transferID, nil
+		}
+		if attempt >= attempts || !integration.IsRetryable(err) {
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(backoff(interval, retry.DisableJitter)):
+		}
+
+		interval *= 2
+		if retry.MaxInterval > 0 && interval > retry.MaxInterval {
+			interval = retry.MaxInterval
+		}
+	}
+}
+
+// backoff returns how long to wait before a retry: a random duration
+// between zero and interval ("full jitter"), or interval itself when jitter
+// is disabled
+func backoff(interval time.Duration, disableJitter bool) time.Duration {
+	if disableJitter || interval <= 0 {
+		return interval
+	}
+	return rand.N(interval + 1)
+}
+
+// callCreateTransfer makes one Wise CreateTransfer call once a transfer slot
+// is free, waiting for one until ctx is done
+func (s *RemittanceService) callCreateTransfer(ctx context.Context, req *integration.WiseTransferRequest) (string, error) {
+	if s.transferSlots != nil {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case s.transferSlots <- struct{}{}:
+		}
+		defer func() { <-s.transferSlots }()
+	}
+
+	metrics.InFlightTransfers.Inc()
+	defer metrics.InFlightTransfers.Dec()
+
+	return s.wiseClient.CreateTransfer(ctx, req)
+}
+
+// HandleTransferCallback processes Wise transfer status callbacks
+func (s *RemittanceService) HandleTransferCallback(ctx context.Context, cb *TransferCallback) error {
+	status := cb.Status
+
+	// Get transaction
+	tx, err := s.repo.GetTransaction(ctx, cb.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	// Update transaction status based on transfer status, writing only the
+	// changed attributes and only if no one else has moved it meanwhile
+	var to domain.TransactionStatus
+	var fields []repository.Field
+	switch status {
+	case TransferCallbackCompleted:
+		to = domain.StatusCompleted
+		if cb.DeliveredAmount != nil {
+			fields = append(fields, s.settlementFields(tx, *cb.DeliveredAmount)...)
+		}
+	case TransferCallbackFailed:
+		to = domain.StatusFailed
+		fields = append(fields, repository.Set("failure_reason", domain.FailureReasonTransferFailed))
+	case TransferCallbackPending, TransferCallbackProcessing:
+		return nil // progress update, nothing to record
+	default:
+		return ErrUnknownTransferStatus
+	}
+
+	// Callbacks can arrive late, repeated or out of order. A finished
+	// transaction never moves again; only a transfer in progress does.
+	if tx.IsTerminal() {
+		if tx.Status != to {
+			log.Printf("ignoring transfer callback for finished transaction: transaction_id=%s status=%s callback_status=%s", tx.ID, tx.Status, status)
+		}
+		return nil
+	}
+	if tx.Status != domain.StatusProcessing {
+		return ErrInvalidStatus
+	}
+
+	err = s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusProcessing, to, fields...)
+	if errors.Is(err, repository.ErrStatusMismatch) {
+		return nil // a concurrent callback or the poller got there first
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	if to == domain.StatusCompleted {
+		recordRevenue(tx, s.config.FeeModel)
+	}
+	return nil
+}
+
+// recordRevenue adds a completed transaction's fees and exchange rate
+// margin to the revenue metrics. The margin is the difference between the
+// mid-market and customer rates on the amount converted under model.
+func recordRevenue(tx *domain.Transaction, model domain.FeeModel) {
+	pair := domain.RatePair(tx.SourceCurrency, tx.TargetCurrency)
+	if tx.Fees != nil && tx.Fees.TotalFee > 0 {
+		metrics.FeesCollected.WithLabelValues(pair).Add(tx.Fees.TotalFee)
+	}
+	if tx.MidMarketRate > 0 {
+		if margin := domain.FXSpread(tx.NetAmount(model), tx.MidMarketRate, tx.ExchangeRate); margin > 0 {
+			metrics.FXMarginEarned.WithLabelValues(pair).Add(margin)
+		}
+	}
+}
+
+// settlementFields records the amount Wise delivered and its variance from
+// the quoted target amount. A variance beyond the settlement tolerance flags
+// the transaction for review; smaller ones, from FX timing, are accepted.
+func (s *RemittanceService) settlementFields(tx *domain.Transaction, delivered float64) []repository.Field {
+	variance := domain.RoundAmount(delivered-tx.TargetAmount, tx.TargetCurrency)
+	fields := []repository.Field{
+		repository.Set("delivered_amount", delivered),
+		repository.Set("settlement_variance", variance),
+	}
+
+	if !s.withinSettlementTolerance(variance, tx.TargetAmount) {
+		log.Printf("settlement variance beyond tolerance: transaction_id=%s expected=%.2f delivered=%.2f variance=%.2f",
+			tx.ID, tx.TargetAmount, delivered, variance)
+		fields = append(fields, repository.Set("settlement_review", true))
+	}
+	return fields
+}
+
+// withinSettlementTolerance reports whether a delivered amount's variance is
+// within the larger of the absolute and percentage tolerances. With neither
+// configured every variance is accepted.
+func (s *RemittanceService) withinSettlementTolerance(variance, expected float64) bool {
+	tolerance := s.config.SettlementTolerance
+	if tolerance.Amount <= 0 && tolerance.Percent <= 0 {
+		return true
+	}
+	return math.Abs(variance) <= max(tolerance.Amount, expected*tolerance.Percent)
+}
+
+// ApproveTransaction releases a transaction held for review so it can be paid
+func (s *RemittanceService) ApproveTransaction(ctx context.Context, txID string) (*domain.Transaction, error) {
+	return s.resolveReview(ctx, txID, domain.StatusInitiated)
+}
+
+// RejectTransaction fails a transaction held for review
+func (s *RemittanceService) RejectTransaction(ctx context.Context, txID string) (*domain.Transaction, error) {
+	return s.resolveReview(ctx, txID, domain.StatusFailed)
+}
+
+func (s *RemittanceService) resolveReview(ctx context.Context, txID string, status domain.TransactionStatus) (*domain.Transaction, error) {
+	tx, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if tx.Status != domain.StatusPendingReview {
+		return nil, ErrInvalidStatus
+	}
+
+	if status == domain.StatusFailed {
+		if err := s.failTransaction(ctx, tx, domain.FailureReasonReviewRejected); err != nil {
+			return nil, err
+		}
+		return tx, nil
+	}
+
+	err = s.repo.UpdateTransactionStatus(ctx, tx.ID, domain.StatusPendingReview, status)
+	if errors.Is(err, repository.ErrStatusMismatch) {
+		return nil, ErrInvalidStatus
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+	tx.UpdateStatus(status)
+
+	return tx, nil
+}
+
+// EstimateDelivery returns the expected delivery window for one of the
+// user's transactions
+func (s *RemittanceService) EstimateDelivery(ctx context.Context, userID, txID string) (*domain.DeliveryEstimate, error) {
+	tx, err := s.userTransaction(ctx, userID, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := s.currencyPair(tx.SourceCurrency, tx.TargetCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.EstimateDelivery(deliveryWindow(pair), domain.Now()), nil
+}
+
+// EstimateQuoteDelivery returns the expected delivery window for a new
+// transaction on the given corridor if it were paid now
+func (s *RemittanceService) EstimateQuoteDelivery(ctx context.Context, sourceCurrency, targetCurrency string) (*domain.DeliveryEstimate, error) {
+	pair, err := s.currencyPair(sourceCurrency, targetCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	now := domain.Now()
+	return deliveryWindow(pair).Estimate(now, now), nil
+}
+
+// Helper functions
+
+// currencyPair returns a copy of the corridor's settings, with Enabled
+// reflecting any runtime pause
+func (s *RemittanceService) currencyPair(source, target string) (*config.CurrencyPairConfig, error) {
+	for _, pair := range s.config.CurrencyPairs {
+		if pair.Source == source && pair.Target == target {
+			pair.Enabled = s.pairs.isEnabled(pair.Key())
+			return &pair, nil
+		}
+	}
+	return nil, ErrInvalidCurrency
+}
+
+// RecalculateTransaction re-quotes an unpaid transaction at the current rate,
+// recomputing its fees and target amount together. The update only applies
+// if the transaction is still in the status it was read in, so a payment
+// arriving meanwhile is never repriced.
+func (s *RemittanceService) RecalculateTransaction(ctx context.Context, txID string) (*domain.Transaction, error) {
+	tx, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	switch tx.Status {
+	case domain.StatusInitiated, domain.StatusPendingReview, domain.StatusPaymentPending:
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	mid, err := s.fetchRate(ctx, tx.SourceCurrency, tx.TargetCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	s.requote(tx, mid)
+
+	err = s.repo.UpdateTransactionStatus(ctx, tx.ID, tx.Status, tx.Status, rateFields(tx)...)
+	if err != nil {
+		if errors.Is(err, repository.ErrStatusMismatch) {
+			return nil, ErrInvalidStatus
+		}
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+// requote prices tx at the mid-market rate mid, recomputing its fees and
+// target amount together
+func (s *RemittanceService) requote(tx *domain.Transaction, mid *domain.ExchangeRate) {
+	// Keep a promo code already granted, even if it has expired since
+	var promo *config.PromoCodeConfig
+	if tx.Fees != nil {
+		promo = s.findPromoCode(tx.Fees.PromoCode)
+	}
+	tx.SetFees(s.calculateFees(tx.SourceCurrency, tx.TargetCurrency, tx.SourceAmount, promo))
+	tx.SetRates(mid.Rate, s.customerRate(tx.SourceCurrency, tx.TargetCurrency, mid.Rate), s.margin(tx.SourceCurrency, tx.TargetCurrency), s.config.FeeModel)
+	tx.SetRateSource(mid)
+	tx.FallbackRate = false
+}
+
+// rateFields are the attributes a re-quote changes
+func rateFields(tx *domain.Transaction) []repository.Field {
+	return []repository.Field{
+		repository.Set("exchange_rate", tx.ExchangeRate),
+		repository.Set("mid_market_rate", tx.MidMarketRate),
+		repository.Set("margin", tx.Margin),
+		repository.Set("target_amount", tx.TargetAmount),
+		repository.Set("rate_locked_at", tx.RateLockedAt),
+		repository.Set("fees", tx.Fees),
+		repository.Set("fallback_rate", tx.FallbackRate),
+		repository.Set("rate_source", tx.RateSource),
+		repository.Set("rate_fetched_at", tx.RateFetchedAt),
+	}
+}
+
+// refreshStaleRate re-quotes the transaction at the current rate when its
+// locked rate is older than the corridor allows, reporting whether it did.
+// A rate that has moved beyond the tolerance is refused rather than absorbed
+// as a loss.
+func (s *RemittanceService) refreshStaleRate(ctx context.Context, tx *domain.Transaction) (bool, error) {
+	pair, err := s.currencyPair(tx.SourceCurrency, tx.TargetCurrency)
+	if err != nil || pair.MaxRateAgeForTransfer <= 0 {
+		return false, nil
+	}
+	if tx.RateAge(time.Now()) <= pair.MaxRateAgeForTransfer {
+		return false, nil
+	}
+
+	mid, err := s.fetchRate(ctx, tx.SourceCurrency, tx.TargetCurrency)
+	if err != nil {
+		return false, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	rate := s.customerRate(tx.SourceCurrency, tx.TargetCurrency, mid.Rate)
+	if math.Abs(rate-tx.ExchangeRate) > tx.ExchangeRate*pair.RequoteTolerance {
+		return false, ErrRateStale
+	}
+
+	s.requote(tx, mid)
+	return true, nil
+}
+
+// customerRate applies the pair's margin to the mid-market rate
+func (s *RemittanceService) customerRate(source, target string, midRate float64) float64 {
+	return midRate * (1 - s.margin(source, target))
+}
+
+// checkPaymentCurrency refuses a payment method that cannot collect the
+// source currency, e.g. UPI for anything but INR
+func (s *RemittanceService) checkPaymentCurrency(method domain.PaymentMethod, currency string) error {
+	allowed, ok := s.config.PaymentCurrencies[method]
+	if !ok || slices.Contains(allowed, currency) {
+		return nil
+	}
+	return &ValidationError{
+		Err:     ErrMethodCurrencyMismatch,
+		Message: fmt.Sprintf("%s payments cannot be made in %s", method, currency),
+	}
+}
+
+// margin returns the pair's margin, zero for an unknown pair
+func (s *RemittanceService) margin(source, target string) float64 {
+	pair, err := s.currencyPair(source, target)
+	if err != nil {
+		return 0
+	}
+	return pair.Margin
+}
+
+func deliveryWindow(pair *config.CurrencyPairConfig) domain.DeliveryWindow {
+	return domain.DeliveryWindow{
+		Min: pair.DeliveryWindow.Min,
+		Max: pair.DeliveryWindow.Max,
+	}
+}
+
+func (s *RemittanceService) validateAmount(amount float64, currency string) error {
+	if amount < s.config.MinAmount {
+		return ErrInvalidAmount
+	}
+	if amount > s.config.MaxAmount {
+		return ErrInvalidAmount
+	}
+
+	// Reject more decimal places than the currency can carry
+	precision, ok := s.config.AmountPrecision[currency]
+	if !ok {
+		precision = domain.CurrencyPrecision(currency)
+	}
+	scaled := amount * math.Pow10(precision)
+	if math.Abs(scaled-math.Round(scaled)) > 1e-6 {
+		return &ValidationError{
+			Err:     ErrInvalidAmount,
+			Message: fmt.Sprintf("%s amounts allow at most %d decimal places", currency, precision),
+		}
+	}
+
+	return nil
+}
+
+func (s *RemittanceService) validateRecipient(ctx context.Context, recipient *domain.RecipientDetails, source, target string) error {
+	if recipient == nil {
+		return ErrInvalidRecipient
+	}
+	if recipient.BankAccount == "" || recipient.BankCode == "" || recipient.Name == "" {
+		return ErrInvalidRecipient
+	}
+
+	blocked, err := s.compliance.IsBlocked(ctx, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to screen recipient: %w", err)
+	}
+	if blocked {
+		return ErrRecipientBlocked
+	}
+
+	// Screened first so a blocked recipient costs no provider call
+	account, err := s.adBankClient.ValidateAccount(ctx, recipient.BankCode, recipient.BankAccount)
+	if err != nil {
+		return fmt.Errorf("failed to validate recipient account: %w", err)
+	}
+	if !account.Valid {
+		return ErrInvalidRecipient
+	}
+
+	// The bank's word on the account currency beats the sender's
+	if account.Currency != "" {
+		recipient.AccountCurrency = account.Currency
+	}
+	if pair, err := s.currencyPair(source, target); err == nil && pair.EnforceRecipientCurrency &&
+		recipient.AccountCurrency != "" && recipient.AccountCurrency != target {
+		return ErrRecipientCurrencyMismatch
+	}
+
+	return nil
+}
+
+// forEachUserTransaction pages through the user's transactions, newest
+// first, calling fn for each until it returns false
+func (s *RemittanceService) forEachUserTransaction(ctx context.Context, userID string, fn func(tx *domain.Transaction) bool) error {
+	var cursor string
+	for {
+		txns, next, err := s.repo.ListTransactionsByUser(ctx, userID, reportPageSize, cursor, repository.SortDescending)
+		if err != nil {
+			return fmt.Errorf("failed to get user transactions: %w", err)
+		}
+
+		for _, tx := range txns {
+			if !fn(tx) {
+				return nil
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// lookupBankName returns the name of the recipient's bank for display. A
+// failed lookup is logged and leaves the name empty; the bank code is enough
+// to send the transfer.
+func (s *RemittanceService) lookupBankName(ctx context.Context, bankCode string) string {
+	name, err := s.adBankClient.LookupBank(ctx, bankCode)
+	if err != nil {
+		log.Printf("bank lookup failed: bank_code=%s error=%v", bankCode, err)
+		return ""
+	}
+	return name
+}
+
+// paidAmountMatches checks a reported payment against the expected amount
+// within the configured tolerance
+func (s *RemittanceService) paidAmountMatches(paid, expected float64) bool {
+	return math.Abs(paid-expected) <= s.config.PaymentAmountTolerance
+}
+
+// transferRecipientName returns the recipient name to send Wise, recording a
+// Latin spelling on the transaction when the corridor asks for one
+func (s *RemittanceService) transferRecipientName(tx *domain.Transaction) string {
+	recipient := tx.RecipientDetails
+	pair, err := s.currencyPair(tx.SourceCurrency, tx.TargetCurrency)
+	if err != nil || !pair.TransliterateNames || translit.IsLatin(recipient.Name) {
+		return recipient.Name
+	}
+
+	recipient.NameTransliterated = translit.ToLatin(recipient.Name)
+	return recipient.NameTransliterated
+}
+
+// checkTransferLimits checks Wise supports the corridor and the amount left
+// after fees is within the corridor's Wise transfer limits
+func (s *RemittanceService) checkTransferLimits(tx *domain.Transaction) error {
+	pair, err := s.currencyPair(tx.SourceCurrency, tx.TargetCurrency)
+	if err != nil {
+		return err
+	}
+	if len(s.config.WiseCorridors) > 0 && !slices.Contains(s.config.WiseCorridors, pair.Key()) {
+		return ErrCorridorNotSupported
+	}
+
+	net := tx.NetAmount(s.config.FeeModel)
+	if pair.MinTransfer > 0 && net < pair.MinTransfer {
+		return ErrBelowCorridorMinimum
+	}
+	if pair.MaxTransfer > 0 && net > pair.MaxTransfer {
+		return ErrAboveCorridorMaximum
+	}
+	return nil
+}
+
+func (s *RemittanceService) checkCorridor(tier, source, target string) error {
+	pair, err := s.currencyPair(source, target)
+	if err != nil {
+		return err
+	}
+	if !pair.Enabled {
+		return ErrCorridorDisabled
+	}
+
+	tierConfig, ok := s.config.Tiers[tier]
+	if !ok {
+		return nil
+	}
+	for _, key := range tierConfig.AllowedPairs {
+		if key == pair.Key() {
+			return nil
+		}
+	}
+
+	return ErrCorridorNotAllowed
+}
+
+func (s *RemittanceService) checkDailyLimit(ctx context.Context, userID string, amount float64) error {
+	dailyTotal, err := s.usedToday(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if dailyTotal+amount > s.config.DailyLimit {
+		return ErrDailyLimitExceeded
+	}
+
+	return nil
+}
+
+// usedToday sums the user's non-failed transactions created since midnight UTC
+func (s *RemittanceService) usedToday(ctx context.Context, userID string) (float64, error) {
+	// Sum today's transactions, stopping at the first from an earlier day
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var dailyTotal float64
+	err := s.forEachUserTransaction(ctx, userID, func(tx *domain.Transaction) bool {
+		if !tx.CreatedAt.After(today) {
+			return false
+		}
+		if !tx.IsFailed() {
+			dailyTotal += tx.SourceAmount
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return dailyTotal, nil
+}
+
+func (s *RemittanceService) checkOpenTransactions(ctx context.Context, userID string) error {
+	if s.config.MaxOpenTransactions <= 0 {
+		return nil
+	}
+
+	var open int
+	err := s.forEachUserTransaction(ctx, userID, func(tx *domain.Transaction) bool {
+		if !tx.IsTerminal() {
+			open++
+		}
+		return open < s.config.MaxOpenTransactions
+	})
+	if err != nil {
+		return err
+	}
+
+	if open >= s.config.MaxOpenTransactions {
+		return ErrTooManyOpenTransactions
+	}
+
+	return nil
+}
+
+func (s *RemittanceService) resolvePromoCode(ctx context.Context, userID, code string) (*config.PromoCodeConfig, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	promo := s.findPromoCode(code)
+	if promo == nil {
+		return nil, ErrInvalidPromoCode
+	}
+
+	if !promo.ExpiresAt.IsZero() && time.Now().After(promo.ExpiresAt) {
+		return nil, ErrPromoCodeExpired
+	}
+
+	if promo.MaxUsesPerUser > 0 {
+		var uses int
+		err := s.forEachUserTransaction(ctx, userID, func(tx *domain.Transaction) bool {
+			if tx.Fees != nil && strings.EqualFold(tx.Fees.PromoCode, promo.Code) && !tx.IsFailed() {
+				uses++
+			}
+			return uses < promo.MaxUsesPerUser
+		})
+		if err != nil {
+			return nil, err
+		}
+		if uses >= promo.MaxUsesPerUser {
+			return nil, ErrPromoCodeUsageExceeded
+		}
+	}
+
+	return promo, nil
+}
+
+// findPromoCode looks up a promo code case-insensitively, or returns nil
+func (s *RemittanceService) findPromoCode(code string) *config.PromoCodeConfig {
+	if code == "" {
+		return nil
+	}
+	for i := range s.config.PromoCodes {
+		if strings.EqualFold(s.config.PromoCodes[i].Code, code) {
+			return &s.config.PromoCodes[i]
+		}
+	}
+	return nil
+}
+
+// feeSchedule is the base fee and variable fee applied to a corridor
+type feeSchedule struct {
+	base  float64
+	rate  float64
+	tiers []config.FeeTierConfig // replace rate when set
+}
+
+// feesFor returns the corridor's own fee schedule when it has one, otherwise
+// the global one
+func (s *RemittanceService) feesFor(source, target string) feeSchedule {
+	if pair, err := s.currencyPair(source, target); err == nil && pair.Fees != nil {
+		return feeSchedule{base: pair.Fees.Base.Amount, rate: pair.Fees.Percentage.Rate, tiers: pair.Fees.Tiers}
+	}
+	return feeSchedule{base: s.config.BaseFee, rate: s.config.VariableFee, tiers: s.config.FeeTiers}
+}
+
+// calculateFees prices amount on the source/target corridor's fee schedule
+func (s *RemittanceService) calculateFees(source, target string, amount float64, promo *config.PromoCodeConfig) *domain.Fees {
+	schedule := s.feesFor(source, target)
+	variableFee := schedule.variableFee(amount)
+
+	fees := &domain.Fees{
+		BaseFee:     schedule.base,
+		VariableFee: variableFee,
+		TotalFee:    schedule.base + variableFee,
+	}
+
+	if promo != nil {
+		fees.PromoCode = promo.Code
+		fees.Discount = fees.TotalFee * promo.Discount
+		fees.TotalFee -= fees.Discount
+	}
+
+	return fees
+}
+
+// variableFee returns the amount-dependent fee: from the tier covering the
+// amount when tiers are configured, otherwise the single rate clamped to
+// the fee bounds
+func (f feeSchedule) variableFee(amount float64) float64 {
+	if tiers := f.tiers; len(tiers) > 0 {
+		for _, tier := range tiers {
+			if tier.UpTo == 0 || amount <= tier.UpTo {
+				return tier.Flat + amount*tier.Rate
+			}
+		}
+		// Amounts above a bounded last tier pay its rate
+		last := tiers[len(tiers)-1]
+		return last.Flat + amount*last.Rate
+	}
+
+	variableFee := amount * f.rate
+	if variableFee < minVariableFee {
+		variableFee = minVariableFee
+	}
+	if variableFee > maxVariableFee {
+		variableFee = maxVariableFee
+	}
+	return variableFee
+}
+
+// displayTiers returns the fee tiers for display
+func (f feeSchedule) displayTiers() []domain.FeeTier {
+	if len(f.tiers) == 0 {
+		return nil
+	}
+	tiers := make([]domain.FeeTier, 0, len(f.tiers))
+	for _, tier := range f.tiers {
+		tiers = append(tiers, domain.FeeTier{UpTo: tier.UpTo, Rate: tier.Rate, Flat: tier.Flat})
 	}
+	return tiers
 }
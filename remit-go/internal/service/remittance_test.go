@@ -0,0 +1,2547 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/remit-demo/remit-go/internal/config"
+	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/integration"
+	"github.com/remit-demo/remit-go/internal/metrics"
+	"github.com/remit-demo/remit-go/internal/repository"
+)
+
+func TestUserHistoryChecksReadEveryPage(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	const history = 2*reportPageSize + 10 // more than two pages
+
+	t.Run("daily total", func(t *testing.T) {
+		env := newTestEnv(t)
+		for i := range history {
+			env.seed("user-1", 1000, domain.StatusCompleted, now.Add(-time.Duration(i)*time.Millisecond))
+		}
+
+		limits, err := env.svc.GetLimits(ctx, "user-1")
+		if err != nil {
+			t.Fatalf("GetLimits() = %v", err)
+		}
+		if want := float64(history * 1000); limits.UsedToday != want {
+			t.Fatalf("used today = %v, want %v", limits.UsedToday, want)
+		}
+	})
+
+	t.Run("open transactions", func(t *testing.T) {
+		env := newTestEnv(t, func(cfg *Config) { cfg.MaxOpenTransactions = 3 })
+		// The open transactions are the oldest, behind two pages of finished ones
+		for i := range 3 {
+			env.seed("user-1", 1000, domain.StatusPaymentPending, now.Add(-48*time.Hour-time.Duration(i)*time.Second))
+		}
+		for i := range history {
+			env.seed("user-1", 1000, domain.StatusCompleted, now.Add(-24*time.Hour-time.Duration(i)*time.Second))
+		}
+
+		_, err := env.svc.InitiateTransaction(ctx, &InitiateRequest{UserID: "user-1", Amount: 1000, Recipient: testRecipient()})
+		if !errors.Is(err, ErrTooManyOpenTransactions) {
+			t.Fatalf("InitiateTransaction() = %v, want ErrTooManyOpenTransactions", err)
+		}
+	})
+
+	t.Run("promo code uses", func(t *testing.T) {
+		env := newTestEnv(t, func(cfg *Config) {
+			cfg.PromoCodes = []config.PromoCodeConfig{{Code: "WELCOME", Discount: 1, MaxUsesPerUser: 1}}
+		})
+		used := env.seed("user-1", 1000, domain.StatusCompleted, now.Add(-72*time.Hour))
+		used.Fees.PromoCode = "WELCOME"
+		env.repo.put(used)
+		for i := range history {
+			env.seed("user-1", 1000, domain.StatusCompleted, now.Add(-24*time.Hour-time.Duration(i)*time.Second))
+		}
+
+		_, err := env.svc.InitiateTransaction(ctx, &InitiateRequest{UserID: "user-1", Amount: 1000, Recipient: testRecipient(), PromoCode: "WELCOME"})
+		if !errors.Is(err, ErrPromoCodeUsageExceeded) {
+			t.Fatalf("InitiateTransaction() = %v, want ErrPromoCodeUsageExceeded", err)
+		}
+	})
+}
+
+func TestOpenTransactionsCap(t *testing.T) {
+	tests := []struct {
+		name     string
+		max      int
+		statuses []domain.TransactionStatus // of the user's existing transactions
+		other    int                        // open transactions of another user
+		wantErr  error
+	}{
+		{"under the cap", 2, []domain.TransactionStatus{domain.StatusPaymentPending}, 0, nil},
+		{"at the cap", 2, []domain.TransactionStatus{domain.StatusInitiated, domain.StatusProcessing}, 0, ErrTooManyOpenTransactions},
+		{"finished transactions do not count", 2, []domain.TransactionStatus{domain.StatusPaymentPending, domain.StatusCompleted, domain.StatusFailed}, 0, nil},
+		{"other users do not count", 2, []domain.TransactionStatus{domain.StatusPaymentPending}, 3, nil},
+		{"no cap", 0, []domain.TransactionStatus{domain.StatusInitiated, domain.StatusInitiated, domain.StatusInitiated}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.MaxOpenTransactions = tt.max })
+			now := time.Now()
+			for i, status := range tt.statuses {
+				env.seed("user-1", 1000, status, now.Add(-time.Duration(i+1)*time.Hour))
+			}
+			for range tt.other {
+				env.seed("user-2", 1000, domain.StatusPaymentPending, now.Add(-time.Hour))
+			}
+
+			_, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{UserID: "user-1", Amount: 1000, Recipient: testRecipient()})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateTransaction() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitiatePromoCode(t *testing.T) {
+	// 10000 INR pays 150 in fees before any discount
+	tests := []struct {
+		name         string
+		code         string
+		priorUse     domain.TransactionStatus // of an earlier transaction with the code, if set
+		wantErr      error
+		wantFee      float64
+		wantDiscount float64
+	}{
+		{"no code", "", "", nil, 150, 0},
+		{"half off", "HALF", "", nil, 75, 75},
+		{"fee free", "FREE", "", nil, 0, 150},
+		{"case-insensitive", "half", "", nil, 75, 75},
+		{"unknown", "NOPE", "", ErrInvalidPromoCode, 0, 0},
+		{"expired", "OLD", "", ErrPromoCodeExpired, 0, 0},
+		{"used up", "HALF", domain.StatusCompleted, ErrPromoCodeUsageExceeded, 0, 0},
+		{"failed use does not count", "HALF", domain.StatusFailed, nil, 75, 75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.PromoCodes = []config.PromoCodeConfig{
+					{Code: "HALF", Discount: 0.5, MaxUsesPerUser: 1},
+					{Code: "FREE", Discount: 1},
+					{Code: "OLD", Discount: 1, ExpiresAt: time.Now().Add(-time.Hour)},
+				}
+			})
+			if tt.priorUse != "" {
+				prior := env.seed("user-1", 1000, tt.priorUse, time.Now().Add(-time.Hour))
+				prior.Fees.PromoCode = "HALF"
+				env.repo.put(prior)
+			}
+
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				Amount:    10000,
+				Recipient: testRecipient(),
+				PromoCode: tt.code,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateTransaction() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tx.Fees.TotalFee != tt.wantFee || tx.Fees.Discount != tt.wantDiscount {
+				t.Errorf("fee = %v, discount = %v; want %v, %v", tx.Fees.TotalFee, tx.Fees.Discount, tt.wantFee, tt.wantDiscount)
+			}
+			if tt.code != "" && !strings.EqualFold(tx.Fees.PromoCode, tt.code) {
+				t.Errorf("promo code = %q, want %q", tx.Fees.PromoCode, tt.code)
+			}
+		})
+	}
+}
+
+func TestGeneratePaymentLinkReuse(t *testing.T) {
+	tests := []struct {
+		name      string
+		between   func(t *testing.T, env *testEnv, txID string) // runs between the two requests
+		wantSame  bool
+		wantLinks int
+	}{
+		{"pending link is reused", func(t *testing.T, env *testEnv, txID string) {}, true, 1},
+		{"expired link is replaced", func(t *testing.T, env *testEnv, txID string) {
+			payment, err := env.repo.GetPayment(context.Background(), domain.PaymentID(txID))
+			if err != nil {
+				t.Fatal(err)
+			}
+			expired := time.Now().Add(-time.Minute)
+			payment.ExpiresAt = &expired
+			if err := env.repo.UpdatePayment(context.Background(), txID, payment); err != nil {
+				t.Fatal(err)
+			}
+		}, false, 2},
+		{"cancelled link is replaced", func(t *testing.T, env *testEnv, txID string) {
+			if _, err := env.svc.CancelPayment(context.Background(), "user-1", txID); err != nil {
+				t.Fatal(err)
+			}
+		}, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t, func(cfg *Config) { cfg.PaymentLinkValidity = time.Hour })
+			tx := env.initiate(t, "user-1", 10000)
+
+			first, err := env.svc.GeneratePaymentLink(ctx, "user-1", tx.ID)
+			if err != nil {
+				t.Fatalf("first GeneratePaymentLink() = %v", err)
+			}
+			tt.between(t, env, tx.ID)
+			second, err := env.svc.GeneratePaymentLink(ctx, "user-1", tx.ID)
+			if err != nil {
+				t.Fatalf("second GeneratePaymentLink() = %v", err)
+			}
+
+			if same := first.PaymentLink == second.PaymentLink; same != tt.wantSame {
+				t.Errorf("links %q and %q, want same %v", first.PaymentLink, second.PaymentLink, tt.wantSame)
+			}
+			if n := len(env.upi.amounts); n != tt.wantLinks {
+				t.Errorf("links requested = %d, want %d", n, tt.wantLinks)
+			}
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != domain.StatusPaymentPending || got.PaymentDetails.PaymentLink != second.PaymentLink {
+				t.Errorf("status = %s, link = %q; want PAYMENT_PENDING with %q", got.Status, got.PaymentDetails.PaymentLink, second.PaymentLink)
+			}
+		})
+	}
+}
+
+func TestInitiateTransferStaleRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		lockedAgo  time.Duration
+		rate       float64 // at transfer time
+		wantErr    error
+		wantStatus domain.TransactionStatus
+		wantReason string
+		wantRate   float64
+	}{
+		{"fresh rate is kept", 10 * time.Minute, 0.017, nil, domain.StatusProcessing, "", testRate},
+		{"stale rate within tolerance is re-quoted", 2 * time.Hour, 0.0161, nil, domain.StatusProcessing, "", 0.0161},
+		{"stale rate beyond tolerance fails", 2 * time.Hour, 0.017, ErrRateStale, domain.StatusFailed, domain.FailureReasonRateStale, testRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.CurrencyPairs[0].MaxRateAgeForTransfer = time.Hour
+				cfg.CurrencyPairs[0].RequoteTolerance = 0.01
+			})
+			tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now().Add(-tt.lockedAgo))
+			tx.RateLockedAt = tx.CreatedAt
+			env.repo.put(tx)
+			env.adBank.rate = tt.rate
+
+			err := env.svc.InitiateTransfer(context.Background(), tx.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateTransfer() = %v, want %v", err, tt.wantErr)
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus || got.FailureReason != tt.wantReason {
+				t.Errorf("status = %s, reason = %q; want %s, %q", got.Status, got.FailureReason, tt.wantStatus, tt.wantReason)
+			}
+			if got.ExchangeRate != tt.wantRate {
+				t.Errorf("exchange rate = %v, want %v", got.ExchangeRate, tt.wantRate)
+			}
+			if want := got.NetAmount(domain.FeeModelExclusive) * tt.wantRate; math.Abs(got.TargetAmount-want) > 0.01 {
+				t.Errorf("target amount = %v, want %v at the stored rate", got.TargetAmount, want)
+			}
+			wantTransfers := 1
+			if tt.wantErr != nil {
+				wantTransfers = 0
+			}
+			if n := len(env.wise.requests); n != wantTransfers {
+				t.Errorf("transfers created = %d, want %d", n, wantTransfers)
+			}
+		})
+	}
+}
+
+func TestCorridorByUserTier(t *testing.T) {
+	tests := []struct {
+		name    string
+		tier    string
+		wantErr error
+	}{
+		{"tier allowed the corridor", "gold", nil},
+		{"tier without the corridor", "basic", ErrCorridorNotAllowed},
+		{"tier with no pairs", "frozen", ErrCorridorNotAllowed},
+		{"unconfigured tier", "unknown", nil},
+		{"no tier", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.Tiers = map[string]config.TierConfig{
+					"gold":   {AllowedPairs: []string{"INR/CAD", "INR/USD"}},
+					"basic":  {AllowedPairs: []string{"INR/USD"}},
+					"frozen": {},
+				}
+			})
+
+			_, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				UserTier:  tt.tier,
+				Amount:    10000,
+				Recipient: testRecipient(),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateTransaction() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandlePaymentCallbackPaidAmount(t *testing.T) {
+	// 10000 INR plus 150 in fees is collected, matched within 1 INR
+	tests := []struct {
+		name          string
+		paid          *float64
+		wantStatus    domain.TransactionStatus
+		wantPaid      float64
+		wantTransfers int
+	}{
+		{"not reported", nil, domain.StatusProcessing, 10150, 1},
+		{"exact", ptr(10150.0), domain.StatusProcessing, 10150, 1},
+		{"within tolerance", ptr(10149.5), domain.StatusProcessing, 10149.5, 1},
+		{"under", ptr(10000.0), domain.StatusPaymentMismatch, 10000, 0},
+		{"over", ptr(10300.0), domain.StatusPaymentMismatch, 10300, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.PaymentAmountTolerance = 1 })
+			tx := env.awaitingPayment(t, "user-1", 10000)
+
+			err := env.svc.HandlePaymentCallback(context.Background(), &PaymentCallback{
+				PaymentID:  domain.PaymentID(tx.ID),
+				Status:     PaymentCallbackSuccess,
+				PaidAmount: tt.paid,
+			})
+			if err != nil {
+				t.Fatalf("HandlePaymentCallback() = %v", err)
+			}
+
+			if tt.wantTransfers > 0 {
+				waitFor(t, "the transfer", func() bool { return env.repo.tx(t, tx.ID).TransferID != "" })
+			} else {
+				time.Sleep(20 * time.Millisecond)
+			}
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if got.PaymentDetails.PaidAmount != tt.wantPaid || got.PaymentDetails.Currency != "INR" {
+				t.Errorf("paid = %v %s, want %v INR", got.PaymentDetails.PaidAmount, got.PaymentDetails.Currency, tt.wantPaid)
+			}
+			if n := env.wise.calls(); n != tt.wantTransfers {
+				t.Errorf("transfers created = %d, want %d", n, tt.wantTransfers)
+			}
+		})
+	}
+}
+
+func TestInitiateScreensRecipient(t *testing.T) {
+	env := newTestEnv(t)
+	env.compliance.blocked[testRecipient().BankAccount] = true
+
+	_, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{UserID: "user-1", Amount: 10000, Recipient: testRecipient()})
+	if !errors.Is(err, ErrRecipientBlocked) {
+		t.Fatalf("InitiateTransaction() = %v, want ErrRecipientBlocked", err)
+	}
+	if n := len(env.repo.txns); n != 0 {
+		t.Errorf("transactions stored = %d, want none", n)
+	}
+}
+
+func TestInitiateValidatesAccount(t *testing.T) {
+	env := newTestEnv(t)
+	env.adBank.invalid = true
+
+	_, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{UserID: "user-1", Amount: 10000, Recipient: testRecipient()})
+	if !errors.Is(err, ErrInvalidRecipient) {
+		t.Fatalf("InitiateTransaction() = %v, want ErrInvalidRecipient", err)
+	}
+	if n := len(env.repo.txns); n != 0 {
+		t.Errorf("transactions stored = %d, want none", n)
+	}
+}
+
+func TestRecipientAccountCurrency(t *testing.T) {
+	tests := []struct {
+		name         string
+		enforce      bool
+		sender       string // account currency the sender gave
+		bank         string // account currency AD Bank reports
+		wantErr      error
+		wantCurrency string
+	}{
+		{"matching", true, "CAD", "", nil, "CAD"},
+		{"mismatched", true, "INR", "", ErrRecipientCurrencyMismatch, ""},
+		{"unknown", true, "", "", nil, ""},
+		{"bank overrides sender", true, "CAD", "INR", ErrRecipientCurrencyMismatch, ""},
+		{"bank corrects sender", true, "INR", "CAD", nil, "CAD"},
+		{"not enforced", false, "INR", "", nil, "INR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.CurrencyPairs[0].EnforceRecipientCurrency = tt.enforce
+			})
+			env.adBank.currency = tt.bank
+
+			recipient := testRecipient()
+			recipient.AccountCurrency = tt.sender
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{UserID: "user-1", Amount: 10000, Recipient: recipient})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateTransaction() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if n := len(env.repo.txns); n != 0 {
+					t.Errorf("transactions stored = %d, want none", n)
+				}
+				return
+			}
+			if got := env.repo.tx(t, tx.ID).RecipientDetails.AccountCurrency; got != tt.wantCurrency {
+				t.Errorf("account currency = %q, want %q", got, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestHighValueReview(t *testing.T) {
+	tests := []struct {
+		name          string
+		amount        float64
+		resolve       func(s *RemittanceService, txID string) (*domain.Transaction, error)
+		wantHeld      bool
+		wantStatus    domain.TransactionStatus
+		wantReason    string
+		wantLinkError error
+	}{
+		{"at the threshold is not held", 50000, nil, false, domain.StatusInitiated, "", nil},
+		{"above the threshold is held", 50001, nil, true, domain.StatusPendingReview, "", ErrPendingReview},
+		{"approved", 50001, func(s *RemittanceService, txID string) (*domain.Transaction, error) {
+			return s.ApproveTransaction(context.Background(), txID)
+		}, true, domain.StatusInitiated, "", nil},
+		{"rejected", 50001, func(s *RemittanceService, txID string) (*domain.Transaction, error) {
+			return s.RejectTransaction(context.Background(), txID)
+		}, true, domain.StatusFailed, domain.FailureReasonReviewRejected, ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.ReviewThreshold = 50000 })
+			tx := env.initiate(t, "user-1", tt.amount)
+			if tx.RequiresReview != tt.wantHeld {
+				t.Errorf("requires review = %v, want %v", tx.RequiresReview, tt.wantHeld)
+			}
+			if tt.resolve != nil {
+				if _, err := tt.resolve(env.svc, tx.ID); err != nil {
+					t.Fatalf("resolving the review: %v", err)
+				}
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus || got.FailureReason != tt.wantReason {
+				t.Errorf("status = %s, reason = %q; want %s, %q", got.Status, got.FailureReason, tt.wantStatus, tt.wantReason)
+			}
+			_, err := env.svc.GeneratePaymentLink(context.Background(), "user-1", tx.ID)
+			if !errors.Is(err, tt.wantLinkError) {
+				t.Errorf("GeneratePaymentLink() = %v, want %v", err, tt.wantLinkError)
+			}
+		})
+	}
+}
+
+func TestResolveReviewOnlyHeldTransactions(t *testing.T) {
+	env := newTestEnv(t)
+	tx := env.initiate(t, "user-1", 10000)
+
+	if _, err := env.svc.ApproveTransaction(context.Background(), tx.ID); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("ApproveTransaction() = %v, want ErrInvalidStatus", err)
+	}
+	if _, err := env.svc.RejectTransaction(context.Background(), tx.ID); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("RejectTransaction() = %v, want ErrInvalidStatus", err)
+	}
+	if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusInitiated {
+		t.Errorf("status = %s, want %s", got.Status, domain.StatusInitiated)
+	}
+}
+
+func TestPaymentLinkPayeeVPA(t *testing.T) {
+	env := newTestEnv(t, func(cfg *Config) { cfg.PayeeVPA = "remit@bank" })
+	tx := env.initiate(t, "user-1", 10000)
+
+	payment, err := env.svc.GeneratePaymentLink(context.Background(), "user-1", tx.ID)
+	if err != nil {
+		t.Fatalf("GeneratePaymentLink() = %v", err)
+	}
+	if payment.UPIID != "remit@bank" {
+		t.Errorf("payee VPA = %q, want remit@bank", payment.UPIID)
+	}
+
+	stored, err := env.repo.GetPayment(context.Background(), payment.PaymentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.UPIID != "remit@bank" || env.repo.tx(t, tx.ID).PaymentDetails.UPIID != "remit@bank" {
+		t.Errorf("stored payee VPA = %q, want it on the payment and the transaction", stored.UPIID)
+	}
+}
+
+func TestReconciliationReport(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		wantErr  error
+	}{
+		{"range", from, to, nil},
+		{"empty range", from, from, ErrInvalidDateRange},
+		{"reversed range", to, from, ErrInvalidDateRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.seed("user-1", 10000, domain.StatusCompleted, from.Add(time.Hour))
+			env.seed("user-1", 20000, domain.StatusCompleted, to)
+			env.seed("user-2", 10000, domain.StatusFailed, from.Add(2*time.Hour))
+			env.seed("user-1", 40000, domain.StatusCompleted, to.Add(time.Second))
+			env.seed("user-1", 40000, domain.StatusCompleted, from.Add(-time.Second))
+
+			report, err := env.svc.ReconciliationReport(context.Background(), tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReconciliationReport() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := report.CountByStatus[domain.StatusCompleted]; got != 2 {
+				t.Errorf("completed = %d, want 2", got)
+			}
+			if got := report.CountByStatus[domain.StatusFailed]; got != 1 {
+				t.Errorf("failed = %d, want 1", got)
+			}
+			if got := report.Totals["INR"].Sent; got != 30000 {
+				t.Errorf("INR sent = %v, want 30000 from the range only", got)
+			}
+		})
+	}
+}
+
+func TestGetLimits(t *testing.T) {
+	type seeded struct {
+		amount float64
+		status domain.TransactionStatus
+		age    time.Duration
+	}
+	tests := []struct {
+		name          string
+		seed          []seeded
+		wantUsed      float64
+		wantRemaining float64
+	}{
+		{"nothing sent", nil, 0, 2_000_000},
+		{"sent today", []seeded{{300_000, domain.StatusCompleted, 0}, {100_000, domain.StatusPaymentPending, 0}}, 400_000, 1_600_000},
+		{"failed not counted", []seeded{{300_000, domain.StatusFailed, 0}}, 0, 2_000_000},
+		{"earlier days not counted", []seeded{{300_000, domain.StatusCompleted, 48 * time.Hour}}, 0, 2_000_000},
+		{"limit exceeded", []seeded{{2_500_000, domain.StatusCompleted, 0}}, 2_500_000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			for _, s := range tt.seed {
+				env.seed("user-1", s.amount, s.status, time.Now().Add(-s.age))
+			}
+			env.seed("user-2", 500_000, domain.StatusCompleted, time.Now())
+
+			limits, err := env.svc.GetLimits(context.Background(), "user-1")
+			if err != nil {
+				t.Fatalf("GetLimits() = %v", err)
+			}
+			if limits.UsedToday != tt.wantUsed || limits.RemainingToday != tt.wantRemaining {
+				t.Errorf("used %v, remaining %v; want %v, %v",
+					limits.UsedToday, limits.RemainingToday, tt.wantUsed, tt.wantRemaining)
+			}
+			if limits.MinAmount != 100 || limits.MaxAmount != 1_000_000 || limits.DailyLimit != 2_000_000 {
+				t.Errorf("limits = %+v, want the configured amounts", limits)
+			}
+			if limits.Fees.BaseFee != 50 || limits.Fees.VariableRate != 0.01 {
+				t.Errorf("fees = %+v, want the configured schedule", limits.Fees)
+			}
+		})
+	}
+}
+
+func TestInitiateFallbackRate(t *testing.T) {
+	const lastRate = 0.0155
+
+	tests := []struct {
+		name         string
+		providerDown bool
+		maxAge       time.Duration
+		lastAge      time.Duration // zero for no last-known rate
+		wantErr      bool
+		wantFallback bool
+		wantRate     float64
+	}{
+		{"provider up", false, time.Hour, 10 * time.Minute, false, false, testRate},
+		{"recent last-known rate", true, time.Hour, 10 * time.Minute, false, true, lastRate},
+		{"stale last-known rate", true, time.Hour, 2 * time.Hour, true, false, 0},
+		{"no last-known rate", true, time.Hour, 0, true, false, 0},
+		{"fallback disabled", true, 0, 10 * time.Minute, true, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.CurrencyPairs[0].MaxFallbackRateAge = tt.maxAge
+			})
+			if tt.lastAge > 0 {
+				last := domain.NewExchangeRate("ad_bank", "INR", "CAD", lastRate)
+				last.FetchedAt = time.Now().Add(-tt.lastAge)
+				env.repo.SaveRate(context.Background(), last)
+			}
+			if tt.providerDown {
+				env.adBank.setRate(0, errors.New("connection refused"))
+			}
+
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				Amount:    10000,
+				Recipient: testRecipient(),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InitiateTransaction() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tx.FallbackRate != tt.wantFallback || tx.ExchangeRate != tt.wantRate {
+				t.Errorf("fallback %v at %v, want %v at %v", tx.FallbackRate, tx.ExchangeRate, tt.wantFallback, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestFetchRateRemembersLastRate(t *testing.T) {
+	env := newTestEnv(t)
+	env.initiate(t, "user-1", 10000)
+
+	last, err := env.repo.GetLastRate(context.Background(), "INR", "CAD")
+	if err != nil {
+		t.Fatalf("GetLastRate() = %v, want the rate just quoted", err)
+	}
+	if last.Rate != testRate || last.Provider != "ad_bank" {
+		t.Errorf("last-known rate = %+v, want %v from ad_bank", last, testRate)
+	}
+}
+
+func TestRateAttribution(t *testing.T) {
+	lastFetched := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name         string
+		providerDown bool
+		quote        bool
+	}{
+		{"transaction with live rate", false, false},
+		{"transaction with fallback rate", true, false},
+		{"quote with live rate", false, true},
+		{"quote with fallback rate", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.CurrencyPairs[0].MaxFallbackRateAge = time.Hour
+			})
+			last := domain.NewExchangeRate("ad_bank", "INR", "CAD", 0.0155)
+			last.FetchedAt = lastFetched
+			env.repo.SaveRate(context.Background(), last)
+			if tt.providerDown {
+				env.adBank.setRate(0, errors.New("connection refused"))
+			}
+
+			before := time.Now()
+			var source string
+			var fetchedAt time.Time
+			if tt.quote {
+				quote, err := env.svc.Quote(context.Background(), &QuoteRequest{UserID: "user-1", Amount: 10000})
+				if err != nil {
+					t.Fatalf("Quote() = %v", err)
+				}
+				source, fetchedAt = quote.RateSource, quote.RateFetchedAt
+			} else {
+				tx := env.initiate(t, "user-1", 10000)
+				if tx.RateFetchedAt == nil {
+					t.Fatal("RateFetchedAt not set")
+				}
+				source, fetchedAt = tx.RateSource, *tx.RateFetchedAt
+			}
+
+			if source != "ad_bank" {
+				t.Errorf("rate source = %q, want ad_bank", source)
+			}
+			if tt.providerDown {
+				if !fetchedAt.Equal(lastFetched) {
+					t.Errorf("rate fetched at %v, want the last-known rate's %v", fetchedAt, lastFetched)
+				}
+			} else if fetchedAt.Before(before) || fetchedAt.After(time.Now()) {
+				t.Errorf("rate fetched at %v, want the live fetch time", fetchedAt)
+			}
+		})
+	}
+}
+
+func TestGetExchangeRatePair(t *testing.T) {
+	pairs := func(cfg *Config) {
+		cfg.CurrencyPairs = append(cfg.CurrencyPairs,
+			config.CurrencyPairConfig{Source: "INR", Target: "USD", Enabled: true},
+			config.CurrencyPairConfig{Source: "INR", Target: "GBP", Enabled: false},
+		)
+	}
+
+	tests := []struct {
+		name           string
+		defaultPair    string
+		source, target string
+		wantPair       string
+		wantErr        error
+	}{
+		{"first enabled pair by default", "", "", "", "INR/CAD", nil},
+		{"configured default", "INR/USD", "", "", "INR/USD", nil},
+		{"requested pair", "", "INR", "USD", "INR/USD", nil},
+		{"disabled pair", "", "INR", "GBP", "", ErrInvalidCurrency},
+		{"unknown pair", "", "USD", "INR", "", ErrInvalidCurrency},
+		{"source only", "", "INR", "", "", ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, pairs, func(cfg *Config) { cfg.DefaultPair = tt.defaultPair })
+
+			rate, err := env.svc.GetExchangeRate(context.Background(), tt.source, tt.target)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetExchangeRate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if rate.Pair != tt.wantPair || rate.Rate != testRate {
+				t.Errorf("GetExchangeRate() = %s at %v, want %s", rate.Pair, rate.Rate, tt.wantPair)
+			}
+		})
+	}
+}
+
+func TestGetExchangeRateFirstPairDisabled(t *testing.T) {
+	env := newTestEnv(t, func(cfg *Config) {
+		cfg.CurrencyPairs = []config.CurrencyPairConfig{
+			{Source: "INR", Target: "CAD", Enabled: false},
+			{Source: "INR", Target: "USD", Enabled: true},
+		}
+	})
+
+	rate, err := env.svc.GetExchangeRate(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("GetExchangeRate() error = %v", err)
+	}
+	if rate.Pair != "INR/USD" {
+		t.Errorf("GetExchangeRate() = %s, want INR/USD", rate.Pair)
+	}
+}
+
+func TestInitiateRecordsCreator(t *testing.T) {
+	env := newTestEnv(t)
+	tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+		UserID:    "user-1",
+		Amount:    10000,
+		Recipient: testRecipient(),
+		ClientIP:  "203.0.113.7",
+	})
+	if err != nil {
+		t.Fatalf("InitiateTransaction() = %v", err)
+	}
+
+	stored := env.repo.tx(t, tx.ID)
+	if stored.CreatedBy != "user-1" || stored.CreatedByIP != "203.0.113.7" {
+		t.Errorf("created by %q from %q, want user-1 from 203.0.113.7", stored.CreatedBy, stored.CreatedByIP)
+	}
+}
+
+func TestMissingTransactionNotFound(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"get", func() error {
+			_, err := env.svc.GetUserTransaction(ctx, "user-1", "TXN-404")
+			return err
+		}},
+		{"payment callback", func() error {
+			return env.svc.HandlePaymentCallback(ctx, &PaymentCallback{PaymentID: "PAY-TXN-404", Status: PaymentCallbackSuccess})
+		}},
+		{"transfer callback", func() error {
+			return env.svc.HandleTransferCallback(ctx, &TransferCallback{TransactionID: "TXN-404", Status: TransferCallbackCompleted})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestGetTransactionStatuses(t *testing.T) {
+	env := newTestEnv(t)
+	own := env.seed("user-1", 10000, domain.StatusCompleted, time.Now())
+	pending := env.seed("user-1", 10000, domain.StatusPaymentPending, time.Now())
+	other := env.seed("user-2", 10000, domain.StatusCompleted, time.Now())
+
+	tooMany := make([]string, MaxStatusBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("TXN-%d", i)
+	}
+
+	tests := []struct {
+		name         string
+		ids          []string
+		wantStatuses map[string]domain.TransactionStatus
+		wantNotFound []string
+		wantErr      error
+	}{
+		{"own", []string{own.ID, pending.ID},
+			map[string]domain.TransactionStatus{own.ID: domain.StatusCompleted, pending.ID: domain.StatusPaymentPending}, []string{}, nil},
+		{"another user's is not found", []string{own.ID, other.ID},
+			map[string]domain.TransactionStatus{own.ID: domain.StatusCompleted}, []string{other.ID}, nil},
+		{"missing", []string{"TXN-404"}, map[string]domain.TransactionStatus{}, []string{"TXN-404"}, nil},
+		{"duplicates collapse", []string{"TXN-404", own.ID, "TXN-404"},
+			map[string]domain.TransactionStatus{own.ID: domain.StatusCompleted}, []string{"TXN-404"}, nil},
+		{"too many", tooMany, nil, nil, ErrTooManyIDs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses, notFound, err := env.svc.GetTransactionStatuses(context.Background(), "user-1", tt.ids)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetTransactionStatuses() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if fmt.Sprint(statuses) != fmt.Sprint(tt.wantStatuses) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.wantStatuses)
+			}
+			if fmt.Sprint(notFound) != fmt.Sprint(tt.wantNotFound) {
+				t.Errorf("not found = %v, want %v", notFound, tt.wantNotFound)
+			}
+		})
+	}
+}
+
+func TestInitiateAmountPrecision(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision map[string]int
+		amount    float64
+		wantErr   bool
+	}{
+		{"whole rupees", nil, 10000, false},
+		{"paise", nil, 10000.25, false},
+		{"fraction of a paisa", nil, 10000.255, true},
+		{"override to whole rupees", map[string]int{"INR": 0}, 10000, false},
+		{"paise under whole-rupee override", map[string]int{"INR": 0}, 10000.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.AmountPrecision = tt.precision })
+
+			_, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				Amount:    tt.amount,
+				Recipient: testRecipient(),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InitiateTransaction() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) || !errors.Is(err, ErrInvalidAmount) || !strings.Contains(verr.Message, "decimal places") {
+				t.Errorf("InitiateTransaction() = %v, want a decimal places ValidationError", err)
+			}
+		})
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		setup    func(env *testEnv)
+		wantDown []string
+	}{
+		{"all up", func(env *testEnv) {}, nil},
+		{"UPI down", func(env *testEnv) { env.upi.pingErr = down }, []string{"upi"}},
+		{"AD Bank and Wise down", func(env *testEnv) {
+			env.adBank.pingErr = down
+			env.wise.pingErr = down
+		}, []string{"ad_bank", "wise"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tt.setup(env)
+
+			results := env.svc.CheckDependencies(context.Background())
+			if len(results) != 3 {
+				t.Fatalf("CheckDependencies() = %v, want upi, ad_bank and wise", results)
+			}
+			var gotDown []string
+			for _, name := range []string{"ad_bank", "upi", "wise"} {
+				err, ok := results[name]
+				if !ok {
+					t.Errorf("%s not checked", name)
+				}
+				if err != nil {
+					gotDown = append(gotDown, name)
+				}
+			}
+			if fmt.Sprint(gotDown) != fmt.Sprint(tt.wantDown) {
+				t.Errorf("down = %v, want %v", gotDown, tt.wantDown)
+			}
+		})
+	}
+}
+
+func TestQuoteDisclosesSpread(t *testing.T) {
+	tests := []struct {
+		name       string
+		margin     float64
+		amount     float64
+		wantRate   float64
+		wantSpread float64
+		wantErr    error
+	}{
+		{"no margin", 0, 10000, testRate, 0, nil},
+		{"two percent margin", 0.02, 10000, testRate * 0.98, 10000 * testRate * 0.02, nil},
+		{"below the minimum", 0.02, 50, 0, 0, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.CurrencyPairs[0].Margin = tt.margin })
+
+			quote, err := env.svc.Quote(context.Background(), &QuoteRequest{Amount: tt.amount})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Quote() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if quote.MidMarketRate != testRate || math.Abs(quote.ExchangeRate-tt.wantRate) > 1e-12 {
+				t.Errorf("rates = %v mid, %v offered; want %v, %v", quote.MidMarketRate, quote.ExchangeRate, testRate, tt.wantRate)
+			}
+			if math.Abs(quote.Fees.FXSpread-tt.wantSpread) > 1e-9 {
+				t.Errorf("FX spread = %v, want %v", quote.Fees.FXSpread, tt.wantSpread)
+			}
+			if quote.Fees.TotalFee != 150 || math.Abs(quote.TargetAmount-tt.amount*tt.wantRate) > 1e-9 {
+				t.Errorf("fees %v, target %v; want 150 and %v", quote.Fees.TotalFee, quote.TargetAmount, tt.amount*tt.wantRate)
+			}
+			if until := time.Until(quote.ExpiresAt); until <= 14*time.Minute || until > 15*time.Minute {
+				t.Errorf("expires in %v, want the pair's 15 minute rate validity", until)
+			}
+		})
+	}
+}
+
+func TestGeneratePaymentLinkResumes(t *testing.T) {
+	tests := []struct {
+		name      string
+		failWrite bool // the transaction update fails on the first attempt
+	}{
+		{"after a partial write", true},
+		{"after a complete write", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t)
+			tx := env.initiate(t, "user-1", 10000)
+
+			if tt.failWrite {
+				env.repo.fail["UpdateTransactionStatus"] = errors.New("throttled")
+			}
+			first, err := env.svc.GeneratePaymentLink(ctx, "user-1", tx.ID)
+			if (err != nil) != tt.failWrite {
+				t.Fatalf("first GeneratePaymentLink() = %v, want error %v", err, tt.failWrite)
+			}
+			delete(env.repo.fail, "UpdateTransactionStatus")
+
+			retry, err := env.svc.GeneratePaymentLink(ctx, "user-1", tx.ID)
+			if err != nil {
+				t.Fatalf("retried GeneratePaymentLink() = %v", err)
+			}
+			if !tt.failWrite && retry.PaymentLink != first.PaymentLink {
+				t.Errorf("retry link = %q, want the first %q", retry.PaymentLink, first.PaymentLink)
+			}
+			if n := len(env.upi.amounts); n != 1 {
+				t.Errorf("links requested from UPI = %d, want 1", n)
+			}
+
+			stored := env.repo.tx(t, tx.ID)
+			if stored.Status != domain.StatusPaymentPending || stored.PaymentDetails == nil ||
+				stored.PaymentDetails.PaymentLink != retry.PaymentLink {
+				t.Errorf("stored %s with %+v, want PAYMENT_PENDING with %q", stored.Status, stored.PaymentDetails, retry.PaymentLink)
+			}
+		})
+	}
+}
+
+func TestPaymentMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     domain.PaymentMethod
+		wantMethod domain.PaymentMethod
+		wantErr    error
+	}{
+		{"UPI by default", "", domain.PaymentMethodUPI, nil},
+		{"UPI", domain.PaymentMethodUPI, domain.PaymentMethodUPI, nil},
+		{"card", domain.PaymentMethodCard, domain.PaymentMethodCard, nil},
+		{"bank transfer not configured", domain.PaymentMethodBankTransfer, "", ErrUnsupportedPaymentMethod},
+		{"unknown", "CASH", "", ErrUnsupportedPaymentMethod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t, func(cfg *Config) { cfg.PayeeVPA = "remit@bank" })
+
+			tx, err := env.svc.InitiateTransaction(ctx, &InitiateRequest{
+				UserID:        "user-1",
+				Amount:        10000,
+				Recipient:     testRecipient(),
+				PaymentMethod: tt.method,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateTransaction() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tx.PaymentMethod != tt.wantMethod {
+				t.Errorf("method = %s, want %s", tx.PaymentMethod, tt.wantMethod)
+			}
+
+			payment, err := env.svc.GeneratePaymentLink(ctx, "user-1", tx.ID)
+			if err != nil {
+				t.Fatalf("GeneratePaymentLink() = %v", err)
+			}
+			if payment.Method != tt.wantMethod {
+				t.Errorf("payment method = %s, want %s", payment.Method, tt.wantMethod)
+			}
+
+			// The link comes from the method's provider, and only UPI
+			// links name the payee VPA
+			upiLinks, cardLinks := len(env.upi.amounts), len(env.card.amounts)
+			if isUPI := tt.wantMethod == domain.PaymentMethodUPI; (upiLinks == 1) != isUPI || (cardLinks == 1) == isUPI {
+				t.Errorf("links from UPI = %d, card = %d; want one from %s", upiLinks, cardLinks, tt.wantMethod)
+			}
+			if wantVPA := tt.wantMethod == domain.PaymentMethodUPI; (payment.UPIID != "") != wantVPA {
+				t.Errorf("payee VPA = %q, want set %v", payment.UPIID, wantVPA)
+			}
+		})
+	}
+}
+
+func TestPaymentLinkStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []domain.TransactionStatus
+		status  domain.TransactionStatus
+		wantErr error
+	}{
+		{"initiated by default", nil, domain.StatusInitiated, nil},
+		{"awaiting payment by default", nil, domain.StatusPaymentPending, nil},
+		{"processing never", nil, domain.StatusProcessing, ErrInvalidStatus},
+		{"failed by default", nil, domain.StatusFailed, ErrInvalidStatus},
+		{"configured", []domain.TransactionStatus{domain.StatusInitiated}, domain.StatusInitiated, nil},
+		{"not configured", []domain.TransactionStatus{domain.StatusInitiated}, domain.StatusPaymentPending, ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.PaymentLinkStatuses = tt.allowed })
+			tx := env.seed("user-1", 10000, tt.status, time.Now())
+
+			_, err := env.svc.GeneratePaymentLink(context.Background(), "user-1", tx.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GeneratePaymentLink() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && len(env.upi.amounts) != 0 {
+				t.Errorf("links requested = %d, want none when refused", len(env.upi.amounts))
+			}
+		})
+	}
+}
+
+func TestFailureReasons(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		fail func(t *testing.T, env *testEnv) string // fails a transaction, returning its ID
+		want string
+	}{
+		{"payment failed", func(t *testing.T, env *testEnv) string {
+			tx := env.awaitingPayment(t, "user-1", 10000)
+			if err := env.svc.HandlePaymentCallback(ctx, &PaymentCallback{PaymentID: domain.PaymentID(tx.ID), Status: PaymentCallbackFailed}); err != nil {
+				t.Fatal(err)
+			}
+			return tx.ID
+		}, domain.FailureReasonPaymentFailed},
+		{"transfer failed", func(t *testing.T, env *testEnv) string {
+			tx := env.seed("user-1", 10000, domain.StatusProcessing, time.Now())
+			if err := env.svc.HandleTransferCallback(ctx, &TransferCallback{TransactionID: tx.ID, Status: TransferCallbackFailed}); err != nil {
+				t.Fatal(err)
+			}
+			return tx.ID
+		}, domain.FailureReasonTransferFailed},
+		{"transfer rejected by the provider", func(t *testing.T, env *testEnv) string {
+			env.wise.errs = []error{&integration.TransferError{Code: "insufficient_funds"}}
+			tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+			if err := env.svc.InitiateTransfer(ctx, tx.ID); err == nil {
+				t.Fatal("InitiateTransfer() = nil, want the provider's error")
+			}
+			return tx.ID
+		}, "insufficient_funds"},
+		{"rejected in review", func(t *testing.T, env *testEnv) string {
+			tx := env.seed("user-1", 10000, domain.StatusPendingReview, time.Now())
+			if _, err := env.svc.RejectTransaction(ctx, tx.ID); err != nil {
+				t.Fatal(err)
+			}
+			return tx.ID
+		}, domain.FailureReasonReviewRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			got := env.repo.tx(t, tt.fail(t, env))
+			if got.Status != domain.StatusFailed || got.FailureReason != tt.want {
+				t.Errorf("status = %s, reason = %q; want FAILED, %q", got.Status, got.FailureReason, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransferConcurrencyCap(t *testing.T) {
+	const transfers = 6
+
+	tests := []struct {
+		name string
+		cap  int
+		want int
+	}{
+		{"capped", 2, 2},
+		{"uncapped", 0, transfers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.MaxConcurrentTransfers = tt.cap })
+			env.wise.delay = 30 * time.Millisecond
+
+			var wg sync.WaitGroup
+			for range transfers {
+				tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := env.svc.InitiateTransfer(context.Background(), tx.ID); err != nil {
+						t.Errorf("InitiateTransfer() = %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if env.wise.maxFlight != tt.want {
+				t.Errorf("transfers in flight = %d, want %d", env.wise.maxFlight, tt.want)
+			}
+			if n := env.wise.calls(); n != transfers {
+				t.Errorf("transfers created = %d, want %d", n, transfers)
+			}
+		})
+	}
+}
+
+func TestTransferSlotWaitEndsWithContext(t *testing.T) {
+	env := newTestEnv(t, func(cfg *Config) { cfg.MaxConcurrentTransfers = 1 })
+	env.svc.transferSlots <- struct{}{} // another transfer holds the only slot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := env.svc.callCreateTransfer(ctx, &integration.WiseTransferRequest{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("callCreateTransfer() = %v, want the context's deadline", err)
+	}
+	if n := env.wise.calls(); n != 0 {
+		t.Errorf("transfers created = %d, want none without a slot", n)
+	}
+}
+
+func TestReverseQuoteCoversTarget(t *testing.T) {
+	tiers := []config.FeeTierConfig{{UpTo: 20000, Rate: 0.02}, {UpTo: 0, Rate: 0.01, Flat: 100}}
+
+	tests := []struct {
+		name    string
+		model   domain.FeeModel
+		tiers   []config.FeeTierConfig
+		minimal bool // one minor unit less falls short
+	}{
+		{"exclusive fees", domain.FeeModelExclusive, nil, true},
+		{"inclusive fees", domain.FeeModelInclusive, nil, true},
+		{"inclusive tiered fees", domain.FeeModelInclusive, tiers, false},
+	}
+	targets := []float64{2, 160, 321.37, 1000}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.FeeModel = tt.model
+				cfg.FeeTiers = tt.tiers
+			})
+
+			for _, target := range targets {
+				reverse, err := env.svc.ReverseQuote(ctx, &QuoteRequest{TargetAmount: target})
+				if err != nil {
+					t.Fatalf("ReverseQuote(%v) = %v", target, err)
+				}
+				if reverse.TargetAmount < target {
+					t.Errorf("ReverseQuote(%v): sending %v delivers %v", target, reverse.SourceAmount, reverse.TargetAmount)
+				}
+
+				// Quoting the amount found delivers the same
+				forward, err := env.svc.Quote(ctx, &QuoteRequest{Amount: reverse.SourceAmount})
+				if err != nil {
+					t.Fatalf("Quote(%v) = %v", reverse.SourceAmount, err)
+				}
+				if forward.TargetAmount < target {
+					t.Errorf("Quote(%v) delivers %v, want at least %v", reverse.SourceAmount, forward.TargetAmount, target)
+				}
+
+				if !tt.minimal {
+					continue
+				}
+				less, err := env.svc.Quote(ctx, &QuoteRequest{Amount: reverse.SourceAmount - 0.01})
+				if err == nil && less.TargetAmount >= target {
+					t.Errorf("ReverseQuote(%v) = %v, but %v already delivers %v",
+						target, reverse.SourceAmount, reverse.SourceAmount-0.01, less.TargetAmount)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlePaymentCallbackOnlyAppliesAwaitingPayment(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        domain.TransactionStatus // before the callback
+		callback      PaymentCallbackStatus
+		wantStatus    domain.TransactionStatus
+		wantTransfers int
+	}{
+		{"success awaiting payment", domain.StatusPaymentPending, PaymentCallbackSuccess, domain.StatusProcessing, 1},
+		{"failure awaiting payment", domain.StatusPaymentPending, PaymentCallbackFailed, domain.StatusFailed, 0},
+		{"pending awaiting payment", domain.StatusPaymentPending, PaymentCallbackPending, domain.StatusPaymentPending, 0},
+		{"repeated success", domain.StatusPaymentReceived, PaymentCallbackSuccess, domain.StatusPaymentReceived, 0},
+		{"late success while processing", domain.StatusProcessing, PaymentCallbackSuccess, domain.StatusProcessing, 0},
+		{"late success once completed", domain.StatusCompleted, PaymentCallbackSuccess, domain.StatusCompleted, 0},
+		{"late failure once completed", domain.StatusCompleted, PaymentCallbackFailed, domain.StatusCompleted, 0},
+		{"success once failed", domain.StatusFailed, PaymentCallbackSuccess, domain.StatusFailed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tx := env.awaitingPayment(t, "user-1", 10000)
+			if tt.status != domain.StatusPaymentPending {
+				env.move(t, tx.ID, tt.status)
+			}
+
+			err := env.svc.HandlePaymentCallback(context.Background(), &PaymentCallback{
+				PaymentID: domain.PaymentID(tx.ID),
+				Status:    tt.callback,
+			})
+			if err != nil {
+				t.Fatalf("HandlePaymentCallback() = %v", err)
+			}
+
+			if tt.wantTransfers > 0 {
+				waitFor(t, "the transfer", func() bool { return env.repo.tx(t, tx.ID).TransferID != "" })
+			} else {
+				time.Sleep(20 * time.Millisecond) // give a wrongly started transfer the chance to show
+			}
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if n := env.wise.calls(); n != tt.wantTransfers {
+				t.Errorf("transfers created = %d, want %d", n, tt.wantTransfers)
+			}
+			if env.repo.fullWrites != 0 {
+				t.Errorf("%d full-item writes, want only conditional updates", env.repo.fullWrites)
+			}
+		})
+	}
+}
+
+func TestHandlePaymentCallbackConcurrentSuccessStartsOneTransfer(t *testing.T) {
+	env := newTestEnv(t)
+	tx := env.awaitingPayment(t, "user-1", 10000)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := env.svc.HandlePaymentCallback(context.Background(), &PaymentCallback{
+				PaymentID: domain.PaymentID(tx.ID),
+				Status:    PaymentCallbackSuccess,
+			})
+			if err != nil {
+				t.Errorf("HandlePaymentCallback() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	waitFor(t, "the transfer", func() bool { return env.repo.tx(t, tx.ID).TransferID != "" })
+	time.Sleep(20 * time.Millisecond)
+	if n := env.wise.calls(); n != 1 {
+		t.Fatalf("transfers created = %d, want 1", n)
+	}
+}
+
+func TestHandlePaymentCallbackStatuses(t *testing.T) {
+	tests := []struct {
+		status        PaymentCallbackStatus
+		wantErr       error
+		wantStatus    domain.TransactionStatus
+		wantPayment   string
+		wantTransfers int
+	}{
+		{PaymentCallbackSuccess, nil, domain.StatusProcessing, "SUCCESS", 1},
+		{PaymentCallbackFailed, nil, domain.StatusFailed, "FAILED", 0},
+		{PaymentCallbackPending, nil, domain.StatusPaymentPending, "PENDING", 0},
+		{"REFUNDED", ErrUnknownPaymentStatus, domain.StatusPaymentPending, "PENDING", 0},
+		{"success", ErrUnknownPaymentStatus, domain.StatusPaymentPending, "PENDING", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			env := newTestEnv(t)
+			tx := env.awaitingPayment(t, "user-1", 10000)
+
+			err := env.svc.HandlePaymentCallback(context.Background(), &PaymentCallback{
+				PaymentID: domain.PaymentID(tx.ID),
+				Status:    tt.status,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HandlePaymentCallback() = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantTransfers > 0 {
+				waitFor(t, "the transfer", func() bool { return env.repo.tx(t, tx.ID).TransferID != "" })
+			}
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus || got.PaymentDetails.Status != tt.wantPayment {
+				t.Errorf("status = %s, payment %s; want %s, payment %s", got.Status, got.PaymentDetails.Status, tt.wantStatus, tt.wantPayment)
+			}
+			if n := env.wise.calls(); n != tt.wantTransfers {
+				t.Errorf("transfers created = %d, want %d", n, tt.wantTransfers)
+			}
+		})
+	}
+}
+
+func TestHandleTransferCallbackUnknownStatus(t *testing.T) {
+	env := newTestEnv(t)
+	tx := env.processing("TR-1", time.Now())
+
+	for _, status := range []TransferCallbackStatus{"CANCELLED", "completed", ""} {
+		err := env.svc.HandleTransferCallback(context.Background(), &TransferCallback{TransactionID: tx.ID, Status: status})
+		if !errors.Is(err, ErrUnknownTransferStatus) {
+			t.Errorf("HandleTransferCallback(%q) = %v, want ErrUnknownTransferStatus", status, err)
+		}
+	}
+	if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusProcessing {
+		t.Errorf("status = %s, want PROCESSING", got.Status)
+	}
+}
+
+func TestCallbackStatusValid(t *testing.T) {
+	tests := []struct {
+		status string
+		want   [2]bool // valid for payments, for transfers
+	}{
+		{"SUCCESS", [2]bool{true, false}},
+		{"FAILED", [2]bool{true, true}},
+		{"PENDING", [2]bool{true, true}},
+		{"COMPLETED", [2]bool{false, true}},
+		{"PROCESSING", [2]bool{false, true}},
+		{"REFUNDED", [2]bool{false, false}},
+		{"", [2]bool{false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			got := [2]bool{PaymentCallbackStatus(tt.status).Valid(), TransferCallbackStatus(tt.status).Valid()}
+			if got != tt.want {
+				t.Errorf("Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitiateTransferOnce(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	tx := env.awaitingPayment(t, "user-1", 10000)
+	env.move(t, tx.ID, domain.StatusPaymentReceived)
+
+	if err := env.svc.InitiateTransfer(ctx, tx.ID); err != nil {
+		t.Fatalf("InitiateTransfer() = %v", err)
+	}
+	if err := env.svc.InitiateTransfer(ctx, tx.ID); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("second InitiateTransfer() = %v, want ErrInvalidStatus", err)
+	}
+
+	got := env.repo.tx(t, tx.ID)
+	if got.Status != domain.StatusProcessing || got.TransferID != "TR-1" {
+		t.Errorf("status = %s, transfer = %q; want PROCESSING with TR-1", got.Status, got.TransferID)
+	}
+	if got.PaymentDetails == nil || got.PaymentDetails.PaymentID != domain.PaymentID(tx.ID) {
+		t.Errorf("payment details lost: %+v", got.PaymentDetails)
+	}
+	if env.wise.calls() != 1 {
+		t.Errorf("transfers created = %d, want 1", env.wise.calls())
+	}
+	if env.repo.fullWrites != 0 {
+		t.Errorf("%d full-item writes, want only conditional updates", env.repo.fullWrites)
+	}
+}
+
+func TestCorridorTransferLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    domain.FeeModel
+		min, max float64
+		amount   float64
+		wantErr  error
+	}{
+		{"within", domain.FeeModelExclusive, 1000, 50000, 10000, nil},
+		{"no limits", domain.FeeModelExclusive, 0, 0, 10000, nil},
+		{"below the minimum", domain.FeeModelExclusive, 20000, 0, 10000, ErrBelowCorridorMinimum},
+		{"above the maximum", domain.FeeModelExclusive, 0, 5000, 10000, ErrAboveCorridorMaximum},
+		{"maximum applies after inclusive fees", domain.FeeModelInclusive, 0, 9900, 10000, nil},
+		{"minimum applies after inclusive fees", domain.FeeModelInclusive, 9900, 0, 10000, ErrBelowCorridorMinimum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.FeeModel = tt.model
+				cfg.CurrencyPairs[0].MinTransfer = tt.min
+				cfg.CurrencyPairs[0].MaxTransfer = tt.max
+			})
+
+			_, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				Amount:    tt.amount,
+				Recipient: testRecipient(),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("InitiateTransaction() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitiateTransferOutsideCorridorLimits(t *testing.T) {
+	env := newTestEnv(t)
+	tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+	// The limit tightened after the transaction was paid
+	env.svc.config.CurrencyPairs[0].MaxTransfer = 5000
+
+	err := env.svc.InitiateTransfer(context.Background(), tx.ID)
+	if !errors.Is(err, ErrAboveCorridorMaximum) {
+		t.Fatalf("InitiateTransfer() = %v, want ErrAboveCorridorMaximum", err)
+	}
+	if n := env.wise.calls(); n != 0 {
+		t.Errorf("transfers created = %d, want none", n)
+	}
+	if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusFailed || got.FailureReason != string(ErrAboveCorridorMaximum) {
+		t.Errorf("status = %s, reason = %q; want FAILED for the corridor maximum", got.Status, got.FailureReason)
+	}
+}
+
+func TestWiseCorridors(t *testing.T) {
+	tests := []struct {
+		name      string
+		corridors []string
+		wantErr   error
+	}{
+		{"any corridor", nil, nil},
+		{"supported corridor", []string{"INR/USD", "INR/CAD"}, nil},
+		{"unsupported corridor", []string{"INR/USD"}, ErrCorridorNotSupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+			// Wise dropped the corridor after the transaction was paid
+			env.svc.config.WiseCorridors = tt.corridors
+
+			err := env.svc.InitiateTransfer(context.Background(), tx.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateTransfer() = %v, want %v", err, tt.wantErr)
+			}
+			wantCalls, wantStatus, wantReason := 1, domain.StatusProcessing, ""
+			if tt.wantErr != nil {
+				wantCalls, wantStatus, wantReason = 0, domain.StatusFailed, tt.wantErr.Error()
+			}
+			if n := env.wise.calls(); n != wantCalls {
+				t.Errorf("transfers created = %d, want %d", n, wantCalls)
+			}
+			if got := env.repo.tx(t, tx.ID); got.Status != wantStatus || got.FailureReason != wantReason {
+				t.Errorf("status = %s, reason = %q; want %s, %q", got.Status, got.FailureReason, wantStatus, wantReason)
+			}
+
+			// New transactions are refused for the corridor up front
+			_, err = env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID: "user-2", Amount: 10000, Recipient: testRecipient(),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("InitiateTransaction() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaitForDependencies(t *testing.T) {
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		setup    func(env *testEnv)
+		wantErr  bool
+		wantName string // dependency named in the error
+	}{
+		{"ready", func(env *testEnv) {}, false, ""},
+		{"rate provider recovers", func(env *testEnv) { env.adBank.pingFailures = 3 }, false, ""},
+		{"rate provider stays down", func(env *testEnv) { env.adBank.pingErr = down }, true, "ad_bank"},
+		{"DynamoDB stays down", func(env *testEnv) { env.repo.fail["Ping"] = down }, true, "dynamodb"},
+		{"other integrations are not awaited", func(env *testEnv) {
+			env.upi.pingErr = down
+			env.wise.pingErr = down
+		}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tt.setup(env)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			err := env.svc.WaitForDependencies(ctx, 5*time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForDependencies() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && (!errors.Is(err, down) || !strings.Contains(err.Error(), tt.wantName)) {
+				t.Errorf("WaitForDependencies() = %v, want %s's failure", err, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestSearchTransactions(t *testing.T) {
+	env := newTestEnv(t)
+	for _, user := range []string{"user-1", "user-2"} {
+		if _, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+			UserID:         user,
+			Amount:         10000,
+			Recipient:      testRecipient(),
+			IdempotencyKey: "invoice-42",
+		}); err != nil {
+			t.Fatalf("InitiateTransaction(%s) = %v", user, err)
+		}
+	}
+	if _, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+		UserID: "user-1", Amount: 10000, Recipient: testRecipient(),
+	}); err != nil {
+		t.Fatalf("InitiateTransaction() = %v", err)
+	}
+
+	tests := []struct {
+		reference string
+		wantUsers []string
+	}{
+		{"invoice-42", []string{"user-1", "user-2"}},
+		{"invoice-43", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			txns, _, err := env.svc.SearchTransactions(context.Background(), tt.reference, 10, "")
+			if err != nil {
+				t.Fatalf("SearchTransactions() = %v", err)
+			}
+			var users []string
+			for _, tx := range txns {
+				users = append(users, tx.UserID)
+			}
+			sort.Strings(users)
+			if fmt.Sprint(users) != fmt.Sprint(tt.wantUsers) {
+				t.Errorf("SearchTransactions() users = %v, want %v", users, tt.wantUsers)
+			}
+		})
+	}
+}
+
+func TestTransferRecipientNameTransliteration(t *testing.T) {
+	tests := []struct {
+		name               string
+		transliterate      bool
+		recipientName      string
+		wantSent           string
+		wantTransliterated string
+	}{
+		{"Devanagari name", true, "राहुल शर्मा", "Rahul Sharma", "Rahul Sharma"},
+		{"Latin name", true, "Jane Doe", "Jane Doe", ""},
+		{"corridor without transliteration", false, "राहुल शर्मा", "राहुल शर्मा", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.CurrencyPairs[0].TransliterateNames = tt.transliterate
+			})
+			tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+			tx.RecipientDetails.Name = tt.recipientName
+			env.repo.put(tx)
+
+			if err := env.svc.InitiateTransfer(context.Background(), tx.ID); err != nil {
+				t.Fatalf("InitiateTransfer() = %v", err)
+			}
+
+			if sent := env.wise.requests[0].RecipientName; sent != tt.wantSent {
+				t.Errorf("sent Wise %q, want %q", sent, tt.wantSent)
+			}
+			got := env.repo.tx(t, tx.ID).RecipientDetails
+			if got.Name != tt.recipientName || got.NameTransliterated != tt.wantTransliterated {
+				t.Errorf("recipient name = %q, transliterated %q; want %q, %q",
+					got.Name, got.NameTransliterated, tt.recipientName, tt.wantTransliterated)
+			}
+		})
+	}
+}
+
+func TestInitiateIgnoresClientTransliteration(t *testing.T) {
+	env := newTestEnv(t)
+	recipient := testRecipient()
+	recipient.NameTransliterated = "Someone Else"
+
+	tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+		UserID:    "user-1",
+		Amount:    10000,
+		Recipient: recipient,
+	})
+	if err != nil {
+		t.Fatalf("InitiateTransaction() = %v", err)
+	}
+	if got := env.repo.tx(t, tx.ID).RecipientDetails.NameTransliterated; got != "" {
+		t.Errorf("transliterated name = %q, want none from the client", got)
+	}
+}
+
+func TestListRecipientTransactions(t *testing.T) {
+	env := newTestEnv(t)
+	seed := func(userID, account string) *domain.Transaction {
+		recipient := testRecipient()
+		recipient.BankAccount = account
+		tx := domain.NewTransaction(userID, 10000, "INR", "CAD", recipient)
+		env.repo.put(tx)
+		return tx
+	}
+	mine := []*domain.Transaction{seed("user-1", "12345678"), seed("user-1", "12345678")}
+	seed("user-1", "87654321")
+	theirs := seed("user-2", "12345678")
+
+	tests := []struct {
+		name    string
+		userID  string
+		account string
+		wantIDs []string
+	}{
+		{"user's transactions to the recipient", "user-1", "12345678", []string{mine[0].ID, mine[1].ID}},
+		{"account with spaces", "user-1", " 12345678", []string{mine[0].ID, mine[1].ID}},
+		{"other user to the same recipient", "user-2", "12345678", []string{theirs.ID}},
+		{"recipient never paid", "user-1", "99999999", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txns, _, err := env.svc.ListRecipientTransactions(context.Background(), tt.userID, tt.account, 10, "", repository.SortDescending)
+			if err != nil {
+				t.Fatalf("ListRecipientTransactions() = %v", err)
+			}
+			var ids []string
+			for _, tx := range txns {
+				if tx.UserID != tt.userID {
+					t.Errorf("transaction %s of %s listed for %s", tx.ID, tx.UserID, tt.userID)
+				}
+				ids = append(ids, tx.ID)
+			}
+			sort.Strings(ids)
+			want := append([]string(nil), tt.wantIDs...)
+			sort.Strings(want)
+			if fmt.Sprint(ids) != fmt.Sprint(want) {
+				t.Errorf("transactions = %v, want %v", ids, want)
+			}
+		})
+	}
+}
+
+func TestPauseCurrencyPair(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	inFlight := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+
+	if _, err := env.svc.SetCurrencyPairEnabled("INR", "CAD", false); err != nil {
+		t.Fatalf("SetCurrencyPairEnabled(false) = %v", err)
+	}
+	if pairs := env.svc.ListCurrencyPairs(); len(pairs) != 1 || pairs[0].Enabled {
+		t.Errorf("ListCurrencyPairs() = %+v, want INR/CAD paused", pairs)
+	}
+	req := &InitiateRequest{UserID: "user-1", Amount: 10000, Recipient: testRecipient()}
+	if _, err := env.svc.InitiateTransaction(ctx, req); !errors.Is(err, ErrCorridorDisabled) {
+		t.Errorf("InitiateTransaction() on a paused pair = %v, want ErrCorridorDisabled", err)
+	}
+	// Transactions already under way continue
+	if err := env.svc.InitiateTransfer(ctx, inFlight.ID); err != nil {
+		t.Errorf("InitiateTransfer() on a paused pair = %v", err)
+	}
+
+	if _, err := env.svc.SetCurrencyPairEnabled("INR", "CAD", true); err != nil {
+		t.Fatalf("SetCurrencyPairEnabled(true) = %v", err)
+	}
+	if pairs := env.svc.ListCurrencyPairs(); !pairs[0].Enabled {
+		t.Errorf("ListCurrencyPairs() = %+v, want INR/CAD resumed", pairs)
+	}
+	if _, err := env.svc.InitiateTransaction(ctx, req); err != nil {
+		t.Errorf("InitiateTransaction() on a resumed pair = %v", err)
+	}
+
+	if _, err := env.svc.SetCurrencyPairEnabled("INR", "USD", false); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("SetCurrencyPairEnabled(INR/USD) = %v, want ErrInvalidCurrency", err)
+	}
+	if !env.svc.config.CurrencyPairs[0].Enabled {
+		t.Error("pausing changed the configuration")
+	}
+}
+
+func TestInitiateTransferCustomerReference(t *testing.T) {
+	env := newTestEnv(t)
+	tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+
+	if err := env.svc.InitiateTransfer(context.Background(), tx.ID); err != nil {
+		t.Fatalf("InitiateTransfer() = %v", err)
+	}
+	if got := env.wise.requests[0].CustomerReference; got != tx.ID {
+		t.Errorf("customer reference = %q, want the transaction ID %s", got, tx.ID)
+	}
+}
+
+func TestFeeTiers(t *testing.T) {
+	tiers := []config.FeeTierConfig{
+		{UpTo: 10_000, Rate: 0.02},
+		{UpTo: 100_000, Rate: 0.01, Flat: 20},
+		{Rate: 0.005, Flat: 100},
+	}
+
+	tests := []struct {
+		name         string
+		tiers        []config.FeeTierConfig
+		amount       float64
+		wantVariable float64
+	}{
+		{"first tier", tiers, 5_000, 100},
+		{"first tier boundary", tiers, 10_000, 200},
+		{"just above the boundary", tiers, 10_000.01, 20 + 100.0001},
+		{"second tier boundary", tiers, 100_000, 1_020},
+		{"unbounded last tier", tiers, 500_000, 2_600},
+		{"above a bounded last tier", tiers[:1], 20_000, 400},
+		{"no tiers", nil, 20_000, 200},
+		{"no tiers clamps to the minimum", nil, 1_000, minVariableFee},
+		{"no tiers clamps to the maximum", nil, 900_000, maxVariableFee},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.FeeTiers = tt.tiers })
+
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				Amount:    tt.amount,
+				Recipient: testRecipient(),
+			})
+			if err != nil {
+				t.Fatalf("InitiateTransaction() = %v", err)
+			}
+			if math.Abs(tx.Fees.VariableFee-tt.wantVariable) > 1e-9 {
+				t.Errorf("variable fee = %v, want %v", tx.Fees.VariableFee, tt.wantVariable)
+			}
+			if want := 50 + tt.wantVariable; math.Abs(tx.Fees.TotalFee-want) > 1e-9 {
+				t.Errorf("total fee = %v, want %v", tx.Fees.TotalFee, want)
+			}
+
+			limits, err := env.svc.GetLimits(context.Background(), "user-1")
+			if err != nil {
+				t.Fatalf("GetLimits() = %v", err)
+			}
+			if len(limits.Fees.Tiers) != len(tt.tiers) {
+				t.Errorf("fee schedule tiers = %+v, want %+v", limits.Fees.Tiers, tt.tiers)
+			}
+		})
+	}
+}
+
+func TestRecalculateTransaction(t *testing.T) {
+	tests := []struct {
+		status  domain.TransactionStatus
+		wantErr error
+	}{
+		{domain.StatusInitiated, nil},
+		{domain.StatusPendingReview, nil},
+		{domain.StatusPaymentPending, nil},
+		{domain.StatusPaymentReceived, ErrInvalidStatus},
+		{domain.StatusProcessing, ErrInvalidStatus},
+		{domain.StatusCompleted, ErrInvalidStatus},
+		{domain.StatusFailed, ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			env := newTestEnv(t)
+			lockedAt := time.Now().Add(-time.Hour)
+			tx := env.seed("user-1", 10000, tt.status, lockedAt)
+			env.adBank.setRate(0.02, nil)
+
+			got, err := env.svc.RecalculateTransaction(context.Background(), tx.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RecalculateTransaction() = %v, want %v", err, tt.wantErr)
+			}
+
+			stored := env.repo.tx(t, tx.ID)
+			if tt.wantErr != nil {
+				if stored.ExchangeRate != testRate || stored.Fees.TotalFee != 50 {
+					t.Errorf("rate %v, fees %v; want the transaction unchanged", stored.ExchangeRate, stored.Fees.TotalFee)
+				}
+				return
+			}
+			for _, tx := range []*domain.Transaction{got, stored} {
+				if tx.ExchangeRate != 0.02 || tx.MidMarketRate != 0.02 {
+					t.Errorf("rate = %v, mid-market %v; want 0.02", tx.ExchangeRate, tx.MidMarketRate)
+				}
+				if tx.Fees.VariableFee != 100 || tx.Fees.TotalFee != 150 {
+					t.Errorf("fees = %+v, want 150 at 1%% on 10000", tx.Fees)
+				}
+				if math.Abs(tx.TargetAmount-200) > 1e-9 {
+					t.Errorf("target = %v, want 10000 at 0.02", tx.TargetAmount)
+				}
+				if !tx.RateLockedAt.After(lockedAt) || tx.Status != tt.status {
+					t.Errorf("rate locked at %v, status %s; want a new lock in %s", tx.RateLockedAt, tx.Status, tt.status)
+				}
+			}
+		})
+	}
+}
+
+func TestRecalculateKeepsPromoCode(t *testing.T) {
+	env := newTestEnv(t, func(cfg *Config) {
+		cfg.PromoCodes = []config.PromoCodeConfig{{Code: "HALF", Discount: 0.5}}
+	})
+	tx := env.seed("user-1", 10000, domain.StatusPaymentPending, time.Now())
+	tx.Fees.PromoCode = "HALF"
+	env.repo.put(tx)
+
+	got, err := env.svc.RecalculateTransaction(context.Background(), tx.ID)
+	if err != nil {
+		t.Fatalf("RecalculateTransaction() = %v", err)
+	}
+	if got.Fees.PromoCode != "HALF" || got.Fees.Discount != 75 || got.Fees.TotalFee != 75 {
+		t.Errorf("fees = %+v, want 150 halved by HALF", got.Fees)
+	}
+}
+
+func TestHandleTransferCallbackSequence(t *testing.T) {
+	tests := []struct {
+		name       string
+		callbacks  []TransferCallbackStatus
+		wantStatus domain.TransactionStatus
+		wantReason string
+	}{
+		{"progress then completed", []TransferCallbackStatus{TransferCallbackPending, TransferCallbackProcessing, TransferCallbackCompleted}, domain.StatusCompleted, ""},
+		{"completed then stale processing", []TransferCallbackStatus{TransferCallbackCompleted, TransferCallbackProcessing}, domain.StatusCompleted, ""},
+		{"completed then late failure", []TransferCallbackStatus{TransferCallbackCompleted, TransferCallbackFailed}, domain.StatusCompleted, ""},
+		{"failed then late completion", []TransferCallbackStatus{TransferCallbackFailed, TransferCallbackCompleted}, domain.StatusFailed, domain.FailureReasonTransferFailed},
+		{"repeated completion", []TransferCallbackStatus{TransferCallbackCompleted, TransferCallbackCompleted}, domain.StatusCompleted, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tx := env.seed("user-1", 10000, domain.StatusProcessing, time.Now())
+
+			for _, status := range tt.callbacks {
+				err := env.svc.HandleTransferCallback(context.Background(), &TransferCallback{TransactionID: tx.ID, Status: status})
+				if err != nil {
+					t.Fatalf("HandleTransferCallback(%s) = %v", status, err)
+				}
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != tt.wantStatus || got.FailureReason != tt.wantReason {
+				t.Errorf("status = %s, reason = %q; want %s, %q", got.Status, got.FailureReason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRevenueMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      domain.FeeModel
+		callbacks  []TransferCallbackStatus
+		wantFees   float64
+		wantMargin float64
+	}{
+		// 10000 INR with 150 in fees at 0.0165 mid-market, 0.016 to the customer
+		{"completed, fees on top", domain.FeeModelExclusive, []TransferCallbackStatus{TransferCallbackCompleted}, 150, 10000 * 0.0005},
+		{"completed, fees included", domain.FeeModelInclusive, []TransferCallbackStatus{TransferCallbackCompleted}, 150, 9850 * 0.0005},
+		{"completed twice", domain.FeeModelExclusive, []TransferCallbackStatus{TransferCallbackCompleted, TransferCallbackCompleted}, 150, 10000 * 0.0005},
+		{"failed", domain.FeeModelExclusive, []TransferCallbackStatus{TransferCallbackFailed}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.FeeModel = tt.model })
+			tx := domain.NewTransaction("user-1", 10000, "INR", "CAD", testRecipient())
+			tx.SetFees(&domain.Fees{BaseFee: 50, VariableFee: 100, TotalFee: 150})
+			tx.SetRates(0.0165, 0.016, 0.03, tt.model)
+			tx.Status = domain.StatusProcessing
+			env.repo.put(tx)
+
+			fees := metrics.FeesCollected.WithLabelValues("INR/CAD")
+			margin := metrics.FXMarginEarned.WithLabelValues("INR/CAD")
+			feesBefore, marginBefore := testutil.ToFloat64(fees), testutil.ToFloat64(margin)
+
+			for _, status := range tt.callbacks {
+				err := env.svc.HandleTransferCallback(context.Background(), &TransferCallback{TransactionID: tx.ID, Status: status})
+				if err != nil {
+					t.Fatalf("HandleTransferCallback(%s) = %v", status, err)
+				}
+			}
+
+			if got := testutil.ToFloat64(fees) - feesBefore; math.Abs(got-tt.wantFees) > 1e-9 {
+				t.Errorf("fees collected moved by %v, want %v", got, tt.wantFees)
+			}
+			if got := testutil.ToFloat64(margin) - marginBefore; math.Abs(got-tt.wantMargin) > 1e-9 {
+				t.Errorf("FX margin earned moved by %v, want %v", got, tt.wantMargin)
+			}
+		})
+	}
+}
+
+func TestHandleTransferCallbackBeforeTransfer(t *testing.T) {
+	env := newTestEnv(t)
+	tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+
+	err := env.svc.HandleTransferCallback(context.Background(), &TransferCallback{TransactionID: tx.ID, Status: TransferCallbackCompleted})
+	if !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("HandleTransferCallback() = %v, want ErrInvalidStatus", err)
+	}
+	if got := env.repo.tx(t, tx.ID); got.Status != domain.StatusPaymentReceived {
+		t.Errorf("status = %s, want %s", got.Status, domain.StatusPaymentReceived)
+	}
+}
+
+func TestInitiateIDCollision(t *testing.T) {
+	tests := []struct {
+		name       string
+		collisions int
+		wantErr    error
+		wantTries  int
+	}{
+		{"no collision", 0, nil, 1},
+		{"retried with a fresh ID", maxIDAttempts - 1, nil, maxIDAttempts},
+		{"collisions persist", maxIDAttempts, ErrIDCollision, maxIDAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.repo.collisions = tt.collisions
+
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				Amount:    10000,
+				Recipient: testRecipient(),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateTransaction() = %v, want %v", err, tt.wantErr)
+			}
+
+			tried := env.repo.createdIDs
+			if len(tried) != tt.wantTries {
+				t.Fatalf("create attempts = %d, want %d", len(tried), tt.wantTries)
+			}
+			seen := make(map[string]bool)
+			for _, id := range tried {
+				if seen[id] {
+					t.Errorf("ID %s tried twice in %v", id, tried)
+				}
+				seen[id] = true
+			}
+			if err == nil && (tx.ID != tried[len(tried)-1] || env.repo.tx(t, tx.ID).ID != tx.ID) {
+				t.Errorf("transaction %s, tried %v; want it stored under the last ID", tx.ID, tried)
+			}
+			if err != nil && len(env.repo.txns) != 0 {
+				t.Errorf("transactions stored = %d, want none", len(env.repo.txns))
+			}
+		})
+	}
+}
+
+func TestFeeModelAmounts(t *testing.T) {
+	// 10000 INR with a 50 base fee and 1% variable fee pays 150 in fees
+	tests := []struct {
+		model        domain.FeeModel
+		wantNet      float64
+		wantPaid     float64
+		minTransfer  float64
+		wantLimitErr error
+	}{
+		{domain.FeeModelExclusive, 10000, 10150, 9900, nil},
+		{domain.FeeModelInclusive, 9850, 10000, 9900, ErrBelowCorridorMinimum},
+		{domain.FeeModelInclusive, 9850, 10000, 9800, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.model), func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.FeeModel = tt.model
+				cfg.CurrencyPairs[0].MinTransfer = tt.minTransfer
+			})
+
+			quote, err := env.svc.Quote(ctx, &QuoteRequest{Amount: 10000})
+			if err != nil {
+				t.Fatalf("Quote() = %v", err)
+			}
+			if want := tt.wantNet * testRate; math.Abs(quote.TargetAmount-want) > 1e-9 {
+				t.Errorf("quoted target = %v, want %v", quote.TargetAmount, want)
+			}
+
+			tx, err := env.svc.InitiateTransaction(ctx, &InitiateRequest{UserID: "user-1", Amount: 10000, Recipient: testRecipient()})
+			if !errors.Is(err, tt.wantLimitErr) {
+				t.Fatalf("InitiateTransaction() = %v, want %v", err, tt.wantLimitErr)
+			}
+			if err != nil {
+				return
+			}
+			if tx.TargetAmount != quote.TargetAmount {
+				t.Errorf("target = %v, want the quoted %v", tx.TargetAmount, quote.TargetAmount)
+			}
+			if got := tx.CollectibleAmount(tt.model); got != tt.wantPaid {
+				t.Errorf("collectible = %v, want %v", got, tt.wantPaid)
+			}
+
+			if _, err := env.svc.GeneratePaymentLink(ctx, "user-1", tx.ID); err != nil {
+				t.Fatalf("GeneratePaymentLink() = %v", err)
+			}
+			env.move(t, tx.ID, domain.StatusPaymentReceived)
+			if err := env.svc.InitiateTransfer(ctx, tx.ID); err != nil {
+				t.Fatalf("InitiateTransfer() = %v", err)
+			}
+			if got := env.wise.requests[0].SourceAmount; got != tt.wantNet {
+				t.Errorf("Wise source amount = %v, want %v", got, tt.wantNet)
+			}
+		})
+	}
+}
+
+func TestInitiateBankName(t *testing.T) {
+	tests := []struct {
+		name         string
+		lookupErr    error
+		clientName   string
+		wantBankName string
+	}{
+		{"looked up", nil, "", "Test Bank"},
+		{"client value replaced", nil, "Other Bank", "Test Bank"},
+		{"lookup failed", errors.New("unknown bank code"), "", ""},
+		{"client value dropped when lookup fails", errors.New("unknown bank code"), "Other Bank", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.adBank.bankErr = tt.lookupErr
+			recipient := testRecipient()
+			recipient.BankName = tt.clientName
+
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				Amount:    10000,
+				Recipient: recipient,
+			})
+			if err != nil {
+				t.Fatalf("InitiateTransaction() = %v", err)
+			}
+			got := env.repo.tx(t, tx.ID).RecipientDetails
+			if got.BankName != tt.wantBankName || got.BankCode != "TD001" {
+				t.Errorf("bank = %q %q, want %q TD001", got.BankName, got.BankCode, tt.wantBankName)
+			}
+		})
+	}
+}
+
+func TestCorridorFeeOverride(t *testing.T) {
+	override := &config.FeesConfig{
+		Base:       config.FeeConfig{Amount: 100},
+		Percentage: config.FeeConfig{Rate: 0.02},
+	}
+	tiered := &config.FeesConfig{
+		Base:  config.FeeConfig{Amount: 10},
+		Tiers: []config.FeeTierConfig{{UpTo: 5_000, Rate: 0.03}, {Rate: 0.005}},
+	}
+
+	tests := []struct {
+		name         string
+		fees         *config.FeesConfig // of INR/CAD; INR/USD always uses the global fees
+		target       string
+		wantBase     float64
+		wantVariable float64
+	}{
+		{"overriding corridor", override, "CAD", 100, 200},
+		{"other corridor uses the global fees", override, "USD", 50, 100},
+		{"tiered override", tiered, "CAD", 10, 50},
+		{"no override", nil, "CAD", 50, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.CurrencyPairs[0].Fees = tt.fees
+				usd := cfg.CurrencyPairs[0]
+				usd.Target, usd.Fees = "USD", nil
+				cfg.CurrencyPairs = append(cfg.CurrencyPairs, usd)
+			})
+
+			quote, err := env.svc.Quote(context.Background(), &QuoteRequest{
+				Amount:         10000,
+				SourceCurrency: "INR",
+				TargetCurrency: tt.target,
+			})
+			if err != nil {
+				t.Fatalf("Quote() = %v", err)
+			}
+			if quote.Fees.BaseFee != tt.wantBase || math.Abs(quote.Fees.VariableFee-tt.wantVariable) > 1e-9 {
+				t.Errorf("fees = %+v, want base %v, variable %v", quote.Fees, tt.wantBase, tt.wantVariable)
+			}
+			if tt.target != "CAD" {
+				return
+			}
+
+			tx, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:    "user-1",
+				Amount:    10000,
+				Recipient: testRecipient(),
+			})
+			if err != nil {
+				t.Fatalf("InitiateTransaction() = %v", err)
+			}
+			if tx.Fees.BaseFee != tt.wantBase || math.Abs(tx.Fees.VariableFee-tt.wantVariable) > 1e-9 {
+				t.Errorf("initiated fees = %+v, want the quoted ones", tx.Fees)
+			}
+			limits, err := env.svc.GetLimits(context.Background(), "user-1")
+			if err != nil {
+				t.Fatalf("GetLimits() = %v", err)
+			}
+			if limits.Fees.BaseFee != tt.wantBase {
+				t.Errorf("fee schedule base = %v, want the default corridor's %v", limits.Fees.BaseFee, tt.wantBase)
+			}
+		})
+	}
+}
+
+func TestSettlementTolerance(t *testing.T) {
+	// 10000 INR at 0.016 is quoted to deliver 160 CAD
+	tests := []struct {
+		name         string
+		tolerance    config.SettlementToleranceConfig
+		delivered    *float64
+		wantVariance float64
+		wantReview   bool
+	}{
+		{"exact", config.SettlementToleranceConfig{Amount: 1}, ptr(160.0), 0, false},
+		{"under the amount", config.SettlementToleranceConfig{Amount: 1}, ptr(159.5), -0.5, false},
+		{"at the amount", config.SettlementToleranceConfig{Amount: 1}, ptr(161.0), 1, false},
+		{"over the amount", config.SettlementToleranceConfig{Amount: 1}, ptr(158.99), -1.01, true},
+		{"at the percentage", config.SettlementToleranceConfig{Percent: 0.01}, ptr(158.4), -1.6, false},
+		{"over the percentage", config.SettlementToleranceConfig{Percent: 0.01}, ptr(161.61), 1.61, true},
+		{"larger of the two", config.SettlementToleranceConfig{Amount: 2, Percent: 0.01}, ptr(158.0), -2, false},
+		{"no tolerance accepts all", config.SettlementToleranceConfig{}, ptr(150.0), -10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.SettlementTolerance = tt.tolerance })
+			tx := env.processing("TR-1", time.Now())
+
+			err := env.svc.HandleTransferCallback(context.Background(), &TransferCallback{
+				TransactionID:   tx.ID,
+				Status:          "COMPLETED",
+				DeliveredAmount: tt.delivered,
+			})
+			if err != nil {
+				t.Fatalf("HandleTransferCallback() = %v", err)
+			}
+
+			got := env.repo.tx(t, tx.ID)
+			if got.Status != domain.StatusCompleted {
+				t.Errorf("status = %s, want COMPLETED whatever the variance", got.Status)
+			}
+			if got.DeliveredAmount == nil || *got.DeliveredAmount != *tt.delivered {
+				t.Errorf("delivered = %v, want %v", got.DeliveredAmount, *tt.delivered)
+			}
+			if got.SettlementVariance != tt.wantVariance || got.SettlementReview != tt.wantReview {
+				t.Errorf("variance = %v, review = %v; want %v, %v", got.SettlementVariance, got.SettlementReview, tt.wantVariance, tt.wantReview)
+			}
+		})
+	}
+}
+
+func TestSettlementWithoutDeliveredAmount(t *testing.T) {
+	env := newTestEnv(t, func(cfg *Config) {
+		cfg.SettlementTolerance = config.SettlementToleranceConfig{Amount: 1}
+	})
+	tx := env.processing("TR-1", time.Now())
+
+	if err := env.svc.HandleTransferCallback(context.Background(), &TransferCallback{TransactionID: tx.ID, Status: "COMPLETED"}); err != nil {
+		t.Fatalf("HandleTransferCallback() = %v", err)
+	}
+	if got := env.repo.tx(t, tx.ID); got.DeliveredAmount != nil || got.SettlementReview {
+		t.Errorf("delivered = %v, review = %v; want nothing recorded", got.DeliveredAmount, got.SettlementReview)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name          string
+		interval      time.Duration
+		disableJitter bool
+	}{
+		{"jittered", 100 * time.Millisecond, false},
+		{"jittered short", time.Nanosecond, false},
+		{"without jitter", 100 * time.Millisecond, true},
+		{"zero", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := make(map[time.Duration]bool)
+			for range 1000 {
+				d := backoff(tt.interval, tt.disableJitter)
+				if d < 0 || d > tt.interval {
+					t.Fatalf("backoff(%v) = %v, want within [0, %v]", tt.interval, d, tt.interval)
+				}
+				if tt.disableJitter && d != tt.interval {
+					t.Fatalf("backoff(%v) without jitter = %v", tt.interval, d)
+				}
+				seen[d] = true
+			}
+			if !tt.disableJitter && tt.interval > time.Microsecond && len(seen) < 100 {
+				t.Errorf("%d distinct waits in 1000, want them spread over the interval", len(seen))
+			}
+		})
+	}
+}
+
+func TestCreateTransferRetries(t *testing.T) {
+	throttled := &integration.TransferError{Code: "Too Many Requests", Retryable: true}
+
+	tests := []struct {
+		name        string
+		maxAttempts int
+		errs        []error
+		wantCalls   int
+		wantStatus  domain.TransactionStatus
+		minElapsed  time.Duration
+	}{
+		// Waits 20ms, then 40ms capped at 30ms
+		{"recovers", 3, []error{throttled, throttled}, 3, domain.StatusProcessing, 50 * time.Millisecond},
+		{"attempts exhausted", 2, []error{throttled, throttled}, 2, domain.StatusFailed, 20 * time.Millisecond},
+		{"terminal failure", 3, []error{&integration.TransferError{Code: "insufficient_funds"}}, 1, domain.StatusFailed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) {
+				cfg.TransferRetry = config.RetryConfig{
+					MaxAttempts:     tt.maxAttempts,
+					InitialInterval: 20 * time.Millisecond,
+					MaxInterval:     30 * time.Millisecond,
+					DisableJitter:   true,
+				}
+			})
+			env.wise.errs = tt.errs
+			tx := env.seed("user-1", 10000, domain.StatusPaymentReceived, time.Now())
+
+			start := time.Now()
+			env.svc.InitiateTransfer(context.Background(), tx.ID)
+			elapsed := time.Since(start)
+
+			if n := env.wise.calls(); n != tt.wantCalls {
+				t.Errorf("Wise calls = %d, want %d", n, tt.wantCalls)
+			}
+			if got := env.repo.tx(t, tx.ID).Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("took %v, want at least the %v of backoff", elapsed, tt.minElapsed)
+			}
+			for i, req := range env.wise.requests {
+				if req.CustomerTransactionID != tx.ID {
+					t.Errorf("attempt %d: idempotency key = %q, want the transaction ID %s", i+1, req.CustomerTransactionID, tx.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetRateBreakdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		id      string // of the transaction asked for, empty for the initiated one
+		wantErr error
+	}{
+		{"own transaction", "user-1", "", nil},
+		{"someone else's transaction", "user-2", "", repository.ErrNotFound},
+		{"missing transaction", "user-1", "TXN-missing", repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.CurrencyPairs[0].Margin = 0.005 })
+			tx := env.initiate(t, "user-1", 10000)
+			id := tt.id
+			if id == "" {
+				id = tx.ID
+			}
+
+			got, err := env.svc.GetRateBreakdown(context.Background(), tt.userID, id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetRateBreakdown() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			stored := env.repo.tx(t, tx.ID)
+			if got.TransactionID != stored.ID || got.MidMarketRate != stored.MidMarketRate || got.CustomerRate != stored.ExchangeRate {
+				t.Errorf("breakdown = %+v, want the rates stored on %s", got, stored.ID)
+			}
+			if stored.MidMarketRate != testRate || math.Abs(got.MarginPercent-0.5) > 1e-9 {
+				t.Errorf("mid-market rate %v, margin %v%%; want %v, 0.5%%", stored.MidMarketRate, got.MarginPercent, testRate)
+			}
+			if math.Abs(got.CustomerRate-testRate*0.995) > 1e-12 {
+				t.Errorf("customer rate = %v, want %v", got.CustomerRate, testRate*0.995)
+			}
+		})
+	}
+}
+
+func TestPaymentCurrencies(t *testing.T) {
+	upiINR := map[domain.PaymentMethod][]string{domain.PaymentMethodUPI: {"INR"}}
+	tests := []struct {
+		name       string
+		currencies map[domain.PaymentMethod][]string
+		method     domain.PaymentMethod
+		source     string // of the transaction given a link
+		wantErr    bool
+	}{
+		{"INR by UPI", upiINR, domain.PaymentMethodUPI, "INR", false},
+		{"USD by UPI", upiINR, domain.PaymentMethodUPI, "USD", true},
+		{"INR by UPI restricted elsewhere", map[domain.PaymentMethod][]string{domain.PaymentMethodUPI: {"USD"}}, domain.PaymentMethodUPI, "INR", true},
+		{"USD by an unlisted method", upiINR, domain.PaymentMethodCard, "USD", false},
+		{"no restrictions", nil, domain.PaymentMethodUPI, "USD", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, func(cfg *Config) { cfg.PaymentCurrencies = tt.currencies })
+
+			// Initiation always collects INR
+			_, err := env.svc.InitiateTransaction(context.Background(), &InitiateRequest{
+				UserID:        "user-1",
+				Amount:        10000,
+				Recipient:     testRecipient(),
+				PaymentMethod: tt.method,
+			})
+			wantInitErr := tt.wantErr && tt.source == "INR"
+			if got := errors.Is(err, ErrMethodCurrencyMismatch); got != wantInitErr || (err != nil && !got) {
+				t.Errorf("InitiateTransaction() = %v, want currency mismatch %v", err, wantInitErr)
+			}
+
+			tx := env.seed("user-2", 10000, domain.StatusInitiated, time.Now())
+			tx.SourceCurrency = tt.source
+			tx.PaymentMethod = tt.method
+			env.repo.put(tx)
+
+			linksBefore := len(env.upi.amounts) + len(env.card.amounts)
+			_, err = env.svc.GeneratePaymentLink(context.Background(), "user-2", tx.ID)
+			if got := errors.Is(err, ErrMethodCurrencyMismatch); got != tt.wantErr || (err != nil && !got) {
+				t.Fatalf("GeneratePaymentLink() = %v, want currency mismatch %v", err, tt.wantErr)
+			}
+			var verr *ValidationError
+			if tt.wantErr && (!errors.As(err, &verr) || !strings.Contains(verr.Message, string(tt.method)) || !strings.Contains(verr.Message, tt.source)) {
+				t.Errorf("GeneratePaymentLink() = %v, want a validation error naming %s and %s", err, tt.method, tt.source)
+			}
+			if links := len(env.upi.amounts) + len(env.card.amounts) - linksBefore; tt.wantErr && links != 0 {
+				t.Errorf("links requested = %d, want none", links)
+			}
+		})
+	}
+}
+
+func TestUserTransactionOwnership(t *testing.T) {
+	ctx := context.Background()
+
+	// Each operation is run by the owner and by another user on a fresh
+	// transaction awaiting payment
+	ops := []struct {
+		name string
+		run  func(s *RemittanceService, userID, txID string) error
+	}{
+		{"GetUserTransaction", func(s *RemittanceService, userID, txID string) error {
+			_, err := s.GetUserTransaction(ctx, userID, txID)
+			return err
+		}},
+		{"EstimateDelivery", func(s *RemittanceService, userID, txID string) error {
+			_, err := s.EstimateDelivery(ctx, userID, txID)
+			return err
+		}},
+		{"GetTransactionTimeline", func(s *RemittanceService, userID, txID string) error {
+			_, err := s.GetTransactionTimeline(ctx, userID, txID)
+			return err
+		}},
+		{"GetRateBreakdown", func(s *RemittanceService, userID, txID string) error {
+			_, err := s.GetRateBreakdown(ctx, userID, txID)
+			return err
+		}},
+		{"GeneratePaymentLink", func(s *RemittanceService, userID, txID string) error {
+			_, err := s.GeneratePaymentLink(ctx, userID, txID)
+			return err
+		}},
+		{"CancelPayment", func(s *RemittanceService, userID, txID string) error {
+			_, err := s.CancelPayment(ctx, userID, txID)
+			return err
+		}},
+	}
+
+	for _, op := range ops {
+		t.Run(op.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tx := env.initiate(t, "owner", 10000)
+			if _, err := env.svc.GeneratePaymentLink(ctx, "owner", tx.ID); err != nil {
+				t.Fatalf("GeneratePaymentLink() = %v", err)
+			}
+			before := env.repo.tx(t, tx.ID)
+
+			err := op.run(env.svc, "intruder", tx.ID)
+			if !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("other user: error = %v, want ErrNotFound", err)
+			}
+			after := env.repo.tx(t, tx.ID)
+			if after.Status != before.Status || after.PaymentDetails.Status != before.PaymentDetails.Status {
+				t.Fatalf("other user changed the transaction: status %s -> %s, payment %s -> %s",
+					before.Status, after.Status, before.PaymentDetails.Status, after.PaymentDetails.Status)
+			}
+
+			if err := op.run(env.svc, "owner", tx.ID); err != nil {
+				t.Fatalf("owner: error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCancelPaymentByOwner(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	tx := env.initiate(t, "owner", 10000)
+	if _, err := env.svc.GeneratePaymentLink(ctx, "owner", tx.ID); err != nil {
+		t.Fatalf("GeneratePaymentLink() = %v", err)
+	}
+
+	if _, err := env.svc.CancelPayment(ctx, "owner", tx.ID); err != nil {
+		t.Fatalf("CancelPayment() = %v", err)
+	}
+
+	got := env.repo.tx(t, tx.ID)
+	if got.Status != domain.StatusInitiated {
+		t.Errorf("status = %s, want %s", got.Status, domain.StatusInitiated)
+	}
+	if got.PaymentDetails.Status != domain.PaymentStatusCancelled {
+		t.Errorf("payment status = %s, want %s", got.PaymentDetails.Status, domain.PaymentStatusCancelled)
+	}
+}
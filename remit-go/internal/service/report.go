@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/remit-demo/remit-go/internal/domain"
+)
+
+// reportPageSize bounds how many transactions are held in memory at once
+// while aggregating a report
+const reportPageSize = 100
+
+// ReconciliationReport aggregates transactions created within [from, to].
+// Transactions are streamed page by page from the status index so memory
+// stays bounded regardless of the range.
+func (s *RemittanceService) ReconciliationReport(ctx context.Context, from, to time.Time) (*domain.ReconciliationReport, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidDateRange
+	}
+
+	report := domain.NewReconciliationReport(from, to)
+	for _, status := range domain.Statuses {
+		err := s.forEachTransactionByStatus(ctx, status, from, to, func(tx *domain.Transaction) error {
+			report.Add(tx)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// forEachTransactionByStatus pages through every transaction in a status
+// created within [from, to], calling fn for each
+func (s *RemittanceService) forEachTransactionByStatus(
+	ctx context.Context,
+	status domain.TransactionStatus,
+	from, to time.Time,
+	fn func(tx *domain.Transaction) error,
+) error {
+	var cursor string
+	for {
+		txns, next, err := s.repo.ListTransactionsByStatus(ctx, status, from, to, reportPageSize, cursor)
+		if err != nil {
+			return fmt.Errorf("failed to list %s transactions: %w", status, err)
+		}
+
+		for _, tx := range txns {
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
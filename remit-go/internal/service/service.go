@@ -2,43 +2,214 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/remit-demo/remit-go/internal/domain"
+	"github.com/remit-demo/remit-go/internal/repository"
 )
 
 // Service defines the interface for remittance business operations
 type Service interface {
 	// Transaction operations
-	InitiateTransaction(ctx context.Context, userID string, amount float64, recipient *domain.RecipientDetails) (*domain.Transaction, error)
+	InitiateTransaction(ctx context.Context, req *InitiateRequest) (*domain.Transaction, error)
 	GetTransaction(ctx context.Context, id string) (*domain.Transaction, error)
-	ListUserTransactions(ctx context.Context, userID string, limit int, lastKey string) ([]*domain.Transaction, string, error)
+	GetUserTransaction(ctx context.Context, userID, id string) (*domain.Transaction, error)
+	SearchTransactions(ctx context.Context, reference string, limit int, cursor string) ([]*domain.Transaction, string, error)
+	GetTransactionTimeline(ctx context.Context, userID, id string) ([]domain.TimelineEntry, error)
+	GetRateBreakdown(ctx context.Context, userID, id string) (*domain.RateBreakdown, error)
+	GetTransactionStatuses(ctx context.Context, userID string, ids []string) (map[string]domain.TransactionStatus, []string, error)
+	ListUserTransactions(ctx context.Context, userID string, limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error)
+	ListRecipientTransactions(ctx context.Context, userID, bankAccount string, limit int, lastKey string, order repository.SortOrder) ([]*domain.Transaction, string, error)
+
+	// Export operations
+	StartExport(ctx context.Context, userID string) (*domain.ExportJob, error)
+	GetExport(ctx context.Context, userID, id string) (*domain.ExportJob, error)
+	ExportFile(ctx context.Context, userID, id string) ([]byte, *domain.ExportJob, error)
+	CancelExport(ctx context.Context, userID, id string) (*domain.ExportJob, error)
 
 	// Payment operations
-	GeneratePaymentLink(ctx context.Context, txID string) (*domain.PaymentDetails, error)
-	HandlePaymentCallback(ctx context.Context, paymentID string, status string) error
+	GeneratePaymentLink(ctx context.Context, userID, txID string) (*domain.PaymentDetails, error)
+	HandlePaymentCallback(ctx context.Context, cb *PaymentCallback) error
+	CancelPayment(ctx context.Context, userID, txID string) (*domain.Transaction, error)
+
+	// Limit operations
+	GetLimits(ctx context.Context, userID string) (*domain.Limits, error)
 
 	// Exchange rate operations
-	GetExchangeRate(ctx context.Context) (float64, error)
+	Quote(ctx context.Context, req *QuoteRequest) (*domain.Quote, error)
+	ReverseQuote(ctx context.Context, req *QuoteRequest) (*domain.Quote, error)
+	GetExchangeRate(ctx context.Context, sourceCurrency, targetCurrency string) (*domain.ExchangeRate, error)
 
 	// Cross-border transfer operations
 	InitiateTransfer(ctx context.Context, txID string) error
-	HandleTransferCallback(ctx context.Context, txID string, status string) error
+	HandleTransferCallback(ctx context.Context, cb *TransferCallback) error
+
+	// Review operations
+	ApproveTransaction(ctx context.Context, txID string) (*domain.Transaction, error)
+	RejectTransaction(ctx context.Context, txID string) (*domain.Transaction, error)
+	RecalculateTransaction(ctx context.Context, txID string) (*domain.Transaction, error)
+	GetProviderDebug(ctx context.Context, txID string) (*ProviderDebug, error)
+	ApplyBulkAction(ctx context.Context, req *BulkActionRequest) ([]BulkActionResult, error)
+
+	// Privacy operations
+	RedactTransaction(ctx context.Context, txID, by string) (*domain.Transaction, error)
+	RedactUserData(ctx context.Context, userID, by string) (*RedactionResult, error)
+
+	// Corridor operations
+	ListCurrencyPairs() []domain.CurrencyPair
+	SetCurrencyPairEnabled(source, target string, enabled bool) (*domain.CurrencyPair, error)
+
+	// Dead letter operations
+	ListDeadLetters(ctx context.Context, limit int, cursor string) ([]*domain.DeadLetter, string, error)
+	ReplayDeadLetter(ctx context.Context, id string) error
+
+	// Reporting operations
+	ReconciliationReport(ctx context.Context, from, to time.Time) (*domain.ReconciliationReport, error)
+
+	// Health operations
+	CheckDependencies(ctx context.Context) map[string]error
+
+	// Delivery estimation operations
+	EstimateDelivery(ctx context.Context, userID, txID string) (*domain.DeliveryEstimate, error)
+	EstimateQuoteDelivery(ctx context.Context, sourceCurrency, targetCurrency string) (*domain.DeliveryEstimate, error)
+}
+
+// InitiateRequest holds the inputs for starting a transaction
+type InitiateRequest struct {
+	UserID    string
+	UserTier  string
+	Amount    float64
+	Recipient *domain.RecipientDetails
+	PromoCode string
+	ClientIP  string // caller's address, recorded for audit
+
+	PaymentMethod domain.PaymentMethod // defaults to UPI
+	Reference     string               // customer's own reference; defaults to IdempotencyKey
+
+	IdempotencyKey string // retries with the same key return the original transaction
+}
+
+// QuoteRequest holds the inputs for previewing a transaction
+type QuoteRequest struct {
+	UserID         string
+	Amount         float64
+	TargetAmount   float64 // amount to deliver, for ReverseQuote
+	SourceCurrency string  // empty with TargetCurrency for the default pair
+	TargetCurrency string
+	PromoCode      string
+}
+
+// PaymentCallbackStatus is a payment status the UPI provider reports
+type PaymentCallbackStatus string
+
+const (
+	PaymentCallbackSuccess PaymentCallbackStatus = "SUCCESS"
+	PaymentCallbackFailed  PaymentCallbackStatus = "FAILED"
+	PaymentCallbackPending PaymentCallbackStatus = "PENDING"
+)
+
+// Valid reports whether s is a status the provider is known to send
+func (s PaymentCallbackStatus) Valid() bool {
+	switch s {
+	case PaymentCallbackSuccess, PaymentCallbackFailed, PaymentCallbackPending:
+		return true
+	}
+	return false
+}
+
+// PaymentCallback holds a payment status update reported by the UPI provider
+type PaymentCallback struct {
+	PaymentID string
+	Status    PaymentCallbackStatus
+	// PaidAmount is the amount actually collected, when the provider reports it
+	PaidAmount *float64
+}
+
+// TransferCallbackStatus is a transfer status Wise reports
+type TransferCallbackStatus string
+
+const (
+	TransferCallbackCompleted  TransferCallbackStatus = "COMPLETED"
+	TransferCallbackFailed     TransferCallbackStatus = "FAILED"
+	TransferCallbackPending    TransferCallbackStatus = "PENDING"
+	TransferCallbackProcessing TransferCallbackStatus = "PROCESSING"
+)
+
+// Valid reports whether s is a status Wise is known to send
+func (s TransferCallbackStatus) Valid() bool {
+	switch s {
+	case TransferCallbackCompleted, TransferCallbackFailed, TransferCallbackPending, TransferCallbackProcessing:
+		return true
+	}
+	return false
+}
+
+// TransferCallback is a transfer status update from Wise
+type TransferCallback struct {
+	TransactionID string
+	Status        TransferCallbackStatus
+	// DeliveredAmount is what the recipient actually received, in the
+	// target currency, when Wise reports it
+	DeliveredAmount *float64
 }
 
 // Error types for service operations
 type Error string
 
 const (
-	ErrInvalidAmount      Error = "invalid_amount"
-	ErrInvalidCurrency    Error = "invalid_currency"
-	ErrInvalidRecipient   Error = "invalid_recipient"
-	ErrTransactionFailed  Error = "transaction_failed"
-	ErrPaymentFailed      Error = "payment_failed"
-	ErrTransferFailed     Error = "transfer_failed"
-	ErrInvalidStatus      Error = "invalid_status"
-	ErrDailyLimitExceeded Error = "daily_limit_exceeded"
+	ErrInvalidAmount             Error = "invalid_amount"
+	ErrInvalidCurrency           Error = "invalid_currency"
+	ErrInvalidRecipient          Error = "invalid_recipient"
+	ErrTransactionFailed         Error = "transaction_failed"
+	ErrPaymentFailed             Error = "payment_failed"
+	ErrTransferFailed            Error = "transfer_failed"
+	ErrInvalidStatus             Error = "invalid_status"
+	ErrDailyLimitExceeded        Error = "daily_limit_exceeded"
+	ErrTooManyOpenTransactions   Error = "too_many_open_transactions"
+	ErrInvalidPromoCode          Error = "invalid_promo_code"
+	ErrPromoCodeExpired          Error = "promo_code_expired"
+	ErrPromoCodeUsageExceeded    Error = "promo_code_usage_exceeded"
+	ErrRateStale                 Error = "rate_stale"
+	ErrCorridorNotAllowed        Error = "corridor_not_allowed"
+	ErrCorridorDisabled          Error = "corridor_disabled"
+	ErrBelowCorridorMinimum      Error = "below_corridor_minimum"
+	ErrAboveCorridorMaximum      Error = "above_corridor_maximum"
+	ErrRecipientBlocked          Error = "recipient_blocked"
+	ErrPendingReview             Error = "pending_review"
+	ErrInvalidDateRange          Error = "invalid_date_range"
+	ErrTooManyIDs                Error = "too_many_ids"
+	ErrIdempotencyInProgress     Error = "idempotency_in_progress"
+	ErrUnsupportedPaymentMethod  Error = "unsupported_payment_method"
+	ErrUnknownOperation          Error = "unknown_operation"
+	ErrIDCollision               Error = "id_collision"
+	ErrUnknownTransferStatus     Error = "unknown_transfer_status"
+	ErrUnknownPaymentStatus      Error = "unknown_payment_status"
+	ErrExportNotFound            Error = "export_not_found"
+	ErrExportNotReady            Error = "export_not_ready"
+	ErrTooManyExports            Error = "too_many_exports"
+	ErrExportTooLarge            Error = "export_too_large"
+	ErrInvalidBulkAction         Error = "invalid_bulk_action"
+	ErrPaymentAlreadyReceived    Error = "payment_already_received"
+	ErrCorridorNotSupported      Error = "corridor_not_supported_by_provider"
+	ErrRecipientCurrencyMismatch Error = "recipient_currency_mismatch"
+	ErrMethodCurrencyMismatch    Error = "payment_method_currency_mismatch"
 )
 
 func (e Error) Error() string {
 	return string(e)
 }
+
+// ValidationError refines one of the errors above with a message that can
+// be shown to the caller
+type ValidationError struct {
+	Err     Error
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return string(e.Err) + ": " + e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
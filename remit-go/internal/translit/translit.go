@@ -0,0 +1,141 @@
+package translit
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Devanagari independent vowels
+var vowels = map[rune]string{
+	'अ': "a", 'आ': "a", 'इ': "i", 'ई': "i", 'उ': "u", 'ऊ': "u",
+	'ऋ': "ri", 'ए': "e", 'ऐ': "ai", 'ओ': "o", 'औ': "au", 'ऍ': "e", 'ऑ': "o",
+}
+
+// Devanagari consonants, without their inherent vowel
+var consonants = map[rune]string{
+	'क': "k", 'ख': "kh", 'ग': "g", 'घ': "gh", 'ङ': "n",
+	'च': "ch", 'छ': "chh", 'ज': "j", 'झ': "jh", 'ञ': "n",
+	'ट': "t", 'ठ': "th", 'ड': "d", 'ढ': "dh", 'ण': "n",
+	'त': "t", 'थ': "th", 'द': "d", 'ध': "dh", 'न': "n",
+	'प': "p", 'फ': "ph", 'ब': "b", 'भ': "bh", 'म': "m",
+	'य': "y", 'र': "r", 'ल': "l", 'व': "v", 'ळ': "l",
+	'श': "sh", 'ष': "sh", 'स': "s", 'ह': "h",
+	'\u0958': "q", '\u0959': "kh", '\u095A': "g", '\u095B': "z", // precomposed nukta letters
+	'\u095C': "r", '\u095D': "rh", '\u095E': "f", '\u095F': "y",
+}
+
+// Devanagari dependent vowel signs (matras)
+var matras = map[rune]string{
+	'ा': "a", 'ि': "i", 'ी': "i", 'ु': "u", 'ू': "u", 'ृ': "ri",
+	'े': "e", 'ै': "ai", 'ो': "o", 'ौ': "au", 'ॅ': "e", 'ॉ': "o",
+}
+
+// Devanagari signs following a syllable
+var signs = map[rune]string{
+	'ं': "n", // anusvara
+	'ँ': "n", // chandrabindu
+	'ः': "h", // visarga
+}
+
+const (
+	virama = '्'
+	nukta  = '़'
+)
+
+// IsLatin reports whether s needs no transliteration
+func IsLatin(s string) bool {
+	for _, r := range s {
+		if r >= utf8.RuneSelf && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ToLatin renders a name written in Devanagari in plain ASCII Latin letters,
+// the way Indian names are usually spelled in English: "राहुल शर्मा"
+// becomes "Rahul Sharma". Long vowels are not marked, and the inherent
+// vowel of a word's last consonant is dropped. Characters of other scripts
+// are kept as they are.
+func ToLatin(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if c, ok := consonants[r]; ok {
+			b.WriteString(c)
+			// Skip a combining nukta; the common nukta letters are mapped
+			// in their precomposed form
+			for i+1 < len(runes) && runes[i+1] == nukta {
+				i++
+			}
+
+			if i+1 < len(runes) {
+				next := runes[i+1]
+				if m, ok := matras[next]; ok {
+					b.WriteString(m)
+					i++
+					continue
+				}
+				if next == virama {
+					i++
+					continue
+				}
+			}
+
+			// Inherent vowel, silent at the end of a word unless the word
+			// is this one letter or ends in a conjunct ("Krishna")
+			wordStart := i == 0 || !isDevanagariLetter(runes[i-1])
+			wordEnd := i+1 == len(runes) || !isDevanagariLetter(runes[i+1])
+			conjunct := i > 0 && runes[i-1] == virama
+			if !wordEnd || wordStart || conjunct {
+				b.WriteString("a")
+			}
+			continue
+		}
+
+		if v, ok := vowels[r]; ok {
+			b.WriteString(v)
+			continue
+		}
+		if s, ok := signs[r]; ok {
+			b.WriteString(s)
+			continue
+		}
+		if r >= '०' && r <= '९' {
+			b.WriteRune('0' + r - '०')
+			continue
+		}
+		if r == nukta || r == virama {
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return titleCase(b.String())
+}
+
+// isDevanagariLetter reports whether r continues a Devanagari word
+func isDevanagariLetter(r rune) bool {
+	return r >= 0x0900 && r <= 0x097F && r != '।' && r != '॥'
+}
+
+// titleCase upper-cases the first letter of each space-separated word
+func titleCase(s string) string {
+	out := []rune(s)
+	start := true
+	for i, r := range out {
+		if unicode.IsSpace(r) {
+			start = true
+			continue
+		}
+		if start {
+			out[i] = unicode.ToUpper(r)
+			start = false
+		}
+	}
+	return string(out)
+}
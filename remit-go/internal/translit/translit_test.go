@@ -0,0 +1,54 @@
+package translit
+
+import "testing"
+
+func TestToLatin(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"full name", "राहुल शर्मा", "Rahul Sharma"},
+		{"final conjunct keeps its vowel", "कृष्ण", "Krishna"},
+		{"leading conjunct", "प्रिया", "Priya"},
+		{"initial vowel", "अमित", "Amit"},
+		{"anusvara", "संजय", "Sanjay"},
+		{"precomposed nukta letter", "\u095Bैनब", "Zainab"},
+		{"combining nukta", "ज\u093Cैनब", "Jainab"},
+		{"single letter word", "क", "Ka"},
+		{"digits", "फ्लैट १२", "Phlait 12"},
+		{"mixed scripts", "Rahul शर्मा", "Rahul Sharma"},
+		{"Latin unchanged", "Jane Doe", "Jane Doe"},
+		{"other scripts kept", "রাহুল", "রাহুল"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToLatin(tt.in); got != tt.want {
+				t.Errorf("ToLatin(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLatin(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"Jane Doe", true},
+		{"José Müller", true},
+		{"O'Brien-Smith", true},
+		{"", true},
+		{"राहुल शर्मा", false},
+		{"Rahul शर्मा", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := IsLatin(tt.in); got != tt.want {
+				t.Errorf("IsLatin(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import "testing"
+
+func TestCanonicalizeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"sorts keys", `{"b":1,"a":2}`, `{"a":2,"b":1}`, false},
+		{"sorts nested keys", `{"z":{"y":1,"x":[{"d":1,"c":2}]}}`, `{"z":{"x":[{"c":2,"d":1}],"y":1}}`, false},
+		{"drops whitespace", "{ \"a\" : [ 1 , 2 ] }\n", `{"a":[1,2]}`, false},
+		{"exponent", `{"amount":1e2}`, `{"amount":100}`, false},
+		{"trailing zeros", `{"amount":0.10}`, `{"amount":0.1}`, false},
+		{"large number stays plain", `{"amount":12345678.9}`, `{"amount":12345678.9}`, false},
+		{"no HTML escaping", `{"note":"a<b&c"}`, `{"note":"a<b&c"}`, false},
+		{"literals", `{"a":true,"b":false,"c":null}`, `{"a":true,"b":false,"c":null}`, false},
+		{"trailing data", `{"a":1}{"b":2}`, "", true},
+		{"invalid", `{"a":`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalizeJSON([]byte(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CanonicalizeJSON() error = %v, want error %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalizeJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeEqualPayloads(t *testing.T) {
+	type payload struct {
+		Status string  `json:"status"`
+		Amount float64 `json:"amount"`
+	}
+
+	fromStruct, err := Canonicalize(payload{Status: "COMPLETED", Amount: 160})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromMap, err := Canonicalize(map[string]any{"amount": 160.0, "status": "COMPLETED"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fromStruct) != string(fromMap) {
+		t.Errorf("Canonicalize() = %s and %s, want equal payloads to match", fromStruct, fromMap)
+	}
+}